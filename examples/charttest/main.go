@@ -67,7 +67,7 @@ MA22:MA(CLOSE,22);
 MA55:MA(CLOSE,55);
 MA144:MA(CLOSE,144);
 RSI$G2:RSI(CLOSE,14);
-VOL22:MA(VOLUME,22);
+VOL22:MA(VOLUME,22),COLORYELLOW,SUB1;
 ZTPPrice:ZTPRICE(C,0.1);
 涨停:=C>=ZTPRICE(REF(C,1),0.1);
 
@@ -78,6 +78,7 @@ ZTPPrice:ZTPRICE(C,0.1);
 昨天阴线:=REF(C,1)<REF(O,1);
 碰涨停:=H>=ZTPRICE(REF(C,1),0.1);
 N字涨停板$m:=前天涨停 AND 昨天阴线 AND 碰涨停 AND 去除;
+DRAWTEXT(N字涨停板$m,H*1.02,'N'),COLORRED;
 a:codelike(x);
 	`)
 	if err != nil {
@@ -129,25 +130,9 @@ a:codelike(x);
 	_ = xxx
 	xxx = executor.GetFloat64Array("昨天阴线")
 	_ = xxx
-	for varName := range executor.GetOutputVariableMap() {
-		if varName == "RSI$G2" || varName == "VOL22" {
-			continue
-		}
-		chart.AddMainCharts(&grob.Scatter{
-			// Uid:       types.StringType(varName),
-			Name:      charts.S(varName),
-			X:         charts.Array(executor.GetDateTimeArray()),
-			Y:         charts.Array(executor.GetFloat64Array(varName)),
-		})
-	}
-	chart.AddCharts(1,&grob.Scatter{
-		Name:      charts.S("VOL22"),
-		X:         charts.Array(executor.GetDateTimeArray()),
-		Y:         charts.Array(executor.GetFloat64Array("VOL22")),
-		Hovertext: charts.HoverTextArray(charts.StringRepeatToArray("VOL22 Hover", len(executor.GetDateTimeArray()))...),
-		Xaxis:     charts.S(charts.Xaxis()),
-		Yaxis:     charts.S(charts.Yaxis(1)),
-	})
+	// 按声明顺序画出所有输出变量：RSI$G2 放在第 2 个副图，VOL22 由 SUB1 放在成交量副图
+	chart.AddFormulaOutputs(executor)
+	chart.AddDrawings(executor)
 	chart.AddCharts(2,&grob.Scatter{
 		Name:      charts.S("RSI_70"),
 		X:         charts.Array(executor.GetDateTimeArray()),
@@ -162,13 +147,5 @@ a:codelike(x);
 		Xaxis:     charts.S(charts.Xaxis()),
 		Yaxis:     charts.S(charts.Yaxis(2)),
 	})
-	chart.AddCharts(2,&grob.Scatter{
-		Name:      charts.S("RSI"),
-		X:         charts.Array(executor.GetDateTimeArray()),
-		Y:         charts.Array(executor.GetFloat64Array("RSI$G2")),
-		Hovertext: charts.HoverTextArray(charts.StringRepeatToArray("RSI HoverInfo", len(executor.GetDateTimeArray()))...),
-		Xaxis:     charts.S(charts.Xaxis()),
-		Yaxis:     charts.S(charts.Yaxis(2)),
-	})
 	chart.AsHtml("test.html")
 }
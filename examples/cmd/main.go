@@ -4,18 +4,27 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/lyr-2000/mylang/pkg/mylang"
+	"github.com/lyr-2000/mylang/pkg/api"
 )
 
 func main() {
-	// 创建一个新的麦语言解释器
-	interp := mylang.NewMylangInterpreter()
+	// 创建一个新的麦语言执行器，内置了 LLV、NAMELIKE、INBLOCK 等函数
+	interp := api.NewMaiExecutor()
+
+	// 股票信息，NAMELIKE、CODELIKE、INBLOCK、ISST 从这里读取，也可以用 api.ReadSymbolInfoFile 从文件加载
+	provider := api.NewLocalSymbolInfoProvider(
+		api.SymbolInfo{Code: "600000.SH", Name: "浦发银行", Board: api.BoardMain, Blocks: []string{"银行", "上证50"}},
+		api.SymbolInfo{Code: "688981.SH", Name: "中芯国际", Board: api.BoardSTAR, Blocks: []string{"半导体"}},
+		api.SymbolInfo{Code: "300001.SZ", Name: "ST特锐", Board: api.BoardChiNext},
+	)
+	interp.SetSymbolInfoProvider(provider)
+	interp.SetSymbol("600000.SH")
 
 	// 注册变量
 	closeData := []float64{100.0, 101.0, 102.0, 103.0, 104.0}
 	highData := []float64{105.0, 106.0, 107.0, 108.0, 109.0}
-	interp.RegisterVariable("CLOSE", closeData)
-	interp.RegisterVariable("HIGH", highData)
+	interp.SetVar("CLOSE", closeData)
+	interp.SetVar("HIGH", highData)
 	fmt.Println("Registered CLOSE variable with data:", closeData)
 	fmt.Println("Registered HIGH variable with data:", highData)
 
@@ -27,63 +36,46 @@ func main() {
 	}
 
 	// 注册用户自定义函数
-	interp.RegisterFunction("LLV", func(args []interface{}) interface{} {
-		if len(args) != 2 {
-			fmt.Println("LLV: Expected 2 arguments, got", len(args))
-			return nil
-		}
-		data, ok1 := args[0].([]float64)
-		n, ok2 := args[1].(float64)
-		if !ok1 || !ok2 {
-			fmt.Println("LLV: Invalid argument types, data:", args[0], "n:", args[1])
-			return nil
-		}
-		if len(data) < int(n) {
-			fmt.Println("LLV: Data length", len(data), "less than n", int(n))
-			return nil
-		}
-		minVal := data[0]
-		for i := 1; i < int(n); i++ {
-			if data[i] < minVal {
-				minVal = data[i]
-			}
-		}
-		fmt.Println("LLV result:", minVal)
-		return minVal
-	})
-	
-	interp.RegisterVariable("NAMELIKE",func(args []interface{}) interface{} {
-		log.Printf("Namelike args: %v", args)
-		return false
-	})
-
 	interp.RegisterFunction("ADD", func(args []interface{}) interface{} {
 		if len(args) != 2 {
 
-			fmt.Println("HHV: Expected 2 arguments, got", len(args))
+			fmt.Println("ADD: Expected 2 arguments, got", len(args))
 			return nil
 		}
 		data, ok1 := args[0].([]float64)
 		n, ok2 := args[1].(float64)
 		if !ok1 || !ok2 {
-			fmt.Println("HHV: Invalid argument types, data:", args[0], "n:", args[1])
+			fmt.Println("ADD: Invalid argument types, data:", args[0], "n:", args[1])
 			return nil
 		}
-		for i := 0; i < len(data); i++ {
-			data[i] += n
+		out := make([]float64, len(data))
+		for i := range data {
+			out[i] = data[i] + n
 		}
-		return data
+		return out
 	})
 
-	// 执行麦语言代码 - 测试画图赋值
-	code := `
+	// 选股条件，每只股票执行一次
+	filter := `
 S1:=IF(NAMELIKE('S'),0,1);
-S2:=IF(NAMELIKE('*'),0,1);
+S2:=IF(NAMELIKE('*ST*'),0,1);
 S4:=IF(INBLOCK('科创板'),0,1);
-S5:=IF(NAMELIKE('C'),0,1);
 S6:=IF(INBLOCK('创业板'),0,1);
 S7:=IF(INBLOCK('北证50'),0,1);
-去除:=S1 AND S2 AND S4 AND S5 AND S6 AND S7;
+保留:=S1 AND S2 AND S4 AND S6 AND S7;
+`
+	for _, code := range provider.Codes() {
+		interp.SetSymbol(code)
+		if err := interp.RunCode(filter); err != nil {
+			log.Fatal(err)
+		}
+		keep, _ := interp.GetVariable("保留")
+		fmt.Printf("%s 保留: %v\n", code, keep)
+	}
+	interp.SetSymbol("600000.SH")
+
+	// 执行麦语言代码 - 测试画图赋值
+	code := `
 a:=1;
 b:=2;
 c:a>b AND b=0;
@@ -99,8 +91,9 @@ zzzzz:HIGH!=CLOSE;
 zzzzzz:HIGH<=CLOSE;	
 zzzzzzz:HIGH>=CLOSE,COLORRED,NODRAW;
     `
-	result := interp.Execute(code)
-	fmt.Println("Result:", result)
+	if err := interp.RunCode(code); err != nil {
+		log.Fatal(err)
+	}
 
 	// 调试输出
 	fmt.Println("Debugging output:")
@@ -120,8 +113,8 @@ zzzzzzz:HIGH>=CLOSE,COLORRED,NODRAW;
 	if len(drawingVars) > 0 {
 		for varName := range drawingVars {
 			fmt.Printf("- %s (is drawing variable: %t)\n", varName, interp.IsOutputVariable(varName))
-			b,ok := interp.GetVariable(varName)
-			fmt.Printf("%s : %v varok=%v\n",varName,b,ok)
+			b, ok := interp.GetVariable(varName)
+			fmt.Printf("%s : %v varok=%v\n", varName, b, ok)
 		}
 	} else {
 		fmt.Println("No drawing variables found")
@@ -5,9 +5,10 @@ go 1.25.1
 // github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly v0.0.0-00010101000000-000000000000
 require github.com/spf13/cast v1.10.0
 
-require github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly v0.0.0-20251122200736-66be0327bde3
-
 require (
-	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c // indirect
-	golang.org/x/sys v0.23.0 // indirect
+	github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly v0.0.0-20251122200736-66be0327bde3
+	golang.org/x/image v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/text v0.23.0 // indirect
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AdjustMode 复权方式，由未复权的 K 线和除权除息事件计算前复权、后复权价格
+//
+// 除权参考价 = (前收盘 - 每股派现 + 每股配股数*配股价) / (1 + 每股送转股数 + 每股配股数)，
+// 复权按等比计算：前复权保持最新价格不变，除权日之前的价格乘以 除权参考价/前收盘；
+// 后复权保持最早价格不变，除权日及以后的价格除以该比例。成交量按送转、配股的股数调整，成交额不变。
+// 早于第一根 K 线的事件没有前收盘，后复权时忽略
+type AdjustMode int
+
+const (
+	AdjustNone     AdjustMode = iota // 不复权
+	AdjustForward                    // 前复权
+	AdjustBackward                   // 后复权
+)
+
+func (a AdjustMode) String() string {
+	switch a {
+	case AdjustNone:
+		return "none"
+	case AdjustForward:
+		return "forward"
+	case AdjustBackward:
+		return "backward"
+	}
+	return fmt.Sprintf("AdjustMode(%d)", int(a))
+}
+
+// Bar 一根 K 线
+type Bar struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+	Amount float64
+}
+
+// CorporateAction 一次除权除息，数量都按每股计算，如 10 送 3 派 1 元为 Bonus 0.3、Cash 0.1
+type CorporateAction struct {
+	Date        time.Time // 除权除息日
+	Cash        float64   // 每股派现
+	Bonus       float64   // 每股送股、转增股数
+	Rights      float64   // 每股配股数
+	RightsPrice float64   // 配股价
+}
+
+// exRightPrice 除权参考价
+func (c CorporateAction) exRightPrice(prevClose float64) float64 {
+	return (prevClose - c.Cash + c.Rights*c.RightsPrice) / (1 + c.Bonus + c.Rights)
+}
+
+// AdjustBars 按复权方式计算 K 线，bars 按时间排序，返回新的切片
+func AdjustBars(bars []Bar, actions []CorporateAction, mode AdjustMode) []Bar {
+	out := append([]Bar(nil), bars...)
+	if mode == AdjustNone || len(bars) == 0 {
+		return out
+	}
+	actions = append([]CorporateAction(nil), actions...)
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].Date.Before(actions[j].Date) })
+
+	// priceFactor[i]、shareFactor[i] 为第 i 根 K 线的价格和成交量系数
+	priceFactor := make([]float64, len(bars))
+	shareFactor := make([]float64, len(bars))
+	for i := range bars {
+		priceFactor[i], shareFactor[i] = 1, 1
+	}
+	for _, action := range actions {
+		// first 为除权日当天或之后的第一根 K 线
+		day := dayKey(action.Date, action.Date.Location())
+		first := sort.Search(len(bars), func(i int) bool {
+			return dayKey(bars[i].Time, action.Date.Location()) >= day
+		})
+		if first == 0 {
+			continue
+		}
+		prev := bars[first-1].Close
+		if !(prev > 0) {
+			continue
+		}
+		ratio := action.exRightPrice(prev) / prev
+		shares := 1 + action.Bonus + action.Rights
+		if !(ratio > 0) || !(shares > 0) {
+			continue
+		}
+		if mode == AdjustForward {
+			for i := 0; i < first; i++ {
+				priceFactor[i] *= ratio
+				shareFactor[i] *= shares
+			}
+		} else {
+			for i := first; i < len(bars); i++ {
+				priceFactor[i] /= ratio
+				shareFactor[i] /= shares
+			}
+		}
+	}
+	for i := range out {
+		out[i].Open *= priceFactor[i]
+		out[i].High *= priceFactor[i]
+		out[i].Low *= priceFactor[i]
+		out[i].Close *= priceFactor[i]
+		out[i].Volume *= shareFactor[i]
+	}
+	return out
+}
+
+// SetBars 绑定未复权的 K 线和除权除息事件，按 mode 复权后设置 OPEN、HIGH、LOW、CLOSE、VOLUME、AMOUNT、
+// O、H、L、C、V 和 dateTime。之后可以用 SetAdjustMode 切换复权方式重新执行公式
+func (m *MaiExecutor) SetBars(bars []Bar, actions []CorporateAction, mode AdjustMode) {
+	m.bars = append([]Bar(nil), bars...)
+	sort.SliceStable(m.bars, func(i, j int) bool { return m.bars[i].Time.Before(m.bars[j].Time) })
+	m.actions = append([]CorporateAction(nil), actions...)
+	m.SetAdjustMode(mode)
+}
+
+// SetAdjustMode 切换复权方式，重新设置 SetBars 绑定的 K 线
+func (m *MaiExecutor) SetAdjustMode(mode AdjustMode) {
+	m.adjustMode = mode
+	bars := AdjustBars(m.bars, m.actions, mode)
+	n := len(bars)
+	open, high, low, cls := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	vol, amount, times := make([]float64, n), make([]float64, n), make([]any, n)
+	for i, b := range bars {
+		open[i], high[i], low[i], cls[i] = b.Open, b.High, b.Low, b.Close
+		vol[i], amount[i], times[i] = b.Volume, b.Amount, b.Time
+	}
+	for names, data := range map[[2]string][]float64{
+		{"OPEN", "O"}: open, {"HIGH", "H"}: high, {"LOW", "L"}: low, {"CLOSE", "C"}: cls, {"VOLUME", "V"}: vol,
+	} {
+		m.SetVar(names[0], data)
+		m.SetVar(names[1], data)
+	}
+	m.SetVar("AMOUNT", amount)
+	m.SetVar("dateTime", times)
+	m.DateTimeKey = "dateTime"
+	m.barTimes = nil
+}
+
+// AdjustMode 返回当前的复权方式
+func (m *MaiExecutor) AdjustMode() AdjustMode {
+	return m.adjustMode
+}
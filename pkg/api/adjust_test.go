@@ -0,0 +1,105 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testBars() ([]Bar, []CorporateAction) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 15, 0, 0, 0, time.UTC) }
+	bars := []Bar{
+		{Time: day(3), Open: 10, High: 10.5, Low: 9.8, Close: 10, Volume: 100, Amount: 1000},
+		{Time: day(4), Open: 10, High: 11, Low: 10, Close: 11, Volume: 100, Amount: 1100},
+		// 10 送 10 派 1 元：除权参考价 (11-0.1)/2 = 5.45
+		{Time: day(5), Open: 5.45, High: 5.6, Low: 5.4, Close: 5.5, Volume: 200, Amount: 1100},
+		{Time: day(6), Open: 5.5, High: 6, Low: 5.5, Close: 6, Volume: 200, Amount: 1200},
+	}
+	actions := []CorporateAction{
+		{Date: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), Cash: 0.1, Bonus: 1},
+		{Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Cash: 1}, // 早于第一根 K 线，忽略
+	}
+	return bars, actions
+}
+
+func closeTo(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAdjustBars(t *testing.T) {
+	bars, actions := testBars()
+	closes := func(bars []Bar) (c, v []float64) {
+		for _, b := range bars {
+			c, v = append(c, b.Close), append(v, b.Volume)
+		}
+		return
+	}
+	ratio := 5.45 / 11
+
+	c, v := closes(AdjustBars(bars, actions, AdjustNone))
+	if !reflect.DeepEqual(c, []float64{10, 11, 5.5, 6}) || !reflect.DeepEqual(v, []float64{100, 100, 200, 200}) {
+		t.Errorf("none = %v %v", c, v)
+	}
+	c, v = closes(AdjustBars(bars, actions, AdjustForward))
+	if !closeTo(c, []float64{10 * ratio, 11 * ratio, 5.5, 6}) || !closeTo(v, []float64{200, 200, 200, 200}) {
+		t.Errorf("forward = %v %v", c, v)
+	}
+	c, v = closes(AdjustBars(bars, actions, AdjustBackward))
+	if !closeTo(c, []float64{10, 11, 5.5 / ratio, 6 / ratio}) || !closeTo(v, []float64{100, 100, 100, 100}) {
+		t.Errorf("backward = %v %v", c, v)
+	}
+	if bars[0].Close != 10 {
+		t.Errorf("AdjustBars should not modify the input")
+	}
+
+	// 配股：10 配 3，配股价 5 元，除权参考价 (11+0.3*5)/1.3
+	rights := []CorporateAction{{Date: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), Rights: 0.3, RightsPrice: 5}}
+	c, _ = closes(AdjustBars(bars, rights, AdjustForward))
+	if want := 11 * (12.5 / 1.3 / 11); math.Abs(c[1]-want) > 1e-9 {
+		t.Errorf("rights forward = %v, want %v", c[1], want)
+	}
+}
+
+func TestMaiExecutorSetBars(t *testing.T) {
+	bars, actions := testBars()
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetBars(bars, actions, AdjustForward)
+	if m.AdjustMode() != AdjustForward {
+		t.Errorf("AdjustMode() = %v", m.AdjustMode())
+	}
+	code := "R:C/REF(C,1);D:DATE;"
+	if err := m.RunCode(code); err != nil {
+		t.Fatal(err)
+	}
+	forward := m.GetFloat64Array("R")
+	if got := m.GetFloat64Array("D"); !reflect.DeepEqual(got, []float64{1240603, 1240604, 1240605, 1240606}) {
+		t.Errorf("DATE = %v", got)
+	}
+
+	m.SetAdjustMode(AdjustBackward)
+	if err := m.RunCode(code); err != nil {
+		t.Fatal(err)
+	}
+	// 等比复权，前复权和后复权的涨跌幅相同
+	if backward := m.GetFloat64Array("R"); !closeTo(forward[1:], backward[1:]) || math.Abs(forward[2]-5.5/5.45) > 1e-9 {
+		t.Errorf("forward %v, backward %v", forward, backward)
+	}
+
+	m.SetAdjustMode(AdjustNone)
+	if got := m.GetFloat64Array("CLOSE"); !reflect.DeepEqual(got, []float64{10, 11, 5.5, 6}) {
+		t.Errorf("CLOSE = %v", got)
+	}
+	if got := m.GetFloat64Array("AMOUNT"); !reflect.DeepEqual(got, []float64{1000, 1100, 1100, 1200}) {
+		t.Errorf("AMOUNT = %v", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/cast"
 )
@@ -15,6 +16,22 @@ type MaiExecutor struct {
 	*mylang.MylangInterpreter
 	PreCompiledProgram *mylang.Program //if not nil ,use it to execute the program
 	DateTimeKey        string
+	DatasetVersion     string // 数据集版本，非空时调用缓存可以跨执行复用
+	callCache          *CallCache
+	Location           *time.Location // 日期时间内置变量的时区，nil 为 time.Local
+	now                func() time.Time
+	barTimes           *barTimeSeries      // 本次执行解析的 K 线时间
+	drawings           []Drawing           // 本次执行记录的画图命令
+	precision          *precisionMode      // 精确模式，nil 为未开启
+	roundedVars        []roundedVar        // 本次执行中精确模式替换的价格变量
+	symbol             string              // 当前执行的股票代码
+	metaProvider       SymbolMetaProvider  // 涨跌停使用的股票信息
+	infoProvider       SymbolInfoProvider  // NAMELIKE、INBLOCK 等使用的股票信息
+	fundProvider       FundamentalProvider // FINANCE、CAPITAL 等使用的财务数据
+	financeSameDay     bool                // 公告当天的 K 线可以使用当天公告的财务数据
+	bars               []Bar               // SetBars 绑定的未复权 K 线
+	actions            []CorporateAction   // SetBars 绑定的除权除息事件
+	adjustMode         AdjustMode
 }
 
 func (m *MaiExecutor) SetCustomVariableGetter(getter func(name string) any) {
@@ -55,6 +72,12 @@ func NewMaiExecutor() *MaiExecutor {
 		MylangInterpreter: mylang.NewMylangInterpreter(),
 	}
 	d.registerFuncs()
+	d.registerDateTimeFuncs()
+	d.registerDrawingFuncs()
+	d.registerPrecisionFuncs()
+	d.registerSymbolInfoFuncs()
+	d.registerFinanceFuncs()
+	d.Interp.BuiltinVariableGetter = d.builtinVariable
 	return d
 }
 
@@ -213,6 +236,20 @@ func (m *MaiExecutor) printExpression(expr mylang.Expression, indent int) {
 		fmt.Printf("%s  Right:\n", indentStr)
 		m.printExpression(e.Right, indent+2)
 
+	case *mylang.ConditionalExpression:
+		fmt.Printf("%sConditionalExpression:\n", indentStr)
+		fmt.Printf("%s  Condition:\n", indentStr)
+		m.printExpression(e.Condition, indent+2)
+		fmt.Printf("%s  Consequence:\n", indentStr)
+		m.printExpression(e.Consequence, indent+2)
+		fmt.Printf("%s  Alternative:\n", indentStr)
+		m.printExpression(e.Alternative, indent+2)
+
+	case *mylang.IndexExpression:
+		fmt.Printf("%sIndexExpression: %s\n", indentStr, e.String())
+		fmt.Printf("%s  Left:\n", indentStr)
+		m.printExpression(e.Left, indent+2)
+
 	case *mylang.FunctionCall:
 		fmt.Printf("%sFunctionCall:\n", indentStr)
 		fmt.Printf("%s  Function:\n", indentStr)
@@ -236,6 +273,8 @@ func (m *MaiExecutor) ExecuteProgram() error {
 	if len(m.PreCompiledProgram.Errors) > 0 {
 		log.Panicf("编译错误: %s", strings.Join(m.PreCompiledProgram.Errors, "; "))
 	}
+	m.beginExecution()
+	defer m.endExecution()
 	m.MylangInterpreter.ExecuteProgram(m.PreCompiledProgram)
 	return m.Err
 }
@@ -252,6 +291,8 @@ func (m *MaiExecutor) RunCode(code string) (err error) {
 	}()
 	// 假设这里可以调用核心麦语言解释器，实际应用中你需要替换为正确调用
 	// 例如: result, err := mytt.RunMaiCode(code)
+	m.beginExecution()
+	defer m.endExecution()
 	m.Execute(code)
 	// mylang.Logger.Printf("Result: %v", result)
 	return m.Err
@@ -264,19 +305,24 @@ func callBasicFunc(name string, args []any) (ret any, err error) {
 		}
 	}()
 
-	b, err := indicators.CallIndicatorByReflection(name, args)
+	b, err := indicators.CallIndicator(name, args)
 	return b, err
 }
 
 func (m *MaiExecutor) registerFuncs() {
 	for _, name := range indicators.GetAllFuncNames() {
 		m.RegisterFunction(name, func(args []interface{}) interface{} {
-			b, err := callBasicFunc(name, args)
-			if err != nil {
-				log.Panicf("func call error: %v %s", err,name)
-			}
-			return b
+			return m.callIndicator(name, args)
 		})
 	}
 
 }
+
+// callIndicator 调用内置指标函数，出错时 panic
+func (m *MaiExecutor) callIndicator(name string, args []interface{}) interface{} {
+	b, err := m.callCachedFunc(name, args)
+	if err != nil {
+		log.Panicf("func call error: %v %s", err, name)
+	}
+	return b
+}
@@ -106,4 +106,25 @@ test3:=C+1;
 	if len(executor.PreCompiledProgram.Errors) > 0 {
 		t.Errorf("Expected no errors in compiled program, but got: %v", executor.PreCompiledProgram.Errors)
 	}
-}
\ No newline at end of file
+}
+func TestMaiExecutorCaseInsensitive(t *testing.T) {
+	executor := NewMaiExecutor()
+	if err := executor.SetCaseInsensitive(true); err != nil {
+		t.Fatalf("SetCaseInsensitive() error = %v", err)
+	}
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-10"})
+	executor.SetVar("Close", []float64{10, 12})
+
+	if err := executor.RunCode("m：ma（close，2）；\nw:weekday;"); err != nil {
+		t.Fatalf("RunCode() error = %v", err)
+	}
+	if m := executor.GetFloat64Array("M"); len(m) != 2 || m[1] != 11 {
+		t.Errorf("M = %v", m)
+	}
+	if w := executor.GetFloat64Array("w"); len(w) != 2 || w[0] != 2 || w[1] != 3 {
+		t.Errorf("w = %v", w)
+	}
+	if len(executor.Diagnostics) != 5 {
+		t.Errorf("Diagnostics = %v", executor.Diagnostics)
+	}
+}
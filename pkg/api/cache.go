@@ -0,0 +1,260 @@
+package api
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sync"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+// CacheStats 调用缓存的命中统计
+type CacheStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+}
+
+// HitRate 命中率，没有调用时返回 0
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// CallCache 内置指标函数的调用缓存
+// key 由函数名和参数内容的哈希组成，同一次执行内相同的 MA(CLOSE,22)、REF(C,1) 只计算一次。
+// 设置了数据集版本后，缓存可以跨多次执行复用（例如选股时同一份数据跑多个公式），
+// 版本变化时缓存自动失效。CallCache 是并发安全的，可以在多个 MaiExecutor 之间共享。
+type CallCache struct {
+	mu      sync.Mutex
+	entries map[string]any
+	version string
+	hits    int64
+	misses  int64
+}
+
+// NewCallCache 创建一个空的调用缓存
+func NewCallCache() *CallCache {
+	return &CallCache{entries: make(map[string]any)}
+}
+
+// Stats 返回当前的命中统计
+func (c *CallCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: len(c.entries)}
+}
+
+// ResetStats 清空命中统计，不影响已缓存的结果
+func (c *CallCache) ResetStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits = 0
+	c.misses = 0
+}
+
+// Clear 清空所有缓存结果
+func (c *CallCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]any)
+}
+
+// Version 返回缓存当前绑定的数据集版本
+func (c *CallCache) Version() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// begin 在一次执行开始时调用
+// version 为空表示缓存只在本次执行内有效；否则只有版本变化时才清空
+func (c *CallCache) begin(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version == "" || version != c.version {
+		c.entries = make(map[string]any)
+	}
+	c.version = version
+}
+
+func (c *CallCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return v, ok
+}
+
+func (c *CallCache) put(key string, v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = v
+}
+
+// callCacheKey 计算函数调用的缓存 key，参数无法哈希时返回 false
+func callCacheKey(name string, args []any) (string, bool) {
+	h := fnv.New128a()
+	h.Write([]byte(name))
+	for _, arg := range args {
+		if !hashValue(h, arg) {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%s#%d#%x", name, len(args), h.Sum(nil)), true
+}
+
+// hashValue 把参数的类型和内容写入哈希
+func hashValue(h hash.Hash, v any) bool {
+	var buf [8]byte
+	writeFloat := func(f float64) {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		h.Write(buf[:])
+	}
+	writeLen := func(tag byte, n int) {
+		h.Write([]byte{tag})
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		h.Write(buf[:])
+	}
+	switch x := v.(type) {
+	case nil:
+		h.Write([]byte{'n'})
+	case float64:
+		h.Write([]byte{'f'})
+		writeFloat(x)
+	case int:
+		h.Write([]byte{'i'})
+		writeFloat(float64(x))
+	case bool:
+		if x {
+			h.Write([]byte{'b', 1})
+		} else {
+			h.Write([]byte{'b', 0})
+		}
+	case string:
+		writeLen('s', len(x))
+		h.Write([]byte(x))
+	case []float64:
+		writeLen('F', len(x))
+		for _, f := range x {
+			writeFloat(f)
+		}
+	case indicators.Series:
+		writeLen('F', len(x))
+		for _, f := range x {
+			writeFloat(f)
+		}
+	case []bool:
+		writeLen('B', len(x))
+		for _, b := range x {
+			if b {
+				h.Write([]byte{1})
+			} else {
+				h.Write([]byte{0})
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// cloneValue 复制缓存的结果，避免调用方修改结果时污染缓存
+func cloneValue(v any) any {
+	switch x := v.(type) {
+	case indicators.Series:
+		return indicators.CopySlice(x)
+	case []float64:
+		return append([]float64(nil), x...)
+	case []bool:
+		return append([]bool(nil), x...)
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = cloneValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// EnableCallCache 为内置指标函数开启调用缓存，缓存只在单次执行内有效
+func (m *MaiExecutor) EnableCallCache() *CallCache {
+	if m.callCache == nil {
+		m.callCache = NewCallCache()
+	}
+	return m.callCache
+}
+
+// SetCallCache 使用外部的调用缓存，传入 nil 关闭缓存
+// 多个 MaiExecutor 共享同一个缓存并设置相同的数据集版本时，可以复用彼此的计算结果
+func (m *MaiExecutor) SetCallCache(cache *CallCache) {
+	m.callCache = cache
+}
+
+// GetCallCache 获取当前的调用缓存，未开启时返回 nil
+func (m *MaiExecutor) GetCallCache() *CallCache {
+	return m.callCache
+}
+
+// SetDatasetVersion 设置绑定数据的版本号
+// 版本不为空时，调用缓存跨执行保留，直到版本号变化；数据更新后必须修改版本号
+func (m *MaiExecutor) SetDatasetVersion(version string) {
+	m.DatasetVersion = version
+}
+
+// CallCacheStats 返回调用缓存的命中统计，未开启缓存时返回零值
+func (m *MaiExecutor) CallCacheStats() CacheStats {
+	if m.callCache == nil {
+		return CacheStats{}
+	}
+	return m.callCache.Stats()
+}
+
+// beginExecution 每次执行前调用，处理缓存的作用域，清空上次解析的 K 线时间和画图命令，精确模式下处理价格变量
+func (m *MaiExecutor) beginExecution() {
+	m.barTimes = nil
+	m.drawings = nil
+	if m.callCache != nil {
+		m.callCache.begin(m.DatasetVersion)
+	}
+	if m.precision != nil {
+		m.roundPriceVars()
+	}
+}
+
+// endExecution 每次执行结束后调用，恢复精确模式替换的价格变量
+func (m *MaiExecutor) endExecution() {
+	m.restorePriceVars()
+}
+
+// callCachedFunc 带缓存地调用内置指标函数
+func (m *MaiExecutor) callCachedFunc(name string, args []any) (any, error) {
+	if m.callCache == nil {
+		return callBasicFunc(name, args)
+	}
+	key, ok := callCacheKey(name, args)
+	if !ok {
+		return callBasicFunc(name, args)
+	}
+	if v, hit := m.callCache.get(key); hit {
+		return cloneValue(v), nil
+	}
+	ret, err := callBasicFunc(name, args)
+	if err != nil {
+		return nil, err
+	}
+	m.callCache.put(key, cloneValue(ret))
+	return ret, nil
+}
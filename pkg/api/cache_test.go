@@ -0,0 +1,114 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+func newCacheTestExecutor() *MaiExecutor {
+	x := NewMaiExecutor()
+	x.SetVar("CLOSE", []float64{10, 11, 12, 13, 14, 15})
+	x.SetVar("C", []float64{10, 11, 12, 13, 14, 15})
+	return x
+}
+
+func TestCallCacheWithinExecution(t *testing.T) {
+	x := newCacheTestExecutor()
+	x.EnableCallCache()
+
+	err := x.RunCode("a:=MA(CLOSE,3);\nb:=MA(CLOSE,3)+1;\nc:=REF(C,1);\nd:=REF(C,1);")
+	if err != nil {
+		t.Fatalf("RunCode error: %v", err)
+	}
+	stats := x.CallCacheStats()
+	if stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("stats = %+v, want 2 hits and 2 misses", stats)
+	}
+	if !reflect.DeepEqual(x.GetFloat64Array("a")[2:], []float64{11, 12, 13, 14}) {
+		t.Errorf("a = %v", x.GetFloat64Array("a"))
+	}
+	if !reflect.DeepEqual(x.GetFloat64Array("b")[2:], []float64{12, 13, 14, 15}) {
+		t.Errorf("b = %v", x.GetFloat64Array("b"))
+	}
+}
+
+func TestCallCacheScopedToExecution(t *testing.T) {
+	x := newCacheTestExecutor()
+	x.EnableCallCache()
+	if err := x.CompileCode("a:=MA(CLOSE,3);"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := x.ExecuteProgram(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := x.CallCacheStats(); stats.Hits != 0 || stats.Misses != 3 {
+		t.Errorf("without dataset version stats = %+v, want 0 hits and 3 misses", stats)
+	}
+}
+
+func TestCallCacheAcrossRunsWithDatasetVersion(t *testing.T) {
+	cache := NewCallCache()
+	formulas := []string{"a:MA(CLOSE,3);", "b:MA(CLOSE,3)>12;", "c:CROSS(MA(CLOSE,3),12);"}
+	for _, code := range formulas {
+		x := newCacheTestExecutor()
+		x.SetCallCache(cache)
+		x.SetDatasetVersion("000001.SZ@20240101")
+		if err := x.RunCode(code); err != nil {
+			t.Fatalf("RunCode(%q) error: %v", code, err)
+		}
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("stats = %+v, want 2 hits and 2 misses", stats)
+	}
+
+	// 数据版本变化后缓存失效
+	x := newCacheTestExecutor()
+	x.SetCallCache(cache)
+	x.SetDatasetVersion("000001.SZ@20240102")
+	if err := x.RunCode("a:MA(CLOSE,3);"); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cache.Stats(); stats.Misses != 3 || stats.Entries != 1 {
+		t.Errorf("after version change stats = %+v, want 3 misses and 1 entry", stats)
+	}
+}
+
+func TestCallCacheResultIsolation(t *testing.T) {
+	x := newCacheTestExecutor()
+	x.EnableCallCache()
+	x.RegisterFunction("INPLACE", func(args []interface{}) interface{} {
+		d := args[0].(indicators.Series)
+		for i := range d {
+			d[i] = 0
+		}
+		return d
+	})
+	err := x.RunCode("a:=INPLACE(MA(CLOSE,2));\nb:=MA(CLOSE,2);")
+	if err != nil {
+		t.Fatalf("RunCode error: %v", err)
+	}
+	if got := x.GetFloat64Array("b"); got[5] != 14.5 {
+		t.Errorf("cached result was modified by caller: %v", got)
+	}
+}
+
+func TestCallCacheKey(t *testing.T) {
+	k1, ok1 := callCacheKey("MA", []any{[]float64{1, 2, 3}, 3.0})
+	k2, ok2 := callCacheKey("MA", []any{[]float64{1, 2, 3}, 3.0})
+	k3, _ := callCacheKey("MA", []any{[]float64{1, 2, 4}, 3.0})
+	k4, _ := callCacheKey("EMA", []any{[]float64{1, 2, 3}, 3.0})
+	if !ok1 || !ok2 || k1 != k2 {
+		t.Errorf("same arguments should produce the same key: %s %s", k1, k2)
+	}
+	if k1 == k3 || k1 == k4 {
+		t.Errorf("different calls should produce different keys")
+	}
+	if _, ok := callCacheKey("MA", []any{map[string]int{}}); ok {
+		t.Errorf("unsupported argument types should not be cached")
+	}
+}
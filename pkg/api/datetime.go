@@ -0,0 +1,333 @@
+package api
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+	"github.com/spf13/cast"
+)
+
+// 日期时间内置变量，由绑定的日期时间数组（见 GetDateTimeArray）计算
+//
+//	DATE     年月日，格式为 (年-1900)*10000+月*100+日，如 2024-04-09 为 1240409
+//	TIME     时分秒，格式为 时*10000+分*100+秒，日线为 0
+//	YEAR MONTH DAY HOUR MINUTE
+//	WEEKDAY  星期，0 为星期日
+//	PERIOD   周期，按 K 线间隔推断，取值同通达信：0 5分钟 1 15分钟 2 30分钟 3 1小时 4 日线 5 周线
+//	         6 月线 7 1分钟 8 多分钟 9 多日 10 季线 11 年线
+//
+// 以下变量只需要 K 线数量，没有日期时间数组时按 C 或 CLOSE 的长度计算
+//
+//	ISLASTBAR       是否最后一根 K 线
+//	BARPOS          K 线位置，从 1 开始
+//	TOTALBARSCOUNT  K 线总数
+//
+// 同名变量（例如用 DATE 绑定日期数组）优先于内置变量
+var dateTimeVarNames = map[string]bool{
+	"DATE": true, "TIME": true, "YEAR": true, "MONTH": true, "DAY": true,
+	"HOUR": true, "MINUTE": true, "WEEKDAY": true, "PERIOD": true,
+	"ISLASTBAR": true, "BARPOS": true, "TOTALBARSCOUNT": true,
+}
+
+// dateTimeLayouts 支持的日期字符串格式，不带时区的按 Location 解析
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"20060102 15:04:05",
+	"20060102150405",
+	"20060102",
+}
+
+// SetLocation 设置日期时间内置变量使用的时区，默认为 time.Local
+// unix 时间戳和 time.Time 转换到该时区，不带时区的日期字符串按该时区解析
+func (m *MaiExecutor) SetLocation(loc *time.Location) {
+	m.Location = loc
+	m.barTimes = nil
+}
+
+func (m *MaiExecutor) location() *time.Location {
+	if m.Location == nil {
+		return time.Local
+	}
+	return m.Location
+}
+
+func (m *MaiExecutor) today() time.Time {
+	now := time.Now
+	if m.now != nil {
+		now = m.now
+	}
+	return now().In(m.location())
+}
+
+// ParseDateTime 把日期时间数组中的一个元素转换为 time.Time
+// 支持日期字符串、unix 秒（13 位按毫秒处理）和 time.Time
+func ParseDateTime(v any, loc *time.Location) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x.In(loc), !x.IsZero()
+	case *time.Time:
+		if x == nil {
+			return time.Time{}, false
+		}
+		return ParseDateTime(*x, loc)
+	case string:
+		return parseDateTimeString(strings.TrimSpace(x), loc)
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return time.Time{}, false
+		}
+		return unixTime(x, loc), true
+	case float32, int, int32, int64, uint32, uint64:
+		return unixTime(cast.ToFloat64(x), loc), true
+	}
+	return time.Time{}, false
+}
+
+func unixTime(sec float64, loc *time.Location) time.Time {
+	if math.Abs(sec) >= 1e11 {
+		return time.UnixMilli(int64(sec)).In(loc)
+	}
+	whole, frac := math.Modf(sec)
+	return time.Unix(int64(whole), int64(frac*1e9)).In(loc)
+}
+
+func parseDateTimeString(s string, loc *time.Location) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc), true
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	// 8 位数字已经按 20060102 解析，其余纯数字按时间戳处理
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return unixTime(sec, loc), true
+	}
+	return time.Time{}, false
+}
+
+// barTimeSeries 每根 K 线的时间，无法解析的位置 valid 为 false
+type barTimeSeries struct {
+	times []time.Time
+	valid []bool
+}
+
+// getBarTimes 解析日期时间数组，结果在一次执行内复用
+func (m *MaiExecutor) getBarTimes() *barTimeSeries {
+	if m.barTimes != nil {
+		return m.barTimes
+	}
+	raw := m.GetDateTimeArray()
+	if raw == nil {
+		return nil
+	}
+	loc := m.location()
+	bt := &barTimeSeries{times: make([]time.Time, len(raw)), valid: make([]bool, len(raw))}
+	for i, v := range raw {
+		bt.times[i], bt.valid[i] = ParseDateTime(v, loc)
+	}
+	m.barTimes = bt
+	return bt
+}
+
+// barCount 获取 K 线数量，优先使用日期时间数组的长度
+func (m *MaiExecutor) barCount() (int, bool) {
+	if raw := m.GetDateTimeArray(); raw != nil {
+		return len(raw), true
+	}
+	for _, name := range []string{"C", "CLOSE"} {
+		if v, ok := m.MylangInterpreter.GetVariable(name); ok {
+			if s, ok := mylang.ToSlice(v); ok {
+				return len(s), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// builtinVariable 计算内置变量，不是内置变量或者缺少数据时返回 nil
+func (m *MaiExecutor) builtinVariable(name string) any {
+	if limitVarNames[name] {
+		return m.limitVariable(name)
+	}
+	if symbolInfoVarNames[name] {
+		return m.symbolInfoVariable(name)
+	}
+	if financeVarNames[name] {
+		return m.financeVariable(name)
+	}
+	if !dateTimeVarNames[name] {
+		return nil
+	}
+	switch name {
+	case "ISLASTBAR", "BARPOS", "TOTALBARSCOUNT":
+		n, ok := m.barCount()
+		if !ok {
+			return nil
+		}
+		return barPosition(name, n)
+	}
+
+	bt := m.getBarTimes()
+	if bt == nil {
+		return nil
+	}
+	if name == "PERIOD" {
+		return indicators.Series{inferPeriod(bt)}
+	}
+	result := make(indicators.Series, len(bt.times))
+	for i, t := range bt.times {
+		if !bt.valid[i] {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = dateTimeField(name, t)
+	}
+	return result
+}
+
+func barPosition(name string, n int) any {
+	switch name {
+	case "ISLASTBAR":
+		result := make([]bool, n)
+		if n > 0 {
+			result[n-1] = true
+		}
+		return result
+	case "BARPOS":
+		result := make(indicators.Series, n)
+		for i := range result {
+			result[i] = float64(i + 1)
+		}
+		return result
+	default:
+		result := make(indicators.Series, n)
+		for i := range result {
+			result[i] = float64(n)
+		}
+		return result
+	}
+}
+
+func dateTimeField(name string, t time.Time) float64 {
+	switch name {
+	case "DATE":
+		return float64((t.Year()-1900)*10000 + int(t.Month())*100 + t.Day())
+	case "TIME":
+		return float64(t.Hour()*10000 + t.Minute()*100 + t.Second())
+	case "YEAR":
+		return float64(t.Year())
+	case "MONTH":
+		return float64(t.Month())
+	case "DAY":
+		return float64(t.Day())
+	case "HOUR":
+		return float64(t.Hour())
+	case "MINUTE":
+		return float64(t.Minute())
+	case "WEEKDAY":
+		return float64(t.Weekday())
+	}
+	return math.NaN()
+}
+
+// inferPeriod 按相邻 K 线间隔的中位数推断周期，K 线不足两根时为 NaN
+func inferPeriod(bt *barTimeSeries) float64 {
+	var gaps []time.Duration
+	var last time.Time
+	hasLast := false
+	for i, t := range bt.times {
+		if !bt.valid[i] {
+			continue
+		}
+		if hasLast && t.After(last) {
+			gaps = append(gaps, t.Sub(last))
+		}
+		last, hasLast = t, true
+	}
+	if len(gaps) == 0 {
+		return math.NaN()
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	gap := gaps[len(gaps)/2]
+
+	const day = 24 * time.Hour
+	switch {
+	case gap == time.Minute:
+		return 7
+	case gap == 5*time.Minute:
+		return 0
+	case gap == 15*time.Minute:
+		return 1
+	case gap == 30*time.Minute:
+		return 2
+	case gap == time.Hour:
+		return 3
+	case gap < day:
+		return 8
+	case gap < 2*day:
+		return 4
+	case gap >= 5*day && gap <= 8*day:
+		return 5
+	case gap >= 28*day && gap <= 31*day:
+		return 6
+	case gap >= 89*day && gap <= 92*day:
+		return 10
+	case gap >= 365*day && gap <= 366*day:
+		return 11
+	}
+	return 9
+}
+
+// dateToDay 把 DATE 格式的日期转换为 UTC 零点，用于按自然日计算间隔
+func dateToDay(v float64) (time.Time, bool) {
+	if math.IsNaN(v) || v <= 0 {
+		return time.Time{}, false
+	}
+	d := int(v)
+	return time.Date(d/10000+1900, time.Month(d/100%100), d%100, 0, 0, 0, 0, time.UTC), true
+}
+
+// registerDateTimeFuncs 注册依赖当前日期的函数
+func (m *MaiExecutor) registerDateTimeFuncs() {
+	// DATETODAY(X) X 为 DATE 格式的日期，返回到今天的自然日天数
+	m.RegisterFunction("DATETODAY", func(args []interface{}) interface{} {
+		checkArgCount("DATETODAY", args, 1, 1)
+		t := m.today()
+		today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		days := func(v float64) float64 {
+			d, ok := dateToDay(v)
+			if !ok {
+				return math.NaN()
+			}
+			return math.Round(today.Sub(d).Hours() / 24)
+		}
+		switch x := args[0].(type) {
+		case float64:
+			return days(x)
+		case int:
+			return days(float64(x))
+		}
+		src := Arrayfloat64(args[0])
+		result := make(indicators.Series, len(src))
+		for i, v := range src {
+			result[i] = days(v)
+		}
+		return result
+	})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+func TestDateTimeVariables(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		name     string
+		dateTime any
+		variable string
+		want     indicators.Series
+	}{
+		{"字符串日期 DATE", []any{"2024-04-09", "2024-04-10"}, "DATE", indicators.Series{1240409, 1240410}},
+		{"字符串日期 TIME", []any{"2024-04-09", "2024-04-10"}, "TIME", indicators.Series{0, 0}},
+		{"字符串时间", []string{"2024-04-09 09:35:00", "2024/04/09 14:59:30"}, "TIME", indicators.Series{93500, 145930}},
+		{"无法解析", []any{"2024-04-09", "abc", nil}, "YEAR", indicators.Series{2024, math.NaN(), math.NaN()}},
+		// 1712626500 = 2024-04-09 01:35:00 UTC = 09:35 CST
+		{"unix 秒", []float64{1712626500}, "HOUR", indicators.Series{9}},
+		{"unix 毫秒", []any{int64(1712626500000)}, "MINUTE", indicators.Series{35}},
+		{"time.Time 转换时区", []time.Time{time.Date(2024, 4, 9, 23, 0, 0, 0, time.UTC)}, "DAY", indicators.Series{10}},
+		{"MONTH", []any{"2024-12-31"}, "MONTH", indicators.Series{12}},
+		{"WEEKDAY", []any{"2024-04-07", "2024-04-09"}, "WEEKDAY", indicators.Series{0, 2}},
+		{"日线 PERIOD", []any{"2024-04-08", "2024-04-09", "2024-04-10", "2024-04-11", "2024-04-12", "2024-04-15"}, "PERIOD", indicators.Series{4}},
+		{"5 分钟 PERIOD", []any{"2024-04-09 09:35", "2024-04-09 09:40", "2024-04-09 09:45"}, "PERIOD", indicators.Series{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewMaiExecutor()
+			executor.SetLocation(shanghai)
+			executor.SetVar("dateTime", tt.dateTime)
+			if err := executor.RunCode("X:" + tt.variable + ";"); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := executor.GetVariable("X")
+			if !equalSeries(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.variable, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBarPositionVariables(t *testing.T) {
+	executor := NewMaiExecutor()
+	executor.SetVar("C", []float64{10, 11, 12})
+	err := executor.RunCode("A:BARPOS;B:TOTALBARSCOUNT;D:ISLASTBAR;E:BARPOS>=TOTALBARSCOUNT-1;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := executor.GetVariable("A")
+	b, _ := executor.GetVariable("B")
+	if !equalSeries(a, indicators.Series{1, 2, 3}) || !equalSeries(b, indicators.Series{3, 3, 3}) {
+		t.Errorf("BARPOS = %v, TOTALBARSCOUNT = %v", a, b)
+	}
+	if got := executor.GetBoolArray("D"); !reflect.DeepEqual(got, []bool{false, false, true}) {
+		t.Errorf("ISLASTBAR = %v", got)
+	}
+	if got := executor.GetBoolArray("E"); !reflect.DeepEqual(got, []bool{false, true, true}) {
+		t.Errorf("BARPOS>=TOTALBARSCOUNT-1 = %v", got)
+	}
+}
+
+func TestDateTimeVariablePriority(t *testing.T) {
+	executor := NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09"})
+	executor.SetVar("YEAR", []float64{1})
+	if err := executor.RunCode("X:YEAR;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := executor.GetFloat64Array("X"); !reflect.DeepEqual(got, []float64{1}) {
+		t.Errorf("绑定的变量应当优先, got %v", got)
+	}
+
+	// 每次执行重新读取日期数组
+	executor.SetVar("dateTime", []any{"2025-01-02"})
+	if err := executor.RunCode("Y:MONTH;"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := executor.GetVariable("Y"); !equalSeries(got, indicators.Series{1}) {
+		t.Errorf("MONTH = %v, want [1]", got)
+	}
+
+	if err := NewMaiExecutor().RunCode("Z:DATE;"); err == nil {
+		t.Errorf("没有日期数组时 DATE 应当报错")
+	}
+}
+
+func TestDateToDay(t *testing.T) {
+	executor := NewMaiExecutor()
+	executor.now = func() time.Time { return time.Date(2024, 4, 12, 10, 0, 0, 0, time.UTC) }
+	executor.SetLocation(time.UTC)
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-11", "2023-04-12"})
+	if err := executor.RunCode("A:DATETODAY(DATE);B:=DATETODAY(1240401);"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := executor.GetVariable("A"); !equalSeries(got, indicators.Series{3, 1, 366}) {
+		t.Errorf("DATETODAY(DATE) = %v", got)
+	}
+	if got, _ := executor.GetVariable("B"); got != 11.0 {
+		t.Errorf("DATETODAY(1240401) = %v, want 11", got)
+	}
+}
+
+func equalSeries(got any, want indicators.Series) bool {
+	s, ok := got.(indicators.Series)
+	if !ok || len(s) != len(want) {
+		return false
+	}
+	for i := range s {
+		if math.IsNaN(want[i]) != math.IsNaN(s[i]) || (!math.IsNaN(want[i]) && s[i] != want[i]) {
+			return false
+		}
+	}
+	return true
+}
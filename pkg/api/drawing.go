@@ -0,0 +1,210 @@
+package api
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+	"github.com/spf13/cast"
+)
+
+// DrawingKind 画图函数的类型
+type DrawingKind string
+
+const (
+	DrawingText      DrawingKind = "DRAWTEXT"  // DRAWTEXT(COND,PRICE,TEXT) 条件成立时在 PRICE 位置写文字
+	DrawingIcon      DrawingKind = "DRAWICON"  // DRAWICON(COND,PRICE,TYPE) 条件成立时在 PRICE 位置画图标
+	DrawingStickLine DrawingKind = "STICKLINE" // STICKLINE(COND,PRICE1,PRICE2,WIDTH,EMPTY) 条件成立时在两个价格之间画柱线
+	DrawingKline     DrawingKind = "DRAWKLINE" // DRAWKLINE(HIGH,OPEN,LOW,CLOSE) 画 K 线
+	DrawingPartLine  DrawingKind = "PARTLINE"  // PARTLINE(PRICE,COND1,COLOR1,COND2,COLOR2,...) 按条件分段着色画线
+)
+
+// Drawing 执行公式时画图函数记录的一条画图命令，序列都已经广播到 K 线数量
+type Drawing struct {
+	Kind         DrawingKind
+	Name         string   // 赋值语句的变量名，画图函数单独成句时为空
+	SuffixParams []string // 语句的修饰符，如 COLORRED
+	Cond         []bool   // DRAWTEXT、DRAWICON、STICKLINE 的条件
+	Price        []float64
+	Price2       []float64 // STICKLINE 的第二个价格
+	Text         string    // DRAWTEXT 的文字
+	Icon         int       // DRAWICON 的图标编号
+	Width        float64   // STICKLINE 的宽度
+	Empty        float64   // STICKLINE 的 EMPTY 参数：0 实心，非 0 空心，-1 虚线
+	High         []float64 // DRAWKLINE
+	Open         []float64
+	Low          []float64
+	Close        []float64
+	Colors       []float64 // PARTLINE 每根 K 线的颜色，RGB 值，NaN 为不画
+}
+
+// GetDrawings 返回最近一次执行记录的画图命令，按执行顺序排列
+func (m *MaiExecutor) GetDrawings() []Drawing {
+	return m.drawings
+}
+
+// RGB 颜色值，与 PARTLINE 配合使用
+func RGB(r, g, b int) float64 {
+	return float64(r&0xff<<16 | g&0xff<<8 | b&0xff)
+}
+
+// RGBHex 把 RGB 颜色值转换为 #RRGGBB
+func RGBHex(c float64) string {
+	return fmt.Sprintf("#%06X", int(c)&0xffffff)
+}
+
+// registerDrawingFuncs 注册画图函数，画图函数返回位置价格，画图命令保存在 GetDrawings 中
+func (m *MaiExecutor) registerDrawingFuncs() {
+	m.RegisterFunction("RGB", func(args []interface{}) interface{} {
+		checkArgCount("RGB", args, 3, 3)
+		return RGB(cast.ToInt(args[0]), cast.ToInt(args[1]), cast.ToInt(args[2]))
+	})
+	m.RegisterFunction(string(DrawingText), func(args []interface{}) interface{} {
+		checkArgCount(string(DrawingText), args, 3, 3)
+		n := m.drawingLen(args)
+		d := m.newDrawing(DrawingText)
+		d.Cond, d.Price, d.Text = toBoolsN(args[0], n), toFloatsN(args[1], n), cast.ToString(args[2])
+		return m.addDrawing(d, d.Price)
+	})
+	m.RegisterFunction(string(DrawingIcon), func(args []interface{}) interface{} {
+		checkArgCount(string(DrawingIcon), args, 3, 3)
+		n := m.drawingLen(args)
+		d := m.newDrawing(DrawingIcon)
+		d.Cond, d.Price, d.Icon = toBoolsN(args[0], n), toFloatsN(args[1], n), cast.ToInt(args[2])
+		return m.addDrawing(d, d.Price)
+	})
+	m.RegisterFunction(string(DrawingStickLine), func(args []interface{}) interface{} {
+		checkArgCount(string(DrawingStickLine), args, 3, 5)
+		n := m.drawingLen(args)
+		d := m.newDrawing(DrawingStickLine)
+		d.Cond, d.Price, d.Price2 = toBoolsN(args[0], n), toFloatsN(args[1], n), toFloatsN(args[2], n)
+		if len(args) > 3 {
+			d.Width = cast.ToFloat64(args[3])
+		}
+		if len(args) > 4 {
+			d.Empty = cast.ToFloat64(args[4])
+		}
+		return m.addDrawing(d, d.Price2)
+	})
+	m.RegisterFunction(string(DrawingKline), func(args []interface{}) interface{} {
+		checkArgCount(string(DrawingKline), args, 4, 4)
+		n := m.drawingLen(args)
+		d := m.newDrawing(DrawingKline)
+		d.High, d.Open, d.Low, d.Close = toFloatsN(args[0], n), toFloatsN(args[1], n), toFloatsN(args[2], n), toFloatsN(args[3], n)
+		return m.addDrawing(d, d.Close)
+	})
+	m.RegisterFunction(string(DrawingPartLine), func(args []interface{}) interface{} {
+		if len(args) < 3 || len(args)%2 == 0 {
+			panic(fmt.Sprintf("%s: expected PRICE followed by COND,COLOR pairs, got %d arguments", DrawingPartLine, len(args)))
+		}
+		n := m.drawingLen(args)
+		d := m.newDrawing(DrawingPartLine)
+		d.Price = toFloatsN(args[0], n)
+		d.Colors = make([]float64, n)
+		for i := range d.Colors {
+			d.Colors[i] = math.NaN()
+		}
+		// 多个条件同时成立时使用第一个
+		for k := len(args) - 2; k >= 1; k -= 2 {
+			cond, color := toBoolsN(args[k], n), toFloatsN(args[k+1], n)
+			for i := range d.Colors {
+				if cond[i] {
+					d.Colors[i] = color[i]
+				}
+			}
+		}
+		return m.addDrawing(d, d.Price)
+	})
+}
+
+// newDrawing 创建画图命令，记录当前语句的变量名和修饰符
+func (m *MaiExecutor) newDrawing(kind DrawingKind) *Drawing {
+	d := &Drawing{Kind: kind}
+	switch s := m.Interp.CurrentStatement().(type) {
+	case *mylang.AssignmentStatement:
+		if s.Name != nil {
+			d.Name = s.Name.Value
+		}
+		d.SuffixParams = s.SuffixParams
+	case *mylang.ExpressionStatement:
+		d.SuffixParams = s.SuffixParams
+	}
+	return d
+}
+
+func (m *MaiExecutor) addDrawing(d *Drawing, ret []float64) indicators.Series {
+	m.drawings = append(m.drawings, *d)
+	return indicators.CopySlice(ret)
+}
+
+// drawingLen 画图命令的长度：K 线数量，没有 K 线时取参数中最长序列的长度
+func (m *MaiExecutor) drawingLen(args []interface{}) int {
+	if n, ok := m.barCount(); ok {
+		return n
+	}
+	n := 0
+	for _, arg := range args {
+		if s, ok := mylang.AnySlice(arg); ok && len(s) > n {
+			n = len(s)
+		}
+	}
+	return n
+}
+
+func checkArgCount(name string, args []interface{}, min, max int) {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			panic(fmt.Sprintf("%s: expected %d arguments, got %d", name, min, len(args)))
+		}
+		panic(fmt.Sprintf("%s: expected %d to %d arguments, got %d", name, min, max, len(args)))
+	}
+}
+
+// toFloatsN 把参数转换为长度为 n 的数值序列，标量按 n 广播，长度不足的部分为 NaN
+func toFloatsN(v any, n int) []float64 {
+	out := make([]float64, n)
+	var src []float64
+	switch x := v.(type) {
+	case indicators.Series:
+		src = x
+	case []float64:
+		src = x
+	case []bool:
+		src = make([]float64, len(x))
+		for i, b := range x {
+			if b {
+				src[i] = 1
+			}
+		}
+	case bool:
+		if x {
+			src = []float64{1}
+		} else {
+			src = []float64{0}
+		}
+	default:
+		src = []float64{cast.ToFloat64(v)}
+	}
+	for i := range out {
+		switch {
+		case len(src) == 1:
+			out[i] = src[0]
+		case i < len(src):
+			out[i] = src[i]
+		default:
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// toBoolsN 把参数转换为长度为 n 的条件序列，非零且非 NaN 为 true
+func toBoolsN(v any, n int) []bool {
+	f := toFloatsN(v, n)
+	out := make([]bool, n)
+	for i, x := range f {
+		out[i] = x != 0 && !math.IsNaN(x)
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDrawingFunctions(t *testing.T) {
+	executor := NewMaiExecutor()
+	executor.SetVar("O", []float64{10, 11, 12})
+	executor.SetVar("C", []float64{11, 10, 13})
+	executor.SetVar("L", []float64{9, 9.5, 11})
+	executor.SetVar("H", []float64{12, 11.5, 14})
+	err := executor.RunCode(`
+DRAWTEXT(C>O,L,'买'),COLORRED;
+DRAWICON(C<O,H,2);
+X:STICKLINE(C>O,O,C,3,1),SUB1;
+DRAWKLINE(H,O,L,C);
+PARTLINE(C,C>O,RGB(255,0,0),1,RGB(0,255,0));
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drawings := executor.GetDrawings()
+	if len(drawings) != 5 {
+		t.Fatalf("drawings = %d, want 5", len(drawings))
+	}
+
+	text := drawings[0]
+	if text.Kind != DrawingText || text.Text != "买" || !reflect.DeepEqual(text.SuffixParams, []string{"COLORRED"}) ||
+		!reflect.DeepEqual(text.Cond, []bool{true, false, true}) || !reflect.DeepEqual(text.Price, []float64{9, 9.5, 11}) {
+		t.Errorf("DRAWTEXT = %+v", text)
+	}
+	if icon := drawings[1]; icon.Kind != DrawingIcon || icon.Icon != 2 || !reflect.DeepEqual(icon.Cond, []bool{false, true, false}) {
+		t.Errorf("DRAWICON = %+v", icon)
+	}
+	stick := drawings[2]
+	if stick.Kind != DrawingStickLine || stick.Name != "X" || stick.Width != 3 || stick.Empty != 1 ||
+		!reflect.DeepEqual(stick.Price2, []float64{11, 10, 13}) || !reflect.DeepEqual(stick.SuffixParams, []string{"SUB1"}) {
+		t.Errorf("STICKLINE = %+v", stick)
+	}
+	if kline := drawings[3]; kline.Kind != DrawingKline || !reflect.DeepEqual(kline.High, []float64{12, 11.5, 14}) {
+		t.Errorf("DRAWKLINE = %+v", kline)
+	}
+	part := drawings[4]
+	if want := []float64{RGB(255, 0, 0), RGB(0, 255, 0), RGB(255, 0, 0)}; !reflect.DeepEqual(part.Colors, want) {
+		t.Errorf("PARTLINE colors = %v, want %v", part.Colors, want)
+	}
+	if RGBHex(part.Colors[1]) != "#00FF00" {
+		t.Errorf("RGBHex = %s", RGBHex(part.Colors[1]))
+	}
+
+	// 画图函数返回位置价格
+	if got := executor.GetFloat64Array("X"); !reflect.DeepEqual(got, []float64{11, 10, 13}) {
+		t.Errorf("X = %v", got)
+	}
+
+	// 每次执行重新记录
+	if err := executor.RunCode("DRAWICON(1,C,1);"); err != nil {
+		t.Fatal(err)
+	}
+	if len(executor.GetDrawings()) != 1 {
+		t.Errorf("drawings = %d, want 1", len(executor.GetDrawings()))
+	}
+}
+
+func TestDrawingArguments(t *testing.T) {
+	if got := toFloatsN([]float64{1, 2}, 3); got[0] != 1 || got[1] != 2 || !math.IsNaN(got[2]) {
+		t.Errorf("toFloatsN = %v", got)
+	}
+	if got := toBoolsN(1.0, 2); !reflect.DeepEqual(got, []bool{true, true}) {
+		t.Errorf("toBoolsN = %v", got)
+	}
+
+	executor := NewMaiExecutor()
+	executor.SetVar("C", []float64{1, 2})
+	if err := executor.RunCode("DRAWTEXT(C>1,C);"); err == nil {
+		t.Errorf("DRAWTEXT 缺少参数时应当报错")
+	}
+	if err := executor.RunCode("PARTLINE(C,C>1);"); err == nil {
+		t.Errorf("PARTLINE 参数不成对时应当报错")
+	}
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+	"github.com/spf13/cast"
+)
+
+// 财务数据，按公告日期对齐到每根 K 线：K 线只能看到前一天及以前公告的数据，避免未来函数。
+// 收盘后公告的数据当天不能使用，确定是盘前公告时可以用 SetFinanceSameDay 使用当天公告的数据
+//
+//	FINANCE(N)    财务数据，N 为编号（1 总股本、7 流通股本，其余编号原样作为字段名）或字段名，如 FINANCE('pe')
+//	CAPITAL       流通股本，同 FINANCE(7)
+//	TOTALCAPITAL  总股本，同 FINANCE(1)
+//	HSL           换手率（百分比），成交量/流通股本*100，成交量和流通股本的单位需要一致
+//
+// 股票由 SetSymbol 指定，数据由 SetFundamentalProvider 提供。没有日期时间数组时取最新的数据，
+// K 线早于第一条数据或者没有该字段时为 NaN
+var financeVarNames = map[string]bool{"CAPITAL": true, "TOTALCAPITAL": true, "HSL": true}
+
+// 常用的财务字段
+const (
+	FieldTotalCapital = "total_capital" // 总股本
+	FieldCapital      = "capital"       // 流通股本
+)
+
+// financeFields FINANCE(N) 的编号，与通达信相同
+var financeFields = map[int]string{
+	1: FieldTotalCapital,
+	7: FieldCapital,
+}
+
+// FundamentalRecord 一次公告的财务数据，Date 为公告（生效）日期
+type FundamentalRecord struct {
+	Date   time.Time
+	Values map[string]float64
+}
+
+// FundamentalProvider 按股票代码提供财务数据，记录的顺序任意
+type FundamentalProvider interface {
+	Fundamentals(code string) []FundamentalRecord
+}
+
+// LocalFundamentalProvider 从本地 JSON、CSV 文件加载的财务数据，代码的写法同 LocalSymbolInfoProvider
+type LocalFundamentalProvider struct {
+	records map[string][]FundamentalRecord
+}
+
+// NewLocalFundamentalProvider 创建一个空的本地财务数据
+func NewLocalFundamentalProvider() *LocalFundamentalProvider {
+	return &LocalFundamentalProvider{records: make(map[string][]FundamentalRecord)}
+}
+
+// Add 添加一条财务数据，同一天的数据合并
+func (p *LocalFundamentalProvider) Add(code string, record FundamentalRecord) {
+	key := codeKey(code)
+	records := p.records[key]
+	for _, r := range records {
+		if r.Date.Equal(record.Date) {
+			for k, v := range record.Values {
+				r.Values[k] = v
+			}
+			return
+		}
+	}
+	values := make(map[string]float64, len(record.Values))
+	for k, v := range record.Values {
+		values[k] = v
+	}
+	records = append(records, FundamentalRecord{Date: record.Date, Values: values})
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Date.Before(records[j].Date) })
+	p.records[key] = records
+}
+
+// Fundamentals 实现 FundamentalProvider，按日期排序
+func (p *LocalFundamentalProvider) Fundamentals(code string) []FundamentalRecord {
+	return p.records[codeKey(code)]
+}
+
+// addRow 添加 JSON、CSV 中的一行，code、date 以外的列都是字段，空值忽略。
+// 列名按小写处理，与 FINANCE('PE') 的字段名一致
+func (p *LocalFundamentalProvider) addRow(row map[string]any) error {
+	lower := make(map[string]any, len(row))
+	for k, v := range row {
+		lower[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	row = lower
+	code := strings.TrimSpace(cast.ToString(row["code"]))
+	if code == "" {
+		return fmt.Errorf("fundamentals: missing code")
+	}
+	date, ok := ParseDateTime(row["date"], time.Local)
+	if !ok {
+		return fmt.Errorf("fundamentals %s: invalid date %v", code, row["date"])
+	}
+	record := FundamentalRecord{Date: date, Values: make(map[string]float64)}
+	for k, v := range row {
+		if k == "code" || k == "date" || v == nil || v == "" {
+			continue
+		}
+		f, err := cast.ToFloat64E(v)
+		if err != nil {
+			return fmt.Errorf("fundamentals %s: invalid %s %v", code, k, v)
+		}
+		record.Values[k] = f
+	}
+	p.Add(code, record)
+	return nil
+}
+
+// LoadFundamentalsJSON 从 JSON 数组加载财务数据，日期写作字符串：
+//
+//	[{"code":"600000","date":"2024-04-30","total_capital":2935208,"capital":2935208,"pe":4.5}]
+func (p *LocalFundamentalProvider) LoadFundamentalsJSON(r io.Reader) error {
+	var rows []map[string]any
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return fmt.Errorf("fundamentals: %w", err)
+	}
+	for _, row := range rows {
+		if err := p.addRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFundamentalsCSV 从带表头的 CSV 加载财务数据，必须有 code、date 列：
+//
+//	code,date,total_capital,capital,pe
+//	600000,2024-04-30,2935208,2935208,4.5
+func (p *LocalFundamentalProvider) LoadFundamentalsCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("fundamentals: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+	for _, row := range rows[1:] {
+		values := make(map[string]any, len(row))
+		for i, v := range row {
+			if i < len(header) {
+				values[header[i]] = strings.TrimSpace(v)
+			}
+		}
+		if values["code"] == "" || values["code"] == nil {
+			continue
+		}
+		if err := p.addRow(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFundamentalsFile 按扩展名读取 .json 或 .csv 文件
+func ReadFundamentalsFile(path string) (*LocalFundamentalProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p := NewLocalFundamentalProvider()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = p.LoadFundamentalsJSON(f)
+	case ".csv":
+		err = p.LoadFundamentalsCSV(f)
+	default:
+		err = fmt.Errorf("fundamentals: unsupported file %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetFundamentalProvider 设置财务数据的来源
+func (m *MaiExecutor) SetFundamentalProvider(p FundamentalProvider) {
+	m.fundProvider = p
+}
+
+// SetFinanceSameDay 设置公告当天的 K 线是否使用当天公告的财务数据，默认不使用
+func (m *MaiExecutor) SetFinanceSameDay(enable bool) {
+	m.financeSameDay = enable
+}
+
+// financeField FINANCE 的参数转换为字段名
+func financeField(arg any) string {
+	if s, ok := arg.(string); ok {
+		return strings.ToLower(s)
+	}
+	n := int(toFloatsN(arg, 1)[0])
+	if name, ok := financeFields[n]; ok {
+		return name
+	}
+	return strconv.Itoa(n)
+}
+
+// financeSeries 按 K 线日期对齐财务字段，没有日期时间数组时返回最新的值
+func (m *MaiExecutor) financeSeries(field string) any {
+	var records []FundamentalRecord
+	if m.fundProvider != nil {
+		for _, r := range m.fundProvider.Fundamentals(m.symbol) {
+			if _, ok := r.Values[field]; ok {
+				records = append(records, r)
+			}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Date.Before(records[j].Date) })
+
+	bt := m.getBarTimes()
+	if bt == nil {
+		if len(records) == 0 {
+			return math.NaN()
+		}
+		return records[len(records)-1].Values[field]
+	}
+	loc := m.location()
+	days := make([]int, len(records))
+	for i, r := range records {
+		days[i] = dayKey(r.Date, loc)
+	}
+	result := make(indicators.Series, len(bt.times))
+	for i, t := range bt.times {
+		result[i] = math.NaN()
+		if !bt.valid[i] {
+			continue
+		}
+		// records[k-1] 为最后一条可以使用的数据
+		day := dayKey(t, loc)
+		k := sort.Search(len(days), func(k int) bool { return days[k] >= day })
+		if m.financeSameDay {
+			k = sort.Search(len(days), func(k int) bool { return days[k] > day })
+		}
+		if k > 0 {
+			result[i] = records[k-1].Values[field]
+		}
+	}
+	return result
+}
+
+// dayKey 日期在 loc 时区的年月日，用于按天比较
+func dayKey(t time.Time, loc *time.Location) int {
+	y, mon, d := t.In(loc).Date()
+	return y*10000 + int(mon)*100 + d
+}
+
+// financeVariable 计算 CAPITAL、TOTALCAPITAL、HSL
+func (m *MaiExecutor) financeVariable(name string) any {
+	switch name {
+	case "CAPITAL":
+		return m.financeSeries(FieldCapital)
+	case "TOTALCAPITAL":
+		return m.financeSeries(FieldTotalCapital)
+	}
+	var vol []float64
+	for _, key := range []string{"V", "VOL", "VOLUME"} {
+		if v, ok := m.MylangInterpreter.GetVariable(key); ok {
+			if s, ok := mylang.AnySlice(v); ok {
+				vol = toFloatsN(v, len(s))
+				break
+			}
+		}
+	}
+	if vol == nil {
+		return nil
+	}
+	capital := toFloatsN(m.financeSeries(FieldCapital), len(vol))
+	result := make(indicators.Series, len(vol))
+	for i := range vol {
+		result[i] = vol[i] / capital[i] * 100
+		if capital[i] == 0 {
+			result[i] = math.NaN()
+		}
+	}
+	return result
+}
+
+// registerFinanceFuncs 注册 FINANCE
+func (m *MaiExecutor) registerFinanceFuncs() {
+	m.RegisterFunction("FINANCE", func(args []interface{}) interface{} {
+		checkArgCount("FINANCE", args, 1, 1)
+		return m.financeSeries(financeField(args[0]))
+	})
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFundamentalsCSV = `code,date,total_capital,capital,pe
+600000.SH,2024-01-02,1000,400,5
+600000.SH,2024-01-04,1000,500,
+600000.SH,2024-01-04,,,6
+000001,2024-01-01,2000,2000,8
+`
+
+func TestLoadFundamentals(t *testing.T) {
+	p := NewLocalFundamentalProvider()
+	if err := p.LoadFundamentalsCSV(strings.NewReader(testFundamentalsCSV)); err != nil {
+		t.Fatal(err)
+	}
+	records := p.Fundamentals("SH600000")
+	if len(records) != 2 || !reflect.DeepEqual(records[1].Values, map[string]float64{"total_capital": 1000, "capital": 500, "pe": 6}) {
+		t.Errorf("Fundamentals(SH600000) = %+v", records)
+	}
+
+	json := `[{"code":"600000","date":"2023-12-29","capital":300},{"code":"600000","date":"2024-01-03","roe":0.1}]`
+	if err := p.LoadFundamentalsJSON(strings.NewReader(json)); err != nil {
+		t.Fatal(err)
+	}
+	if records := p.Fundamentals("600000"); len(records) != 4 || records[0].Values["capital"] != 300 {
+		t.Errorf("records should be sorted by date: %+v", records)
+	}
+	// JSON 的键按小写处理
+	if err := p.LoadFundamentalsJSON(strings.NewReader(`[{"Code":"600000","Date":"2024-01-05","PE":4.5}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if records := p.Fundamentals("600000"); len(records) != 5 || !reflect.DeepEqual(records[4].Values, map[string]float64{"pe": 4.5}) {
+		t.Errorf("upper case JSON keys: %+v", records)
+	}
+	for _, bad := range []string{`[{"date":"2024-01-01"}]`, `[{"code":"1","date":"x"}]`, `[{"code":"1","date":"2024-01-01","pe":"abc"}]`, `[`} {
+		if err := p.LoadFundamentalsJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadFundamentalsJSON(%s) should fail", bad)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "finance.csv")
+	if err := os.WriteFile(path, []byte(testFundamentalsCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ReadFundamentalsFile(path); err != nil || len(p.Fundamentals("000001.SZ")) != 1 || len(p.Fundamentals("000001.SH")) != 0 {
+		t.Errorf("ReadFundamentalsFile() = %v, %v", p, err)
+	}
+}
+
+func TestFinanceFunctions(t *testing.T) {
+	p := NewLocalFundamentalProvider()
+	if err := p.LoadFundamentalsCSV(strings.NewReader(testFundamentalsCSV)); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetFundamentalProvider(p)
+	m.SetSymbol("600000")
+	m.SetVar("dateTime", []any{"2024-01-01", "2024-01-02", "2024-01-03", "2024-01-04 15:00:00", "2024-01-05"})
+	m.SetVar("C", []float64{10, 10, 10, 10, 10})
+	m.SetVar("VOLUME", []float64{40, 40, 20, 50, 100})
+
+	nan := math.NaN()
+	check := func(want map[string][]float64) {
+		t.Helper()
+		if err := m.RunCode("A:CAPITAL;B:TOTALCAPITAL;D:FINANCE(7);E:FINANCE('PE');F:HSL;G:FINANCE(99);"); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range want {
+			got := m.GetFloat64Array(name)
+			if len(got) != len(want) {
+				t.Errorf("%s = %v, want %v", name, got, want)
+				continue
+			}
+			for i := range want {
+				if got[i] != want[i] && !(math.IsNaN(got[i]) && math.IsNaN(want[i])) {
+					t.Errorf("%s = %v, want %v", name, got, want)
+					break
+				}
+			}
+		}
+	}
+	// 1 月 2 日、1 月 4 日公告的数据从下一个交易日开始使用
+	check(map[string][]float64{
+		"A": {nan, nan, 400, 400, 500},
+		"B": {nan, nan, 1000, 1000, 1000},
+		"D": {nan, nan, 400, 400, 500},
+		"E": {nan, nan, 5, 5, 6},
+		"F": {nan, nan, 5, 12.5, 20},
+		"G": {nan, nan, nan, nan, nan},
+	})
+	// 盘前公告，当天就可以使用
+	m.SetFinanceSameDay(true)
+	check(map[string][]float64{
+		"A": {nan, 400, 400, 500, 500},
+		"E": {nan, 5, 5, 6, 6},
+		"F": {nan, 10, 5, 10, 20},
+	})
+
+	// 没有日期时间数组时取最新的数据
+	m = NewMaiExecutor()
+	m.SetFundamentalProvider(p)
+	m.SetSymbol("000001.SZ")
+	m.SetVar("V", []float64{20, 200})
+	if err := m.RunCode("A:CAPITAL;F:HSL;"); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := m.GetVariable("A"); a != 2000.0 {
+		t.Errorf("CAPITAL = %v", a)
+	}
+	if got := m.GetFloat64Array("F"); !reflect.DeepEqual(got, []float64{1, 10}) {
+		t.Errorf("HSL = %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+)
+
+// FutureFunctions 返回已编译程序中用到的未来函数（REFX、BACKSET、ZIG 等），按名称排序。
+// 负数索引和负数边界的切片（如 C[-1]、C[-20:]）取最后几根 K 线，记为 "[]"。
+// 使用未来函数的公式在历史上的信号会被之后的行情修改，回测前应当检查
+func (m *MaiExecutor) FutureFunctions() []string {
+	if m.PreCompiledProgram == nil {
+		return nil
+	}
+	found := map[string]bool{}
+	for _, stmt := range m.PreCompiledProgram.Statements {
+		switch s := stmt.(type) {
+		case *mylang.AssignmentStatement:
+			collectFutureFunctions(s.Value, found)
+		case *mylang.ExpressionStatement:
+			collectFutureFunctions(s.Expression, found)
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func collectFutureFunctions(expr mylang.Expression, found map[string]bool) {
+	switch e := expr.(type) {
+	case *mylang.BinaryExpression:
+		collectFutureFunctions(e.Left, found)
+		collectFutureFunctions(e.Right, found)
+	case *mylang.UnaryExpression:
+		collectFutureFunctions(e.Right, found)
+	case *mylang.ConditionalExpression:
+		collectFutureFunctions(e.Condition, found)
+		collectFutureFunctions(e.Consequence, found)
+		collectFutureFunctions(e.Alternative, found)
+	case *mylang.IndexExpression:
+		if isNegativeNumber(e.Index) || isNegativeNumber(e.Start) || isNegativeNumber(e.End) {
+			found["[]"] = true
+		}
+		for _, x := range []mylang.Expression{e.Left, e.Index, e.Start, e.End} {
+			collectFutureFunctions(x, found)
+		}
+	case *mylang.FunctionCall:
+		if ident, ok := e.Function.(*mylang.Identifier); ok {
+			if name := strings.ToUpper(ident.Value); indicators.IsFutureFunction(name) {
+				found[name] = true
+			}
+		}
+		for _, arg := range e.Arguments {
+			collectFutureFunctions(arg, found)
+		}
+	}
+}
+
+// isNegativeNumber expr 是否为负数常量，如 -1
+func isNegativeNumber(expr mylang.Expression) bool {
+	switch e := expr.(type) {
+	case *mylang.NumberLiteral:
+		return e.Value < 0
+	case *mylang.UnaryExpression:
+		if n, ok := e.Right.(*mylang.NumberLiteral); ok {
+			return e.Operator == "-" && n.Value > 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFutureFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{"没有未来函数", "A:REF(C,1);B:=MA(C,5);", []string{}},
+		{"嵌套调用", "A:MA(REFX(C,1),5)>ZIG(C,5);", []string{"REFX", "ZIG"}},
+		{"条件和一元表达式", "B:=BACKSET(C>O,2);X:-PEAK(C,5,1);B AND zig(C,5)>0;", []string{"BACKSET", "PEAK", "ZIG"}},
+		{"条件表达式", "A:C>O ? REFX(C,1) : BARSLAST(C>ZIG(C,5));", []string{"REFX", "ZIG"}},
+		{"负数索引", "A:C[-1];", []string{"[]"}},
+		{"负数切片", "A:C[-20:]+C[:-1];B:REFX(C,1);", []string{"REFX", "[]"}},
+		{"正数索引和切片", "A:C[1]+C[10:20]+C[BARSLAST(C>O)];", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewMaiExecutor()
+			if err := executor.CompileCode(tt.code); err != nil {
+				t.Fatal(err)
+			}
+			if got := executor.FutureFunctions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FutureFunctions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
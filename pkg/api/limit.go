@@ -0,0 +1,305 @@
+package api
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+)
+
+// 涨跌停，按 A 股规则计算：昨收*(1±涨跌幅) 按十进制四舍五入到 0.01 元
+//
+//	ZTPRICE(X)  DTPRICE(X)  X 为昨收，涨跌幅按当前股票的板块和 ST 状态确定，上市初期不设涨跌幅的交易日为 NaN
+//	ISZT  ISDT              收盘价是否等于涨停价、跌停价，昨收取前一个交易日最后一根 K 线的收盘价，分钟线也按日计算
+//	ZTCOUNT                 连续涨停的 K 线数，当天没有涨停为 0
+//
+// 股票由 SetSymbol 指定，板块、ST 和上市日期由 SetSymbolMetaProvider 提供，没有时使用 SetSymbolInfoProvider 的信息，
+// 都没有时按代码推断板块。
+// ZTPRICE(X,N) 仍然按给定的涨跌幅 N 计算，见精确模式
+var limitVarNames = map[string]bool{"ISZT": true, "ISDT": true, "ZTCOUNT": true}
+
+// Board 股票所属的板块
+type Board int
+
+const (
+	BoardMain    Board = iota // 沪深主板，涨跌幅 10%，ST 为 5%
+	BoardChiNext              // 创业板，涨跌幅 20%
+	BoardSTAR                 // 科创板，涨跌幅 20%
+	BoardBSE                  // 北交所，涨跌幅 30%
+)
+
+func (b Board) String() string {
+	switch b {
+	case BoardMain:
+		return "main"
+	case BoardChiNext:
+		return "chinext"
+	case BoardSTAR:
+		return "star"
+	case BoardBSE:
+		return "bse"
+	}
+	return "unknown"
+}
+
+// InferBoard 按股票代码推断板块，代码可以带交易所前缀或后缀，如 SZ300750、688981.SH、430047.BJ
+func InferBoard(code string) Board {
+	upper := strings.ToUpper(code)
+	if strings.HasPrefix(upper, "BJ") || strings.HasSuffix(upper, "BJ") {
+		return BoardBSE
+	}
+	digits := codeDigits(code)
+	switch {
+	case strings.HasPrefix(digits, "30"):
+		return BoardChiNext
+	case strings.HasPrefix(digits, "688"), strings.HasPrefix(digits, "689"):
+		return BoardSTAR
+	case strings.HasPrefix(digits, "8"), strings.HasPrefix(digits, "43"), strings.HasPrefix(digits, "92"):
+		return BoardBSE
+	}
+	return BoardMain
+}
+
+// SymbolMeta 计算涨跌停所需的股票信息
+type SymbolMeta struct {
+	Code        string
+	Board       Board
+	ST          bool      // ST、*ST，主板涨跌幅为 5%，其他板块不变
+	ListDate    time.Time // 上市日期，零值表示不考虑上市初期的规则
+	NoLimitDays int       // 上市后不设涨跌幅的交易日数，0 按板块默认：北交所 1 天，其他板块 5 天；小于 0 表示没有
+}
+
+// LimitRate 涨跌幅限制
+func (s SymbolMeta) LimitRate() float64 {
+	switch s.Board {
+	case BoardChiNext, BoardSTAR:
+		return 0.2
+	case BoardBSE:
+		return 0.3
+	}
+	if s.ST {
+		return 0.05
+	}
+	return 0.1
+}
+
+// noLimitDays 上市后不设涨跌幅的交易日数
+func (s SymbolMeta) noLimitDays() int {
+	switch {
+	case s.ListDate.IsZero() || s.NoLimitDays < 0:
+		return 0
+	case s.NoLimitDays > 0:
+		return s.NoLimitDays
+	case s.Board == BoardBSE:
+		return 1
+	}
+	return 5
+}
+
+// SymbolMetaProvider 按股票代码提供 SymbolMeta
+type SymbolMetaProvider interface {
+	SymbolMeta(code string) (SymbolMeta, bool)
+}
+
+// SymbolMetaMap 用 map 实现的 SymbolMetaProvider
+type SymbolMetaMap map[string]SymbolMeta
+
+func (mp SymbolMetaMap) SymbolMeta(code string) (SymbolMeta, bool) {
+	s, ok := mp[code]
+	return s, ok
+}
+
+// SymbolMetaFunc 用函数实现的 SymbolMetaProvider
+type SymbolMetaFunc func(code string) (SymbolMeta, bool)
+
+func (f SymbolMetaFunc) SymbolMeta(code string) (SymbolMeta, bool) {
+	return f(code)
+}
+
+// limitTick 交易所计算涨跌停价使用的最小变动价位
+var limitTick = newPrecisionMode(DefaultTickSize)
+
+// LimitUpPrice 按十进制计算涨停价，四舍五入到 0.01 元
+func LimitUpPrice(prevClose float64, meta SymbolMeta) float64 {
+	return limitTick.limitPrice(prevClose, meta.LimitRate())
+}
+
+// LimitDownPrice 按十进制计算跌停价，四舍五入到 0.01 元
+func LimitDownPrice(prevClose float64, meta SymbolMeta) float64 {
+	return limitTick.limitPrice(prevClose, -meta.LimitRate())
+}
+
+// SetSymbol 设置当前执行的股票代码，用于查询 SymbolMeta
+func (m *MaiExecutor) SetSymbol(code string) {
+	m.symbol = code
+}
+
+// Symbol 返回当前执行的股票代码
+func (m *MaiExecutor) Symbol() string {
+	return m.symbol
+}
+
+// SetSymbolMetaProvider 设置涨跌停使用的股票信息，传入 nil 时使用 SymbolInfo
+func (m *MaiExecutor) SetSymbolMetaProvider(p SymbolMetaProvider) {
+	m.metaProvider = p
+}
+
+// SymbolMeta 返回当前股票的信息，provider 中没有时使用 SymbolInfo
+func (m *MaiExecutor) SymbolMeta() SymbolMeta {
+	if m.metaProvider != nil {
+		if s, ok := m.metaProvider.SymbolMeta(m.symbol); ok {
+			if s.Code == "" {
+				s.Code = m.symbol
+			}
+			return s
+		}
+	}
+	return m.SymbolInfo().Meta()
+}
+
+// noLimitBars 标记上市初期不设涨跌幅的 K 线，按日期时间数组计算交易日，没有日期时间时返回 nil。
+// 数据从上市以后才开始时，上市日到第一根 K 线之间的交易日按工作日估算
+func (m *MaiExecutor) noLimitBars(meta SymbolMeta) []bool {
+	days := meta.noLimitDays()
+	if days == 0 {
+		return nil
+	}
+	bt := m.getBarTimes()
+	if bt == nil {
+		return nil
+	}
+	loc := m.location()
+	y, mon, d := meta.ListDate.In(loc).Date()
+	listDay := time.Date(y, mon, d, 0, 0, 0, 0, loc)
+	result := make([]bool, len(bt.times))
+	count := 0
+	var lastDay time.Time
+	for i, t := range bt.times {
+		if !bt.valid[i] {
+			continue
+		}
+		y, mon, d := t.In(loc).Date()
+		day := time.Date(y, mon, d, 0, 0, 0, 0, loc)
+		if day.Before(listDay) {
+			continue
+		}
+		if !day.Equal(lastDay) {
+			if lastDay.IsZero() {
+				count = weekdaysBetween(listDay, day, days)
+			}
+			count++
+			lastDay = day
+		}
+		result[i] = count <= days
+	}
+	return result
+}
+
+// weekdaysBetween [from, to) 之间的工作日数，超过 limit 时返回 limit
+func weekdaysBetween(from, to time.Time, limit int) int {
+	n := 0
+	for day := from; day.Before(to) && n < limit; day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			n++
+		}
+	}
+	return n
+}
+
+// prevCloses 每根 K 线的昨收，即前一个交易日最后一根 K 线的收盘价，同一天的分钟线昨收相同；
+// 没有日期时间数组时取前一根 K 线的收盘价
+func (m *MaiExecutor) prevCloses(closes []float64) []float64 {
+	out := make([]float64, len(closes))
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	bt := m.getBarTimes()
+	if bt == nil || len(bt.times) != len(closes) {
+		for i := 1; i < len(closes); i++ {
+			out[i] = closes[i-1]
+		}
+		return out
+	}
+	loc := m.location()
+	prev, last := math.NaN(), math.NaN()
+	lastDay := -1
+	for i, t := range bt.times {
+		if !bt.valid[i] {
+			continue
+		}
+		if day := dayKey(t, loc); day != lastDay {
+			prev, lastDay = last, day
+		}
+		out[i] = prev
+		if !math.IsNaN(closes[i]) {
+			last = closes[i]
+		}
+	}
+	return out
+}
+
+// limitPriceSeries 计算 ZTPRICE(X)、DTPRICE(X)，sign 为 1 是涨停价，-1 是跌停价
+func (m *MaiExecutor) limitPriceSeries(prev any, sign float64) any {
+	meta := m.SymbolMeta()
+	rate := sign * meta.LimitRate()
+	s, ok := mylang.AnySlice(prev)
+	if !ok {
+		return limitTick.limitPrice(toFloatsN(prev, 1)[0], rate)
+	}
+	noLimit := m.noLimitBars(meta)
+	out := make(indicators.Series, len(s))
+	for i, v := range toFloatsN(prev, len(s)) {
+		if i < len(noLimit) && noLimit[i] {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = limitTick.limitPrice(v, rate)
+	}
+	return out
+}
+
+// limitVariable 计算 ISZT、ISDT、ZTCOUNT，缺少收盘价时返回 nil
+func (m *MaiExecutor) limitVariable(name string) any {
+	var closes []float64
+	for _, key := range []string{"C", "CLOSE"} {
+		if v, ok := m.MylangInterpreter.GetVariable(key); ok {
+			if s, ok := mylang.AnySlice(v); ok {
+				closes = toFloatsN(v, len(s))
+				break
+			}
+		}
+	}
+	if closes == nil {
+		return nil
+	}
+	meta := m.SymbolMeta()
+	noLimit := m.noLimitBars(meta)
+	prev := m.prevCloses(closes)
+	result := make(indicators.Series, len(closes))
+	count := 0.0
+	for i := range closes {
+		hit := false
+		if !math.IsNaN(prev[i]) && !(i < len(noLimit) && noLimit[i]) && !math.IsNaN(closes[i]) {
+			price := limitTick.fromTicks(limitTick.toTicks(closes[i]))
+			switch name {
+			case "ISDT":
+				hit = price == LimitDownPrice(prev[i], meta)
+			default:
+				hit = price == LimitUpPrice(prev[i], meta)
+			}
+		}
+		if name == "ZTCOUNT" {
+			if hit {
+				count++
+			} else {
+				count = 0
+			}
+			result[i] = count
+		} else if hit {
+			result[i] = 1
+		}
+	}
+	return result
+}
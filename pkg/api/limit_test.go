@@ -0,0 +1,142 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInferBoard(t *testing.T) {
+	tests := map[string]Board{
+		"600000":    BoardMain,
+		"000001.SZ": BoardMain,
+		"SZ300750":  BoardChiNext,
+		"688981.SH": BoardSTAR,
+		"430047":    BoardBSE,
+		"920002.BJ": BoardBSE,
+		"bj832000":  BoardBSE,
+	}
+	for code, want := range tests {
+		if got := InferBoard(code); got != want {
+			t.Errorf("InferBoard(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestLimitPrice(t *testing.T) {
+	tests := []struct {
+		meta     SymbolMeta
+		prev     float64
+		up, down float64
+	}{
+		{SymbolMeta{Board: BoardMain}, 10.05, 11.06, 9.05},
+		{SymbolMeta{Board: BoardMain, ST: true}, 3.55, 3.73, 3.37},
+		{SymbolMeta{Board: BoardChiNext, ST: true}, 12.34, 14.81, 9.87},
+		{SymbolMeta{Board: BoardSTAR}, 99.99, 119.99, 79.99},
+		{SymbolMeta{Board: BoardBSE}, 7.77, 10.1, 5.44},
+	}
+	for _, tt := range tests {
+		if got := LimitUpPrice(tt.prev, tt.meta); got != tt.up {
+			t.Errorf("LimitUpPrice(%v, %+v) = %v, want %v", tt.prev, tt.meta, got, tt.up)
+		}
+		if got := LimitDownPrice(tt.prev, tt.meta); got != tt.down {
+			t.Errorf("LimitDownPrice(%v, %+v) = %v, want %v", tt.prev, tt.meta, got, tt.down)
+		}
+	}
+}
+
+func TestLimitVariables(t *testing.T) {
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetVar("dateTime", []any{"2024-04-08", "2024-04-09", "2024-04-10", "2024-04-11", "2024-04-12", "2024-04-15"})
+	m.SetVar("C", []float64{10, 11, 12.1, 13.31, 11.98, 13.18})
+
+	m.SetSymbol("600000.SH")
+	if err := m.RunCode("ZT:ZTPRICE(REF(C,1));DT:DTPRICE(REF(C,1));A:ISZT;B:ISDT;N:ZTCOUNT;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("ZT"); !math.IsNaN(got[0]) || !reflect.DeepEqual(got[1:], []float64{11, 12.1, 13.31, 14.64, 13.18}) {
+		t.Errorf("ZT = %v", got)
+	}
+	if got := m.GetFloat64Array("DT"); !reflect.DeepEqual(got[1:], []float64{9, 9.9, 10.89, 11.98, 10.78}) {
+		t.Errorf("DT = %v", got)
+	}
+	for name, want := range map[string][]float64{
+		"A": {0, 1, 1, 1, 0, 1},
+		"B": {0, 0, 0, 0, 1, 0},
+		"N": {0, 1, 2, 3, 0, 1},
+	} {
+		if got := m.GetFloat64Array(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	// 创业板、上市前 5 个交易日不设涨跌幅
+	m.SetSymbolMetaProvider(SymbolMetaMap{
+		"300001": {Board: BoardChiNext, ListDate: time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC)},
+	})
+	m.SetSymbol("300001")
+	m.SetVar("C", []float64{10, 11, 12.1, 13.31, 14.64, 17.57})
+	if err := m.RunCode("ZT:ZTPRICE(REF(C,1));A:ISZT;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("ZT"); !math.IsNaN(got[4]) || got[5] != 17.57 {
+		t.Errorf("ZT = %v", got)
+	}
+	if got := m.GetFloat64Array("A"); !reflect.DeepEqual(got, []float64{0, 0, 0, 0, 0, 1}) {
+		t.Errorf("A = %v", got)
+	}
+	if meta := m.SymbolMeta(); meta.Code != "300001" || meta.LimitRate() != 0.2 {
+		t.Errorf("SymbolMeta() = %+v", meta)
+	}
+}
+
+func TestLimitVariablesListDate(t *testing.T) {
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetVar("dateTime", []any{"2024-04-08", "2024-04-09", "2024-04-10", "2024-04-11", "2024-04-12", "2024-04-15", "2024-04-16"})
+	m.SetVar("C", []float64{10, 11, 12.1, 13.31, 14.64, 16.1, 17.71})
+
+	// 很早上市的股票，数据开始时已经有涨跌幅限制
+	m.SetSymbolMetaProvider(SymbolMetaMap{
+		"600000": {Board: BoardMain, ListDate: time.Date(1999, 11, 10, 0, 0, 0, 0, time.UTC)},
+		"300001": {Board: BoardChiNext, ListDate: time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC)},
+	})
+	m.SetSymbol("600000")
+	if err := m.RunCode("ZT:ZTPRICE(REF(C,1));A:ISZT;N:ZTCOUNT;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("ZT"); !reflect.DeepEqual(got[1:], []float64{11, 12.1, 13.31, 14.64, 16.1, 17.71}) {
+		t.Errorf("ZT = %v", got)
+	}
+	if got := m.GetFloat64Array("N"); !reflect.DeepEqual(got, []float64{0, 1, 2, 3, 4, 5, 6}) {
+		t.Errorf("N = %v", got)
+	}
+
+	// 4 月 4 日（星期四）上市，数据从 4 月 8 日开始，此前已有 2 个交易日，4 月 10 日是第 5 个交易日
+	m.SetSymbol("300001")
+	if err := m.RunCode("ZT:ZTPRICE(REF(C,1));"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("ZT"); !math.IsNaN(got[2]) || got[3] != 14.52 {
+		t.Errorf("ZT = %v", got)
+	}
+}
+
+func TestLimitVariablesMinute(t *testing.T) {
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetVar("dateTime", []any{"2024-04-08 14:59", "2024-04-08 15:00", "2024-04-09 09:31", "2024-04-09 09:32", "2024-04-09 09:33"})
+	m.SetVar("C", []float64{9.9, 10, 10.5, 11, 11})
+	m.SetSymbol("600000")
+	if err := m.RunCode("A:ISZT;N:ZTCOUNT;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("A"); !reflect.DeepEqual(got, []float64{0, 0, 0, 1, 1}) {
+		t.Errorf("A = %v", got)
+	}
+	if got := m.GetFloat64Array("N"); !reflect.DeepEqual(got, []float64{0, 0, 0, 1, 2}) {
+		t.Errorf("N = %v", got)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// PeriodUnit 周期单位
+type PeriodUnit int
+
+const (
+	PeriodMinute PeriodUnit = iota // 分钟，小时按 60 分钟计算
+	PeriodDay                      // 交易日
+	PeriodWeek                     // 自然周
+	PeriodMonth                    // 自然月
+)
+
+// Period K 线周期，如 1h 为 {60, PeriodMinute}，3M 为 {3, PeriodMonth}
+type Period struct {
+	N    int
+	Unit PeriodUnit
+}
+
+// periodPattern 周期的写法，如 1m、5min、1h、1d、1w、1M
+var periodPattern = regexp.MustCompile(`^([0-9]+)(m|min|h|d|w|M|mon)$`)
+
+// ParsePeriod 解析周期：5m、15min、1h、2d、1w、1M、3M，mon 同 M。
+// ResampleBars 和图表的 KlineChart.Period 使用同样的写法
+func ParsePeriod(period string) (Period, error) {
+	p := periodPattern.FindStringSubmatch(period)
+	if p == nil {
+		return Period{}, fmt.Errorf("invalid period %q", period)
+	}
+	n, _ := strconv.Atoi(p[1])
+	if n <= 0 {
+		return Period{}, fmt.Errorf("invalid period %q", period)
+	}
+	switch p[2] {
+	case "h":
+		return Period{N: n * 60, Unit: PeriodMinute}, nil
+	case "d":
+		return Period{N: n, Unit: PeriodDay}, nil
+	case "w":
+		return Period{N: n, Unit: PeriodWeek}, nil
+	case "M", "mon":
+		return Period{N: n, Unit: PeriodMonth}, nil
+	}
+	return Period{N: n, Unit: PeriodMinute}, nil
+}
+
+// Duration K 线的时间间隔，日线按 24 小时、月线按 30 天计算
+func (p Period) Duration() time.Duration {
+	unit := map[PeriodUnit]time.Duration{
+		PeriodMinute: time.Minute, PeriodDay: 24 * time.Hour, PeriodWeek: 7 * 24 * time.Hour, PeriodMonth: 30 * 24 * time.Hour,
+	}[p.Unit]
+	return time.Duration(p.N) * unit
+}
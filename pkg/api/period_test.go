@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"5m": 5 * time.Minute, "15min": 15 * time.Minute, "1h": time.Hour, "1d": 24 * time.Hour, "1w": 7 * 24 * time.Hour, "1mon": 30 * 24 * time.Hour,
+	} {
+		if p, err := ParsePeriod(s); err != nil || p.Duration() != want {
+			t.Errorf("ParsePeriod(%s) = %+v %v", s, p, err)
+		}
+	}
+	if p, _ := ParsePeriod("3M"); p != (Period{N: 3, Unit: PeriodMonth}) {
+		t.Errorf("ParsePeriod(3M) = %+v", p)
+	}
+	for _, s := range []string{"", "0d", "1y", "d", "5x"} {
+		if _, err := ParsePeriod(s); err == nil {
+			t.Errorf("ParsePeriod(%s) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+)
+
+// 精确模式，用于涨跌停价这类对价格精度敏感的公式
+//
+// 默认情况下价格按 float64 计算，比较运算允许 1% 的误差（见 mylang.ToleranceLegacy），
+// 10.05*1.1 得到 11.055000000000001，和交易所公布的涨停价 11.06 对不上。开启精确模式后：
+//
+//	价格变量   O H L C OPEN HIGH LOW CLOSE 在执行时使用按最小变动价位四舍五入的副本，执行结束后恢复原来绑定的数据
+//	ZTPRICE    DTPRICE 按十进制计算，结果按最小变动价位四舍五入，与交易所规则一致
+//	比较运算   使用 PrecisionOptions.Tolerance 代替默认的 1% 误差
+//
+// 精确模式只处理用 SetVar 等方式绑定到环境中的价格变量，CustomVariableGetter 提供的数据按原样使用
+var priceVarNames = []string{"O", "H", "L", "C", "OPEN", "HIGH", "LOW", "CLOSE"}
+
+// DefaultTickSize 默认的最小变动价位，A 股为 0.01 元
+const DefaultTickSize = 0.01
+
+// PrecisionOptions 精确模式的设置
+type PrecisionOptions struct {
+	TickSize  float64           // 最小变动价位，<= 0 时使用 DefaultTickSize
+	Tolerance *mylang.Tolerance // 比较运算的误差，nil 时为 TickSize 百万分之一的绝对误差
+}
+
+// precisionMode 开启精确模式后使用的设置
+type precisionMode struct {
+	opt           PrecisionOptions
+	tick          *big.Rat
+	decimals      int
+	prevTolerance mylang.Tolerance // 开启前的误差设置，关闭时恢复
+}
+
+// EnablePrecision 开启精确模式，见 PrecisionOptions
+func (m *MaiExecutor) EnablePrecision(opt PrecisionOptions) {
+	if opt.TickSize <= 0 || math.IsNaN(opt.TickSize) || math.IsInf(opt.TickSize, 0) {
+		opt.TickSize = DefaultTickSize
+	}
+	if opt.Tolerance == nil {
+		opt.Tolerance = &mylang.Tolerance{Mode: mylang.ToleranceAbsolute, Value: opt.TickSize * 1e-6}
+	}
+	prev := m.Tolerance()
+	if m.precision != nil {
+		prev = m.precision.prevTolerance
+	}
+	m.precision = newPrecisionMode(opt.TickSize)
+	m.precision.opt = opt
+	m.precision.prevTolerance = prev
+	m.SetTolerance(*opt.Tolerance)
+}
+
+// DisablePrecision 关闭精确模式，比较运算恢复开启前的误差设置（默认为 1% 误差）
+func (m *MaiExecutor) DisablePrecision() {
+	if m.precision == nil {
+		return
+	}
+	m.SetTolerance(m.precision.prevTolerance)
+	m.precision = nil
+}
+
+// Precision 返回精确模式的设置，未开启时第二个返回值为 false
+func (m *MaiExecutor) Precision() (PrecisionOptions, bool) {
+	if m.precision == nil {
+		return PrecisionOptions{}, false
+	}
+	return m.precision.opt, true
+}
+
+// RoundToTick 把价格按最小变动价位四舍五入，结果是最接近该十进制数的 float64
+func RoundToTick(price, tickSize float64) float64 {
+	if tickSize <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return price
+	}
+	p := newPrecisionMode(tickSize)
+	return p.fromTicks(p.toTicks(price))
+}
+
+// newPrecisionMode 按最小变动价位的十进制表示（如 0.01 为两位小数）创建精确模式
+func newPrecisionMode(tickSize float64) *precisionMode {
+	tickStr := strconv.FormatFloat(tickSize, 'f', -1, 64)
+	tick, _ := new(big.Rat).SetString(tickStr)
+	decimals := 0
+	if dot := strings.IndexByte(tickStr, '.'); dot >= 0 {
+		decimals = len(tickStr) - dot - 1
+	}
+	return &precisionMode{tick: tick, decimals: decimals}
+}
+
+// toTicks 价格换算为最小变动价位的整数倍，消除价格数据本身的二进制误差
+func (p *precisionMode) toTicks(price float64) *big.Int {
+	tick, _ := p.tick.Float64()
+	return big.NewInt(int64(math.Round(price / tick)))
+}
+
+// fromTicks 最小变动价位的整数倍换算为价格
+func (p *precisionMode) fromTicks(ticks *big.Int) float64 {
+	r := new(big.Rat).SetInt(ticks)
+	r.Mul(r, p.tick)
+	f, _ := strconv.ParseFloat(r.FloatString(p.decimals), 64)
+	return f
+}
+
+// limitPrice 按十进制计算 prev*(1+rate)，结果按最小变动价位四舍五入
+func (p *precisionMode) limitPrice(prev, rate float64) float64 {
+	if math.IsNaN(prev) || math.IsNaN(rate) || math.IsInf(prev, 0) || math.IsInf(rate, 0) {
+		return math.NaN()
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return math.NaN()
+	}
+	r.Add(r, big.NewRat(1, 1))
+	r.Mul(r, new(big.Rat).SetInt(p.toTicks(prev)))
+	return p.fromTicks(roundHalfUp(r))
+}
+
+// roundHalfUp 四舍五入到整数，.5 远离零
+func roundHalfUp(r *big.Rat) *big.Int {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	rem.Abs(rem).Lsh(rem, 1)
+	if rem.Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// roundedVar roundPriceVars 替换的价格变量
+type roundedVar struct {
+	name     string
+	original any
+	rounded  []float64
+}
+
+// roundPriceVars 精确模式下把环境中的价格变量替换为按最小变动价位四舍五入后的副本，
+// 执行结束后由 restorePriceVars 恢复，调用方绑定的数据不会被修改
+func (m *MaiExecutor) roundPriceVars() {
+	m.roundedVars = m.roundedVars[:0]
+	for _, name := range priceVarNames {
+		v, ok := m.MylangInterpreter.GetVariable(name)
+		if !ok {
+			continue
+		}
+		var src []float64
+		switch x := v.(type) {
+		case []float64:
+			src = x
+		case indicators.Series:
+			src = x
+		default:
+			continue
+		}
+		out := make([]float64, len(src))
+		for i, price := range src {
+			if math.IsNaN(price) || math.IsInf(price, 0) {
+				out[i] = price
+				continue
+			}
+			out[i] = m.precision.fromTicks(m.precision.toTicks(price))
+		}
+		m.roundedVars = append(m.roundedVars, roundedVar{name: name, original: v, rounded: out})
+		m.MylangInterpreter.SetVar(name, out)
+	}
+}
+
+// restorePriceVars 恢复 roundPriceVars 替换的价格变量，公式中重新赋值的变量保留公式的结果
+func (m *MaiExecutor) restorePriceVars() {
+	for _, r := range m.roundedVars {
+		v, ok := m.MylangInterpreter.GetVariable(r.name)
+		if cur, isFloats := v.([]float64); ok && isFloats && sameSlice(cur, r.rounded) {
+			m.MylangInterpreter.SetVar(r.name, r.original)
+		}
+	}
+	m.roundedVars = nil
+}
+
+// sameSlice a、b 是否是同一个切片
+func sameSlice(a, b []float64) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// registerPrecisionFuncs 注册 ZTPRICE、DTPRICE：一个参数时按当前股票的涨跌幅计算（见 limit.go），
+// 两个参数时在精确模式下按十进制计算，涨跌幅可以是数组，未开启精确模式时和内置指标相同
+func (m *MaiExecutor) registerPrecisionFuncs() {
+	for name, sign := range map[string]float64{"ZTPRICE": 1, "DTPRICE": -1} {
+		m.RegisterFunction(name, func(args []interface{}) interface{} {
+			if len(args) == 1 {
+				return m.limitPriceSeries(args[0], sign)
+			}
+			if m.precision == nil {
+				return m.callIndicator(name, args)
+			}
+			checkArgCount(name, args, 2, 2)
+			// 价格和涨跌幅都可以是数组，按元素计算
+			prev, prevSeries := mylang.AnySlice(args[0])
+			rates, rateSeries := mylang.AnySlice(args[1])
+			if !prevSeries && !rateSeries {
+				return m.precision.limitPrice(toFloatsN(args[0], 1)[0], sign*toFloatsN(args[1], 1)[0])
+			}
+			n := max(len(prev), len(rates))
+			prices, rateValues := toFloatsN(args[0], n), toFloatsN(args[1], n)
+			out := make(indicators.Series, n)
+			for i := range out {
+				out[i] = m.precision.limitPrice(prices[i], sign*rateValues[i])
+			}
+			return out
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/mylang"
+)
+
+func TestRoundToTick(t *testing.T) {
+	tests := []struct {
+		price, tick, want float64
+	}{
+		{11.055000000000001, 0.01, 11.06},
+		{10.049999999999999, 0.01, 10.05},
+		{3.1234, 0.001, 3.123},
+		{3.1235, 0.005, 3.125},
+		{102.4, 0.5, 102.5},
+		{7.77, 0, 7.77},
+	}
+	for _, tt := range tests {
+		if got := RoundToTick(tt.price, tt.tick); got != tt.want {
+			t.Errorf("RoundToTick(%v, %v) = %v, want %v", tt.price, tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestPrecisionMode(t *testing.T) {
+	m := NewMaiExecutor()
+	m.SetVar("C", []float64{10.05, 11.055000000000001, 9.949999999999999})
+
+	// 默认按 float64 计算
+	if err := m.RunCode("ZT:ZTPRICE(C,0.1);"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("ZT"); got[0] == 11.06 {
+		t.Errorf("ZTPRICE without precision = %v", got)
+	}
+
+	m.EnablePrecision(PrecisionOptions{})
+	if opt, ok := m.Precision(); !ok || opt.TickSize != 0.01 || opt.Tolerance.Mode != mylang.ToleranceAbsolute {
+		t.Errorf("Precision() = %+v, %v", opt, ok)
+	}
+	if err := m.RunCode("CC:C;ZT:ZTPRICE(C,0.1);DT:DTPRICE(C,0.1);ISZT:C=ZTPRICE(REF(C,1),0.1);Z5:ZTPRICE(10.03,0.05);"); err != nil {
+		t.Fatal(err)
+	}
+	// 执行时使用四舍五入的副本，调用方绑定的数据不变
+	if got := m.GetFloat64Array("CC"); !reflect.DeepEqual(got, []float64{10.05, 11.06, 9.95}) {
+		t.Errorf("CC = %v", got)
+	}
+	if got := m.GetFloat64Array("C"); !reflect.DeepEqual(got, []float64{10.05, 11.055000000000001, 9.949999999999999}) {
+		t.Errorf("C = %v", got)
+	}
+	if got := m.GetFloat64Array("ZT"); !reflect.DeepEqual(got, []float64{11.06, 12.17, 10.95}) {
+		t.Errorf("ZT = %v", got)
+	}
+	if got := m.GetFloat64Array("DT"); !reflect.DeepEqual(got, []float64{9.05, 9.95, 8.96}) {
+		t.Errorf("DT = %v", got)
+	}
+	if got := m.GetFloat64Array("ISZT"); !reflect.DeepEqual(got, []float64{0, 1, 0}) {
+		t.Errorf("ISZT = %v", got)
+	}
+	// 10.03*1.05 = 10.5315
+	if got, _ := m.GetVariable("Z5"); got != 10.53 {
+		t.Errorf("Z5 = %v", got)
+	}
+
+	// 涨跌幅为数组时按元素计算
+	m.SetVar("RATE", []float64{0.1, 0.2, 0.05})
+	if err := m.RunCode("ZR:ZTPRICE(C,RATE);DR:DTPRICE(10.03,RATE);"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("ZR"); !reflect.DeepEqual(got, []float64{11.06, 13.27, 10.45}) {
+		t.Errorf("ZR = %v", got)
+	}
+	if got := m.GetFloat64Array("DR"); !reflect.DeepEqual(got, []float64{9.03, 8.02, 9.53}) {
+		t.Errorf("DR = %v", got)
+	}
+
+	// 公式中重新赋值的价格变量保留公式的结果
+	if err := m.RunCode("H:C*2;"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RunCode("H:=H+1;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("H"); !reflect.DeepEqual(got, []float64{21.1, 23.12, 20.9}) {
+		t.Errorf("H = %v", got)
+	}
+
+	m.DisablePrecision()
+	if _, ok := m.Precision(); ok || m.Tolerance().Mode != mylang.ToleranceLegacy {
+		t.Errorf("DisablePrecision should restore the legacy tolerance")
+	}
+
+	// 恢复开启前单独设置的误差，重复开启时也是如此
+	custom := mylang.Tolerance{Mode: mylang.ToleranceRelative, Value: 1e-9}
+	m.SetTolerance(custom)
+	m.EnablePrecision(PrecisionOptions{})
+	m.EnablePrecision(PrecisionOptions{TickSize: 0.001})
+	m.DisablePrecision()
+	if got := m.Tolerance(); got != custom {
+		t.Errorf("Tolerance() after DisablePrecision = %+v, want %+v", got, custom)
+	}
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/mylang"
+)
+
+// Session 一个交易时段，Start、End 为距离零点的时间
+type Session struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// AShareSessions A 股的交易时段
+var AShareSessions = []Session{
+	{Start: 9*time.Hour + 30*time.Minute, End: 11*time.Hour + 30*time.Minute},
+	{Start: 13 * time.Hour, End: 15 * time.Hour},
+}
+
+// ResampleOptions 周期合成的设置
+type ResampleOptions struct {
+	Sessions    []Session      // 交易时段，按时间顺序，nil 为 AShareSessions
+	Location    *time.Location // 按该时区计算日期和时段，nil 为 K 线时间自带的时区
+	DropPartial bool           // 丢弃最后一根不完整的 K 线
+}
+
+// ResampleBars 把按时间排序的 K 线合成为 period 周期，partial 表示最后一根 K 线的周期还没有结束。
+//
+// 把 1 分钟线合成为 5 分钟、60 分钟线，把日线合成为周线、月线：
+// 开盘取第一根，最高、最低取最大、最小，收盘取最后一根，成交量、成交额求和，NaN 不参与计算
+//
+//	分钟周期  按交易时段划分，A 股 60 分钟线为 10:30、11:30、14:00、15:00，时间取周期结束的时间；
+//	         9:30、13:00 的集合竞价并入第一根，交易时段以外的 K 线按自然时间划分
+//	Nd       每 N 个交易日
+//	Nw NM    自然周（周一开始）、自然月，3M 为季线，12M 为年线
+//
+// 日线及以上的时间取周期内最后一根 K 线的时间。数据结束时还没有走完的周期为不完整的 K 线，
+// Nd 的最后一个周期不足 N 个交易日时也是不完整的。周期的写法见 ParsePeriod
+func ResampleBars(bars []Bar, period string, opt ResampleOptions) (out []Bar, partial bool, err error) {
+	p, err := ParsePeriod(period)
+	if err != nil {
+		return nil, false, fmt.Errorf("resample: %w", err)
+	}
+	n, unit := p.N, p.Unit
+	if opt.Sessions == nil {
+		opt.Sessions = AShareSessions
+	}
+	local := func(t time.Time) time.Time {
+		if opt.Location != nil {
+			return t.In(opt.Location)
+		}
+		return t
+	}
+
+	var (
+		keys    []int64
+		ends    []time.Time // 每个周期结束的时间，用于判断最后一根是否完整
+		lastDay = -1
+		days    = -1
+	)
+	for _, b := range bars {
+		t := local(b.Time)
+		var key int64
+		var end time.Time
+		switch unit {
+		case PeriodMinute:
+			end = sessionBucketEnd(t, time.Duration(n)*time.Minute, opt.Sessions)
+			key = end.Unix()
+		case PeriodDay:
+			if d := dayKey(t, t.Location()); d != lastDay {
+				lastDay = d
+				days++
+			}
+			key = int64(days / n)
+			end = dayEnd(t, opt.Sessions)
+		case PeriodWeek:
+			// 按日历日期计算，不受夏令时影响；1970-01-05 是星期一，为第 4 天
+			week := floorDiv(epochDays(t)-4, 7)
+			key = floorDiv(week, int64(n))
+			first := time.Date(1970, 1, 5+int(key*int64(n)*7), 0, 0, 0, 0, t.Location())
+			end = dayEnd(first.AddDate(0, 0, (n-1)*7+4), opt.Sessions) // 最后一周的星期五
+		case PeriodMonth:
+			month := int64(t.Year())*12 + int64(t.Month()) - 1
+			key = floorDiv(month, int64(n))
+			next := time.Date(int(key*int64(n)/12), time.Month(key*int64(n)%12+1), 1, 0, 0, 0, 0, t.Location()).AddDate(0, n, 0)
+			end = dayEnd(lastWeekday(next.AddDate(0, 0, -1)), opt.Sessions)
+		}
+
+		if len(keys) == 0 || keys[len(keys)-1] != key {
+			keys = append(keys, key)
+			ends = append(ends, end)
+			nb := Bar{Time: b.Time, Open: math.NaN(), High: math.NaN(), Low: math.NaN(), Close: math.NaN()}
+			if unit == PeriodMinute {
+				nb.Time = end
+			}
+			out = append(out, nb)
+		}
+		mergeBar(&out[len(out)-1], b, unit != PeriodMinute)
+	}
+	if len(out) > 0 {
+		partial = !periodEnded(local(bars[len(bars)-1].Time), ends[len(ends)-1], unit)
+		// Nd 的最后一个周期不足 N 个交易日
+		if unit == PeriodDay && days%n+1 < n {
+			partial = true
+		}
+		if partial && opt.DropPartial {
+			out, partial = out[:len(out)-1], false
+		}
+	}
+	return out, partial, nil
+}
+
+// periodEnded 最后一根 K 线的时间 last 是否已经到了周期结束的时间 end。
+// 日线及以上按日期比较，last 没有时分秒（日线数据）时当天算作已经收盘
+func periodEnded(last, end time.Time, unit PeriodUnit) bool {
+	if unit == PeriodMinute {
+		return !last.Before(end)
+	}
+	lastDay, endDay := dayKey(last, last.Location()), dayKey(end, last.Location())
+	if lastDay != endDay {
+		return lastDay > endDay
+	}
+	y, mon, d := last.Date()
+	return last.Equal(time.Date(y, mon, d, 0, 0, 0, 0, last.Location())) || !last.Before(end)
+}
+
+// mergeBar 把 b 合并到 dst，keepTime 为 true 时时间取最后一根
+func mergeBar(dst *Bar, b Bar, keepTime bool) {
+	if keepTime {
+		dst.Time = b.Time
+	}
+	if math.IsNaN(dst.Open) {
+		dst.Open = b.Open
+	}
+	if !math.IsNaN(b.High) && (math.IsNaN(dst.High) || b.High > dst.High) {
+		dst.High = b.High
+	}
+	if !math.IsNaN(b.Low) && (math.IsNaN(dst.Low) || b.Low < dst.Low) {
+		dst.Low = b.Low
+	}
+	if !math.IsNaN(b.Close) {
+		dst.Close = b.Close
+	}
+	if !math.IsNaN(b.Volume) {
+		dst.Volume += b.Volume
+	}
+	if !math.IsNaN(b.Amount) {
+		dst.Amount += b.Amount
+	}
+}
+
+// sessionBucketEnd 分钟周期中 t 所在周期结束的时间
+func sessionBucketEnd(t time.Time, period time.Duration, sessions []Session) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	tod := t.Sub(midnight)
+	ceil := func(d time.Duration) time.Duration {
+		k := (d + period - 1) / period
+		if k == 0 {
+			k = 1
+		}
+		return k * period
+	}
+	for _, s := range sessions {
+		if tod >= s.Start && tod <= s.End {
+			end := s.Start + ceil(tod-s.Start)
+			if end > s.End {
+				end = s.End
+			}
+			return midnight.Add(end)
+		}
+	}
+	return midnight.Add(ceil(tod))
+}
+
+// dayEnd 当天收盘的时间
+func dayEnd(t time.Time, sessions []Session) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if len(sessions) == 0 {
+		return midnight.Add(24*time.Hour - time.Nanosecond)
+	}
+	return midnight.Add(sessions[len(sessions)-1].End)
+}
+
+// lastWeekday 不晚于 t 的最后一个工作日
+func lastWeekday(t time.Time) time.Time {
+	for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// epochDays t 的日期距离 1970-01-01 的天数
+func epochDays(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// Bars 返回当前绑定的 K 线：OPEN、HIGH、LOW、CLOSE、VOLUME、AMOUNT（或 O、H、L、C、V、VOL）和日期时间数组，
+// 缺少的序列为 NaN，时间无法解析的 K 线被跳过
+func (m *MaiExecutor) Bars() ([]Bar, error) {
+	bt := m.getBarTimes()
+	if bt == nil {
+		return nil, fmt.Errorf("resample: no datetime array")
+	}
+	n := len(bt.times)
+	series := func(names ...string) []float64 {
+		for _, name := range names {
+			if v, ok := m.MylangInterpreter.GetVariable(name); ok {
+				if _, ok := mylang.AnySlice(v); ok {
+					return toFloatsN(v, n)
+				}
+			}
+		}
+		return toFloatsN(math.NaN(), n)
+	}
+	open, high, low, cls := series("OPEN", "O"), series("HIGH", "H"), series("LOW", "L"), series("CLOSE", "C")
+	vol, amount := series("VOLUME", "V", "VOL"), series("AMOUNT")
+	bars := make([]Bar, 0, n)
+	for i, t := range bt.times {
+		if !bt.valid[i] {
+			continue
+		}
+		bars = append(bars, Bar{Time: t, Open: open[i], High: high[i], Low: low[i], Close: cls[i], Volume: vol[i], Amount: amount[i]})
+	}
+	return bars, nil
+}
+
+// Resample 把当前绑定的 K 线合成为 period 周期并重新绑定，见 ResampleBars。
+// SetBars 绑定的数据按当前的复权方式先复权再合成，合成后复权方式为 AdjustNone，需要切换时重新 SetBars
+func (m *MaiExecutor) Resample(period string, opt ResampleOptions) (partial bool, err error) {
+	bars, err := m.Bars()
+	if err != nil {
+		return false, err
+	}
+	if opt.Location == nil {
+		opt.Location = m.location()
+	}
+	out, partial, err := ResampleBars(bars, period, opt)
+	if err != nil {
+		return false, err
+	}
+	m.SetBars(out, nil, AdjustNone)
+	return partial, nil
+}
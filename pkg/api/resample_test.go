@@ -0,0 +1,174 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// minuteBars 生成 A 股一天的 1 分钟线（9:31~11:30、13:01~15:00），收盘价为序号
+func minuteBars(day time.Time) []Bar {
+	var bars []Bar
+	add := func(from, to time.Duration) {
+		for d := from; d <= to; d += time.Minute {
+			i := float64(len(bars))
+			bars = append(bars, Bar{Time: day.Add(d), Open: i, High: i + 0.5, Low: i - 0.5, Close: i, Volume: 1, Amount: 10})
+		}
+	}
+	add(9*time.Hour+31*time.Minute, 11*time.Hour+30*time.Minute)
+	add(13*time.Hour+1*time.Minute, 15*time.Hour)
+	return bars
+}
+
+func TestResampleMinutes(t *testing.T) {
+	day := time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)
+	bars := minuteBars(day)
+
+	out, partial, err := ResampleBars(bars, "1h", ResampleOptions{})
+	if err != nil || partial {
+		t.Fatalf("ResampleBars() partial = %v, err = %v", partial, err)
+	}
+	var times []string
+	for _, b := range out {
+		times = append(times, b.Time.Format("15:04"))
+	}
+	if !reflect.DeepEqual(times, []string{"10:30", "11:30", "14:00", "15:00"}) {
+		t.Errorf("60m times = %v", times)
+	}
+	if b := out[0]; b.Open != 0 || b.Close != 59 || b.High != 59.5 || b.Low != -0.5 || b.Volume != 60 || b.Amount != 600 {
+		t.Errorf("first 60m bar = %+v", b)
+	}
+
+	// 9:30 的集合竞价并入 9:35
+	auction := append([]Bar{{Time: day.Add(9*time.Hour + 30*time.Minute), Open: -1, High: -1, Low: -1, Close: -1, Volume: 5}}, bars[:7]...)
+	out, partial, err = ResampleBars(auction, "5m", ResampleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out[0].Time.Format("15:04") != "09:35" || out[0].Open != -1 || out[0].Volume != 10 || !partial {
+		t.Errorf("5m = %+v, partial = %v", out, partial)
+	}
+	out, partial, _ = ResampleBars(auction, "5m", ResampleOptions{DropPartial: true})
+	if len(out) != 1 || partial {
+		t.Errorf("DropPartial = %+v, %v", out, partial)
+	}
+
+	// 交易时段以外按自然时间
+	night := []Bar{{Time: day.Add(21*time.Hour + 1*time.Minute), Close: 1}, {Time: day.Add(21*time.Hour + 16*time.Minute), Close: 2}}
+	out, _, _ = ResampleBars(night, "15m", ResampleOptions{})
+	if len(out) != 2 || out[0].Time.Format("15:04") != "21:15" || out[1].Time.Format("15:04") != "21:30" {
+		t.Errorf("night 15m = %+v", out)
+	}
+
+	if _, _, err := ResampleBars(bars, "0m", ResampleOptions{}); err == nil {
+		t.Errorf("invalid period should fail")
+	}
+	if _, _, err := ResampleBars(bars, "5x", ResampleOptions{}); err == nil {
+		t.Errorf("invalid period should fail")
+	}
+}
+
+func TestResampleDays(t *testing.T) {
+	var bars []Bar
+	// 2024-04-01（周一）到 2024-05-10 的工作日
+	for d := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC); d.Before(time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC)); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		i := float64(len(bars))
+		bars = append(bars, Bar{Time: d, Open: i, High: i + 1, Low: i - 1, Close: i + 0.5, Volume: 100})
+	}
+
+	week, partial, err := ResampleBars(bars, "1w", ResampleOptions{})
+	if err != nil || partial {
+		t.Fatalf("1w partial = %v, err = %v", partial, err)
+	}
+	if len(week) != 6 || week[0].Time.Format("2006-01-02") != "2024-04-05" || week[0].Open != 0 || week[0].Close != 4.5 || week[0].Volume != 500 {
+		t.Errorf("1w = %+v", week[0])
+	}
+
+	month, partial, _ := ResampleBars(bars, "1M", ResampleOptions{})
+	if len(month) != 2 || month[0].Time.Format("2006-01-02") != "2024-04-30" || month[0].High != 22 || !partial {
+		t.Errorf("1M = %+v, partial = %v", month, partial)
+	}
+	if month[1].Volume != 800 {
+		t.Errorf("May volume = %v", month[1].Volume)
+	}
+
+	two, _, _ := ResampleBars(bars, "2d", ResampleOptions{})
+	if len(two) != 15 || two[1].Open != 2 || two[1].Close != 3.5 {
+		t.Errorf("2d = %+v", two[:2])
+	}
+
+	// 最后一个周期只有 1 个交易日
+	two, partial, _ = ResampleBars(bars[:3], "2d", ResampleOptions{})
+	if len(two) != 2 || !partial || two[1].Open != 2 {
+		t.Errorf("2d of 3 days = %+v, partial = %v", two, partial)
+	}
+	two, partial, _ = ResampleBars(bars[:3], "2d", ResampleOptions{DropPartial: true})
+	if len(two) != 1 || partial || two[0].Close != 1.5 {
+		t.Errorf("2d of 3 days with DropPartial = %+v, partial = %v", two, partial)
+	}
+
+	// 盘中的日线不完整
+	intraday := minuteBars(time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC))[:100]
+	daily, partial, _ := ResampleBars(intraday, "1d", ResampleOptions{})
+	if len(daily) != 1 || !partial || daily[0].Volume != 100 {
+		t.Errorf("intraday 1d = %+v, partial = %v", daily, partial)
+	}
+}
+
+func TestResampleWeeksDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 2024-03-10（周日）开始夏令时，3 月 11 日的零点距离 1970-01-05 不是整数周
+	var bars []Bar
+	for i, day := range []int{7, 8, 11, 12, 15} {
+		bars = append(bars, Bar{Time: time.Date(2024, 3, day, 0, 0, 0, 0, loc), Open: float64(i), Close: float64(i), Volume: 1})
+	}
+	week, partial, err := ResampleBars(bars, "1w", ResampleOptions{})
+	if err != nil || partial {
+		t.Fatalf("1w partial = %v, err = %v", partial, err)
+	}
+	if len(week) != 2 || week[0].Time.Day() != 8 || week[0].Volume != 2 || week[1].Open != 2 || week[1].Volume != 3 {
+		t.Errorf("1w = %+v", week)
+	}
+}
+
+func TestMaiExecutorResample(t *testing.T) {
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetBars(minuteBars(time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)), nil, AdjustNone)
+	partial, err := m.Resample("30m", ResampleOptions{})
+	if err != nil || partial {
+		t.Fatalf("Resample() partial = %v, err = %v", partial, err)
+	}
+	if err := m.RunCode("N:TOTALBARSCOUNT;T:TIME;"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("T"); len(got) != 8 || got[0] != 100000 || got[7] != 150000 {
+		t.Errorf("TIME = %v", got)
+	}
+	if got := m.GetFloat64Array("V"); got[0] != 30 {
+		t.Errorf("V = %v", got)
+	}
+
+	m = NewMaiExecutor()
+	m.SetVar("C", []float64{1, 2})
+	if _, err := m.Resample("1d", ResampleOptions{}); err == nil {
+		t.Errorf("Resample without datetime should fail")
+	}
+	m.SetVar("dateTime", []any{"2024-04-08", "2024-04-09"})
+	if _, err := m.Resample("1w", ResampleOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetFloat64Array("C"); !reflect.DeepEqual(got, []float64{2}) {
+		t.Errorf("C = %v", got)
+	}
+	if got := m.GetFloat64Array("O"); len(got) != 1 || !math.IsNaN(got[0]) {
+		t.Errorf("O = %v", got)
+	}
+}
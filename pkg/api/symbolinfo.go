@@ -0,0 +1,450 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	"github.com/spf13/cast"
+)
+
+// 股票信息函数，选股公式中按名称、代码、板块过滤股票
+//
+//	NAMELIKE('S')     名称以 S 开头，参数带 * 或 ? 时按通配符匹配整个名称，如 NAMELIKE('*ST*')
+//	CODELIKE('60')    代码以 60 开头，同样支持通配符
+//	INBLOCK('科创板')  属于该板块，板块包括 SymbolInfo.Blocks 和所属的市场板块（主板、创业板、科创板、北交所）
+//	SETCODE           市场，0 深圳、1 上海、2 北京
+//	ISST              是否 ST、*ST
+//	LISTDAYS          上市天数（自然日，上市当天为 1），按每根 K 线的日期计算，没有日期时间数组时按今天计算
+//
+// 匹配不区分大小写。股票由 SetSymbol 指定，信息由 SetSymbolInfoProvider 提供，没有提供时按代码推断市场和板块
+var symbolInfoVarNames = map[string]bool{"SETCODE": true, "ISST": true, "LISTDAYS": true}
+
+// boardNames 板块的中文名称，用于 INBLOCK 和读取配置
+var boardNames = map[Board][]string{
+	BoardMain:    {"主板"},
+	BoardChiNext: {"创业板"},
+	BoardSTAR:    {"科创板"},
+	BoardBSE:     {"北交所", "北证"},
+}
+
+// ParseBoard 解析板块名称，支持 Board.String() 的写法和中文名称
+func ParseBoard(s string) (Board, bool) {
+	s = strings.TrimSpace(s)
+	for b := BoardMain; b <= BoardBSE; b++ {
+		if strings.EqualFold(s, b.String()) {
+			return b, true
+		}
+		for _, name := range boardNames[b] {
+			if s == name {
+				return b, true
+			}
+		}
+	}
+	return BoardMain, false
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，JSON 中的板块写作字符串
+func (b *Board) UnmarshalText(text []byte) error {
+	v, ok := ParseBoard(string(text))
+	if !ok {
+		return fmt.Errorf("unknown board %q", text)
+	}
+	*b = v
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (b Board) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// 市场代码，与通达信的 SETCODE 相同
+const (
+	MarketSZ = "SZ"
+	MarketSH = "SH"
+	MarketBJ = "BJ"
+)
+
+// InferMarket 按股票代码推断市场，代码可以带交易所前缀或后缀
+func InferMarket(code string) string {
+	upper := strings.ToUpper(code)
+	for _, market := range []string{MarketSH, MarketSZ, MarketBJ} {
+		if strings.HasPrefix(upper, market) || strings.HasSuffix(upper, market) {
+			return market
+		}
+	}
+	digits := codeDigits(code)
+	switch {
+	case InferBoard(digits) == BoardBSE:
+		return MarketBJ
+	case strings.HasPrefix(digits, "6"), strings.HasPrefix(digits, "9"):
+		return MarketSH
+	}
+	return MarketSZ
+}
+
+// codeDigits 去掉代码中的交易所前缀、后缀，只保留数字
+func codeDigits(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, code)
+}
+
+// SymbolInfo 股票信息
+type SymbolInfo struct {
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Market   string    `json:"market"` // SH、SZ、BJ，为空时按代码推断
+	Board    Board     `json:"board"`
+	Blocks   []string  `json:"blocks"` // 所属的行业、概念、指数板块
+	ListDate time.Time `json:"list_date"`
+	ST       bool      `json:"st"` // 为 false 时名称以 ST、*ST 开头也算
+}
+
+// IsST 是否 ST、*ST
+func (s SymbolInfo) IsST() bool {
+	name := strings.ToUpper(strings.TrimSpace(s.Name))
+	return s.ST || strings.HasPrefix(name, "ST") || strings.HasPrefix(name, "*ST")
+}
+
+// SetCode 通达信的市场代码：0 深圳、1 上海、2 北京
+func (s SymbolInfo) SetCode() float64 {
+	market := s.Market
+	if market == "" {
+		market = InferMarket(s.Code)
+	}
+	switch strings.ToUpper(market) {
+	case MarketSH:
+		return 1
+	case MarketBJ:
+		return 2
+	}
+	return 0
+}
+
+// InBlock 是否属于板块，block 可以带通配符
+func (s SymbolInfo) InBlock(block string) bool {
+	names := append([]string{}, s.Blocks...)
+	names = append(names, boardNames[s.Board]...)
+	for _, name := range names {
+		if wildcardMatch(block, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta 涨跌停使用的股票信息
+func (s SymbolInfo) Meta() SymbolMeta {
+	return SymbolMeta{Code: s.Code, Board: s.Board, ST: s.IsST(), ListDate: s.ListDate}
+}
+
+// SymbolInfoProvider 按股票代码提供 SymbolInfo
+type SymbolInfoProvider interface {
+	SymbolInfo(code string) (SymbolInfo, bool)
+}
+
+// LocalSymbolInfoProvider 从本地 JSON、CSV 文件加载的股票信息，代码不区分交易所写在前缀还是后缀，
+// 没有写交易所时按代码推断，如 000001 为深圳的平安银行，上证指数需要写作 000001.SH
+type LocalSymbolInfoProvider struct {
+	infos map[string]SymbolInfo
+}
+
+// NewLocalSymbolInfoProvider 创建一个空的本地股票信息
+func NewLocalSymbolInfoProvider(infos ...SymbolInfo) *LocalSymbolInfoProvider {
+	p := &LocalSymbolInfoProvider{infos: make(map[string]SymbolInfo)}
+	for _, info := range infos {
+		p.Add(info)
+	}
+	return p
+}
+
+// Add 添加或替换一个股票的信息
+func (p *LocalSymbolInfoProvider) Add(info SymbolInfo) {
+	key := codeKey(info.Code)
+	if info.Market != "" {
+		key = marketCodeKey(info.Market, info.Code)
+	}
+	p.infos[key] = info
+}
+
+// SymbolInfo 实现 SymbolInfoProvider
+func (p *LocalSymbolInfoProvider) SymbolInfo(code string) (SymbolInfo, bool) {
+	info, ok := p.infos[codeKey(code)]
+	return info, ok
+}
+
+// Codes 返回所有股票代码，按代码排序
+func (p *LocalSymbolInfoProvider) Codes() []string {
+	codes := make([]string, 0, len(p.infos))
+	for _, info := range p.infos {
+		codes = append(codes, info.Code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// codeKey 查找股票信息使用的代码，交易所加数字，如 SZ000001，不同交易所的相同数字不会混在一起
+func codeKey(code string) string {
+	return marketCodeKey(InferMarket(code), code)
+}
+
+// marketCodeKey 按给定的交易所生成 codeKey
+func marketCodeKey(market, code string) string {
+	if digits := codeDigits(code); digits != "" {
+		return strings.ToUpper(market) + digits
+	}
+	return strings.ToUpper(code)
+}
+
+// symbolInfoRecord JSON、CSV 中的一行，日期和布尔值的写法比较宽松
+type symbolInfoRecord struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Market   string   `json:"market"`
+	Board    string   `json:"board"`
+	Blocks   []string `json:"blocks"`
+	ListDate any      `json:"list_date"`
+	ST       any      `json:"st"`
+}
+
+func (r symbolInfoRecord) toInfo() (SymbolInfo, error) {
+	if r.Code == "" {
+		return SymbolInfo{}, fmt.Errorf("symbol info: missing code")
+	}
+	info := SymbolInfo{Code: r.Code, Name: r.Name, Market: strings.ToUpper(r.Market), Blocks: r.Blocks}
+	if r.Board == "" {
+		info.Board = InferBoard(r.Code)
+	} else if err := info.Board.UnmarshalText([]byte(r.Board)); err != nil {
+		return SymbolInfo{}, fmt.Errorf("symbol info %s: %w", r.Code, err)
+	}
+	if r.ListDate != nil && r.ListDate != "" {
+		t, ok := ParseDateTime(r.ListDate, time.Local)
+		if !ok {
+			return SymbolInfo{}, fmt.Errorf("symbol info %s: invalid list_date %v", r.Code, r.ListDate)
+		}
+		info.ListDate = t
+	}
+	switch st := r.ST.(type) {
+	case nil:
+	case string:
+		info.ST = st == "是" || cast.ToBool(st)
+	default:
+		info.ST = cast.ToBool(st)
+	}
+	return info, nil
+}
+
+// LoadSymbolInfoJSON 从 JSON 数组加载股票信息：
+//
+//	[{"code":"688981.SH","name":"中芯国际","board":"科创板","blocks":["半导体"],"list_date":"2020-07-16","st":false}]
+func (p *LocalSymbolInfoProvider) LoadSymbolInfoJSON(r io.Reader) error {
+	var records []symbolInfoRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return fmt.Errorf("symbol info: %w", err)
+	}
+	for _, record := range records {
+		info, err := record.toInfo()
+		if err != nil {
+			return err
+		}
+		p.Add(info)
+	}
+	return nil
+}
+
+// LoadSymbolInfoCSV 从带表头的 CSV 加载股票信息，列名同 JSON，顺序任意，多个板块用 ; 或 | 分隔：
+//
+//	code,name,market,board,blocks,list_date,st
+//	600000,浦发银行,SH,主板,银行;上证50,1999-11-10,0
+func (p *LocalSymbolInfoProvider) LoadSymbolInfoCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("symbol info: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["code"]; !ok {
+		return fmt.Errorf("symbol info: csv header has no code column")
+	}
+	for _, row := range rows[1:] {
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		record := symbolInfoRecord{
+			Code:     field("code"),
+			Name:     field("name"),
+			Market:   field("market"),
+			Board:    field("board"),
+			ListDate: field("list_date"),
+			ST:       field("st"),
+		}
+		if record.Code == "" {
+			continue
+		}
+		for _, block := range strings.FieldsFunc(field("blocks"), func(r rune) bool { return r == ';' || r == '|' }) {
+			record.Blocks = append(record.Blocks, strings.TrimSpace(block))
+		}
+		info, err := record.toInfo()
+		if err != nil {
+			return err
+		}
+		p.Add(info)
+	}
+	return nil
+}
+
+// ReadSymbolInfoFile 按扩展名读取 .json 或 .csv 文件
+func ReadSymbolInfoFile(path string) (*LocalSymbolInfoProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p := NewLocalSymbolInfoProvider()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = p.LoadSymbolInfoJSON(f)
+	case ".csv":
+		err = p.LoadSymbolInfoCSV(f)
+	default:
+		err = fmt.Errorf("symbol info: unsupported file %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetSymbolInfoProvider 设置股票信息的来源，涨跌停在 SymbolMetaProvider 中找不到时也使用这里的信息
+func (m *MaiExecutor) SetSymbolInfoProvider(p SymbolInfoProvider) {
+	m.infoProvider = p
+}
+
+// SymbolInfo 返回当前股票的信息，provider 中没有时按代码推断市场和板块
+func (m *MaiExecutor) SymbolInfo() SymbolInfo {
+	if m.infoProvider != nil {
+		if info, ok := m.infoProvider.SymbolInfo(m.symbol); ok {
+			return info
+		}
+	}
+	return SymbolInfo{Code: m.symbol, Market: InferMarket(m.symbol), Board: InferBoard(m.symbol)}
+}
+
+// wildcardMatch 不区分大小写的通配符匹配，* 匹配任意个字符，? 匹配一个字符
+func wildcardMatch(pattern, s string) bool {
+	p := []rune(strings.ToUpper(pattern))
+	r := []rune(strings.ToUpper(s))
+	pi, ri := 0, 0
+	star, mark := -1, 0
+	for ri < len(r) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == r[ri]):
+			pi++
+			ri++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ri
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			ri = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// likeMatch NAMELIKE、CODELIKE 的匹配：带通配符时匹配整个字符串，否则按前缀匹配
+func likeMatch(pattern, s string) bool {
+	if strings.ContainsAny(pattern, "*?") {
+		return wildcardMatch(pattern, s)
+	}
+	return strings.HasPrefix(strings.ToUpper(s), strings.ToUpper(pattern))
+}
+
+// boolFloat 条件成立为 1，否则为 0，与通达信一致，可以参与四则运算
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// symbolInfoVariable 计算 SETCODE、ISST、LISTDAYS
+func (m *MaiExecutor) symbolInfoVariable(name string) any {
+	info := m.SymbolInfo()
+	switch name {
+	case "SETCODE":
+		return info.SetCode()
+	case "ISST":
+		return boolFloat(info.IsST())
+	}
+	if info.ListDate.IsZero() {
+		return math.NaN()
+	}
+	loc := m.location()
+	y, mon, d := info.ListDate.In(loc).Date()
+	listDay := time.Date(y, mon, d, 0, 0, 0, 0, time.UTC)
+	days := func(t time.Time) float64 {
+		y, mon, d := t.In(loc).Date()
+		return math.Floor(time.Date(y, mon, d, 0, 0, 0, 0, time.UTC).Sub(listDay).Hours()/24) + 1
+	}
+	bt := m.getBarTimes()
+	if bt == nil {
+		return days(m.today())
+	}
+	result := make(indicators.Series, len(bt.times))
+	for i, t := range bt.times {
+		if !bt.valid[i] {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = days(t)
+	}
+	return result
+}
+
+// registerSymbolInfoFuncs 注册 NAMELIKE、CODELIKE、INBLOCK
+func (m *MaiExecutor) registerSymbolInfoFuncs() {
+	m.RegisterFunction("NAMELIKE", func(args []interface{}) interface{} {
+		checkArgCount("NAMELIKE", args, 1, 1)
+		return boolFloat(likeMatch(cast.ToString(args[0]), m.SymbolInfo().Name))
+	})
+	m.RegisterFunction("CODELIKE", func(args []interface{}) interface{} {
+		checkArgCount("CODELIKE", args, 1, 1)
+		info := m.SymbolInfo()
+		pattern := cast.ToString(args[0])
+		return boolFloat(likeMatch(pattern, info.Code) || likeMatch(pattern, codeDigits(info.Code)))
+	})
+	m.RegisterFunction("INBLOCK", func(args []interface{}) interface{} {
+		checkArgCount("INBLOCK", args, 1, 1)
+		return boolFloat(m.SymbolInfo().InBlock(cast.ToString(args[0])))
+	})
+}
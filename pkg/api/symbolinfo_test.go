@@ -0,0 +1,176 @@
+package api
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSymbolInfoCSV = `code,name,board,blocks,list_date,st
+600000.SH,浦发银行,主板,银行;上证50,1999-11-10,0
+300001,*ST特锐,,电力设备|创业板指,2009-10-30,
+688981,中芯国际,star,半导体,2020-07-16,false
+`
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*ST*", "*ST特锐", true},
+		{"*st*", "浦发银行", false},
+		{"60????", "600000", true},
+		{"60????", "6000001", false},
+		{"*", "", true},
+		{"半导*", "半导体", true},
+		{"a*b*c", "aXbYbZc", true},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestInferMarket(t *testing.T) {
+	for code, want := range map[string]string{
+		"600000": MarketSH, "000001": MarketSZ, "300750": MarketSZ, "688981": MarketSH,
+		"430047": MarketBJ, "920002": MarketBJ, "sh510300": MarketSH, "159915.SZ": MarketSZ,
+	} {
+		if got := InferMarket(code); got != want {
+			t.Errorf("InferMarket(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestLoadSymbolInfo(t *testing.T) {
+	p := NewLocalSymbolInfoProvider()
+	if err := p.LoadSymbolInfoCSV(strings.NewReader(testSymbolInfoCSV)); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Codes(); !reflect.DeepEqual(got, []string{"300001", "600000.SH", "688981"}) {
+		t.Errorf("Codes() = %v", got)
+	}
+	info, ok := p.SymbolInfo("SH600000")
+	if !ok || info.Name != "浦发银行" || !reflect.DeepEqual(info.Blocks, []string{"银行", "上证50"}) || info.IsST() || info.ListDate.Year() != 1999 {
+		t.Errorf("SymbolInfo(SH600000) = %+v, %v", info, ok)
+	}
+	if info, _ := p.SymbolInfo("300001.SZ"); info.Board != BoardChiNext || !info.IsST() {
+		t.Errorf("SymbolInfo(300001.SZ) = %+v", info)
+	}
+
+	json := `[{"code":"430047.BJ","name":"诺思兰德","board":"北交所","blocks":["北证50"],"list_date":"20201231","st":"是"}]`
+	if err := p.LoadSymbolInfoJSON(strings.NewReader(json)); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := p.SymbolInfo("430047"); info.Board != BoardBSE || !info.ST || info.SetCode() != 2 {
+		t.Errorf("SymbolInfo(430047) = %+v", info)
+	}
+
+	// 相同数字、不同交易所的代码分开保存
+	p.Add(SymbolInfo{Code: "000001.SZ", Name: "平安银行"})
+	p.Add(SymbolInfo{Code: "000001", Market: MarketSH, Name: "上证指数"})
+	for code, want := range map[string]string{"000001": "平安银行", "SZ000001": "平安银行", "000001.SH": "上证指数"} {
+		if info, _ := p.SymbolInfo(code); info.Name != want {
+			t.Errorf("SymbolInfo(%s) = %+v, want %s", code, info, want)
+		}
+	}
+
+	for _, bad := range []string{`[{"code":"1","board":"foo"}]`, `[{"code":"1","list_date":"x"}]`, `[{"name":"x"}]`, `{`} {
+		if err := p.LoadSymbolInfoJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadSymbolInfoJSON(%s) should fail", bad)
+		}
+	}
+	if err := p.LoadSymbolInfoCSV(strings.NewReader("name\nx\n")); err == nil {
+		t.Errorf("csv without code column should fail")
+	}
+
+	path := filepath.Join(t.TempDir(), "symbols.csv")
+	if err := os.WriteFile(path, []byte(testSymbolInfoCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ReadSymbolInfoFile(path); err != nil || len(p.Codes()) != 3 {
+		t.Errorf("ReadSymbolInfoFile() = %v, %v", p, err)
+	}
+	if _, err := ReadSymbolInfoFile(filepath.Join(t.TempDir(), "symbols.txt")); err == nil {
+		t.Errorf("unsupported file should fail")
+	}
+}
+
+func TestSymbolInfoFunctions(t *testing.T) {
+	p := NewLocalSymbolInfoProvider()
+	if err := p.LoadSymbolInfoCSV(strings.NewReader(testSymbolInfoCSV)); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMaiExecutor()
+	m.SetLocation(time.UTC)
+	m.SetSymbolInfoProvider(p)
+	m.SetVar("dateTime", []any{"2020-07-16", "2020-07-17", "2020-08-15"})
+	m.SetVar("C", []float64{27.46, 32.95, 80})
+
+	code := `A:NAMELIKE('中芯');B:NAMELIKE('*ST*');D:CODELIKE('688');E:CODELIKE('60*');
+F:INBLOCK('科创板');G:INBLOCK('半导*');H:SETCODE;I:ISST;J:LISTDAYS;
+去除:=IF(NAMELIKE('S'),0,1) AND IF(INBLOCK('科创板'),0,1);`
+	m.SetSymbol("688981.SH")
+	if err := m.RunCode(code); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]any{
+		"A": 1.0, "B": 0.0, "D": 1.0, "E": 0.0, "F": 1.0, "G": 1.0, "H": 1.0, "I": 0.0,
+	} {
+		if got, _ := m.GetVariable(name); got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if got := m.GetFloat64Array("J"); !reflect.DeepEqual(got, []float64{1, 2, 31}) {
+		t.Errorf("LISTDAYS = %v", got)
+	}
+	// 科创板股票被去除
+	if got := m.GetFloat64Array("去除"); len(got) == 0 || got[0] != 0 {
+		t.Errorf("去除 = %v", got)
+	}
+	// 上市前 5 个交易日不设涨跌幅，信息来自 SymbolInfo
+	if meta := m.SymbolMeta(); meta.Board != BoardSTAR || meta.noLimitDays() != 5 {
+		t.Errorf("SymbolMeta() = %+v", meta)
+	}
+
+	m.SetSymbol("300001")
+	if err := m.RunCode("B:NAMELIKE('*ST*');E:CODELIKE('30');H:SETCODE;I:ISST;K:ISST*2;L:NAMELIKE('*ST*')+INBLOCK('创业板');"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]any{"B": 1.0, "E": 1.0, "H": 0.0, "I": 1.0, "K": 2.0, "L": 2.0} {
+		if got, _ := m.GetVariable(name); got != want {
+			t.Errorf("300001 %s = %v, want %v", name, got, want)
+		}
+	}
+
+	// 没有信息时按代码推断
+	m.SetSymbol("920002")
+	if err := m.RunCode("H:SETCODE;F:INBLOCK('北交所');J:LISTDAYS;"); err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := m.GetVariable("H"); h != 2.0 {
+		t.Errorf("SETCODE = %v", h)
+	}
+	if f, _ := m.GetVariable("F"); f != 1.0 {
+		t.Errorf("INBLOCK = %v", f)
+	}
+	if j, _ := m.GetVariable("J"); !math.IsNaN(j.(float64)) {
+		t.Errorf("LISTDAYS = %v", j)
+	}
+}
+
+func TestIsST(t *testing.T) {
+	for name, want := range map[string]bool{"ST康美": true, " *ST特锐": true, "st天龙": true, "BEST科技": false, "中芯国际": false} {
+		if got := (SymbolInfo{Name: name}).IsST(); got != want {
+			t.Errorf("IsST(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !(SymbolInfo{Name: "中芯国际", ST: true}).IsST() {
+		t.Errorf("explicit ST should be ST")
+	}
+}
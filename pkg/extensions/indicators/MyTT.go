@@ -51,6 +51,28 @@ func (s Series) Slice(start, end int) Series {
 	return s[start:end]
 }
 
+// compareSeries 逐元素比较，任一边为 NaN 或超出范围时为 false，长度为 1 的 S2 按标量广播
+func compareSeries(S1, S2 Series, cmp func(a, b float64) bool) []bool {
+	result := make([]bool, len(S1))
+	for i := range S1 {
+		b := valueAt(S2, i)
+		if math.IsNaN(S1[i]) || math.IsNaN(b) {
+			continue
+		}
+		result[i] = cmp(S1[i], b)
+	}
+	return result
+}
+
+// andBools 逐元素与运算，结果长度取较短的一个
+func andBools(a, b []bool) []bool {
+	result := make([]bool, min(len(a), len(b)))
+	for i := range result {
+		result[i] = a[i] && b[i]
+	}
+	return result
+}
+
 // ------------------ 0级：核心工具函数 --------------------------------------------
 
 // RD 四舍五入取D位小数
@@ -154,12 +176,12 @@ func TAN(S Series) Series {
 	return result
 }
 
-// MAX 序列最大值
+// MAX 序列最大值，长度为 1 的 S2 按标量广播
 func MAX(S1, S2 Series) Series {
 	result := make(Series, len(S1))
 	for i := range S1 {
-		if i < len(S2) {
-			result[i] = math.Max(S1[i], S2[i])
+		if i < len(S2) || len(S2) == 1 {
+			result[i] = math.Max(S1[i], valueAt(S2, i))
 		} else {
 			result[i] = S1[i]
 		}
@@ -176,12 +198,12 @@ func MAXS(S Series, scalar float64) Series {
 	return result
 }
 
-// MIN 序列最小值
+// MIN 序列最小值，长度为 1 的 S2 按标量广播
 func MIN(S1, S2 Series) Series {
 	result := make(Series, len(S1))
 	for i := range S1 {
-		if i < len(S2) {
-			result[i] = math.Min(S1[i], S2[i])
+		if i < len(S2) || len(S2) == 1 {
+			result[i] = math.Min(S1[i], valueAt(S2, i))
 		} else {
 			result[i] = S1[i]
 		}
@@ -189,16 +211,15 @@ func MIN(S1, S2 Series) Series {
 	return result
 }
 
-// IF 序列布尔判断
+// IF 序列布尔判断，条件成立取 A，否则取 B
+// 长度为 1 的 A、B 按标量广播，超出范围的位置为 NaN
 func IF(S []bool, A, B Series) Series {
 	result := make(Series, len(S))
 	for i, condition := range S {
-		if condition && i < len(A) {
-			result[i] = A[i]
-		} else if i < len(B) {
-			result[i] = B[i]
+		if condition {
+			result[i] = valueAt(A, i)
 		} else {
-			result[i] = math.NaN()
+			result[i] = valueAt(B, i)
 		}
 	}
 	return result
@@ -281,82 +302,42 @@ func DIFF(S Series, N int) Series {
 	return result
 }
 
-// STD 求序列的N日标准差
-func STD(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			slice := S[i-N+1 : i+1]
-			mean := 0.0
-			for _, v := range slice {
-				if !math.IsNaN(v) {
-					mean += v
-				}
-			}
-			mean /= float64(len(slice))
-
-			variance := 0.0
-			for _, v := range slice {
-				if !math.IsNaN(v) {
-					variance += math.Pow(v-mean, 2)
-				}
-			}
-			variance /= float64(len(slice))
-			result[i] = math.Sqrt(variance)
-		} else {
-			result[i] = math.NaN()
-		}
+// STD 求序列的N日标准差（总体标准差，与 pandas ddof=0 一致）
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func STD(S Series, N int, minPeriods ...int) Series {
+	if N <= 0 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, _ []int) float64 {
+		mean := meanOf(vals)
+		variance := 0.0
+		for _, v := range vals {
+			variance += (v - mean) * (v - mean)
+		}
+		return math.Sqrt(variance / float64(len(vals)))
+	})
 }
 
-// SUM 对序列求N天累计和
-func SUM(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if N > 0 {
-			if i >= N-1 {
-				sum := 0.0
-				for j := i - N + 1; j <= i; j++ {
-					if !math.IsNaN(S[j]) {
-						sum += S[j]
-					}
-				}
-				result[i] = sum
-			} else {
-				result[i] = math.NaN()
-			}
-		} else {
-			// N=0时对序列所有依次求和
-			sum := 0.0
-			for j := 0; j <= i; j++ {
-				if !math.IsNaN(S[j]) {
-					sum += S[j]
-				}
-			}
-			result[i] = sum
-		}
+// SUM 对序列求N天累计和，N=0 时从第一个有效值开始累计
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func SUM(S Series, N int, minPeriods ...int) Series {
+	if N < 0 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, _ []int) float64 {
+		return sumOf(vals)
+	})
 }
 
 // MA 求序列的N日简单移动平均值
-func MA(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			sum := 0.0
-			for j := i - N + 1; j <= i; j++ {
-				if !math.IsNaN(S[j]) {
-					sum += S[j]
-				}
-			}
-			result[i] = sum / float64(N)
-		} else {
-			result[i] = math.NaN()
-		}
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func MA(S Series, N int, minPeriods ...int) Series {
+	if N <= 0 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, _ []int) float64 {
+		return meanOf(vals)
+	})
 }
 
 // EMA 指数移动平均
@@ -367,22 +348,21 @@ func EMA(S Series, N int) Series {
 	alpha := 2.0 / float64(N+1)
 	result := make(Series, len(S))
 
-	// 找到第一个非NaN值作为初始值
-	var firstValid float64
-	var firstIndex int
+	// 找到第一个非NaN值作为初始值，之前的位置为 NaN
+	firstIndex := -1
 	for i, v := range S {
 		if !math.IsNaN(v) {
-			firstValid = v
 			firstIndex = i
 			break
 		}
+		result[i] = math.NaN()
 	}
 
-	if math.IsNaN(firstValid) {
+	if firstIndex < 0 {
 		return result // 全部都是NaN
 	}
 
-	result[firstIndex] = firstValid
+	result[firstIndex] = S[firstIndex]
 
 	for i := firstIndex + 1; i < len(S); i++ {
 		if !math.IsNaN(S[i]) {
@@ -402,22 +382,21 @@ func SMA(S Series, N int, M float64) Series {
 	alpha := M / float64(N)
 	result := make(Series, len(S))
 
-	// 找到第一个非NaN值作为初始值
-	var firstValid float64
-	var firstIndex int
+	// 找到第一个非NaN值作为初始值，之前的位置为 NaN
+	firstIndex := -1
 	for i, v := range S {
 		if !math.IsNaN(v) {
-			firstValid = v
 			firstIndex = i
 			break
 		}
+		result[i] = math.NaN()
 	}
 
-	if math.IsNaN(firstValid) {
+	if firstIndex < 0 {
 		return result // 全部都是NaN
 	}
 
-	result[firstIndex] = firstValid
+	result[firstIndex] = S[firstIndex]
 
 	for i := firstIndex + 1; i < len(S); i++ {
 		if !math.IsNaN(S[i]) {
@@ -429,30 +408,22 @@ func SMA(S Series, N int, M float64) Series {
 	return result
 }
 
-// WMA 加权移动平均
-func WMA(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			sum := 0.0
-			weightSum := 0.0
-			for j := 0; j < N; j++ {
-				weight := float64(j + 1)
-				if !math.IsNaN(S[i-N+1+j]) {
-					sum += S[i-N+1+j] * weight
-					weightSum += weight
-				}
-			}
-			if weightSum > 0 {
-				result[i] = sum / weightSum
-			} else {
-				result[i] = math.NaN()
-			}
-		} else {
-			result[i] = math.NaN()
+// WMA 加权移动平均，越近的值权重越大
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func WMA(S Series, N int, minPeriods ...int) Series {
+	if N <= 0 {
+		return nanSeries(len(S))
+	}
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, pos []int) float64 {
+		sum := 0.0
+		weightSum := 0.0
+		for j, v := range vals {
+			weight := float64(pos[j] + 1)
+			sum += v * weight
+			weightSum += weight
 		}
-	}
-	return result
+		return sum / weightSum
+	})
 }
 
 // DMA 动态移动平均
@@ -462,22 +433,21 @@ func DMA(S Series, A float64) Series {
 	}
 	result := make(Series, len(S))
 
-	// 找到第一个非NaN值作为初始值
-	var firstValid float64
-	var firstIndex int
+	// 找到第一个非NaN值作为初始值，之前的位置为 NaN
+	firstIndex := -1
 	for i, v := range S {
 		if !math.IsNaN(v) {
-			firstValid = v
 			firstIndex = i
 			break
 		}
+		result[i] = math.NaN()
 	}
 
-	if math.IsNaN(firstValid) {
+	if firstIndex < 0 {
 		return result // 全部都是NaN
 	}
 
-	result[firstIndex] = firstValid
+	result[firstIndex] = S[firstIndex]
 
 	for i := firstIndex + 1; i < len(S); i++ {
 		if !math.IsNaN(S[i]) {
@@ -489,63 +459,66 @@ func DMA(S Series, A float64) Series {
 	return result
 }
 
-// HHV 最近N天最高价
-func HHV(S Series, N int) Series {
+// DMAS 平滑因子为序列的动态移动平均，A 中的 NaN 按 1 处理（即取当前值）
+func DMAS(S Series, A Series) Series {
 	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			max := S[i-N+1]
-			for j := i - N + 2; j <= i; j++ {
-				if !math.IsNaN(S[j]) && S[j] > max {
-					max = S[j]
-				}
-			}
-			result[i] = max
-		} else {
-			result[i] = math.NaN()
+	if len(S) == 0 {
+		return result
+	}
+	result[0] = S[0]
+	for i := 1; i < len(S); i++ {
+		a := valueAt(A, i)
+		if math.IsNaN(a) {
+			a = 1
 		}
+		result[i] = a*S[i] + (1-a)*result[i-1]
 	}
 	return result
 }
 
-// LLV 最近N天最低价
-func LLV(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			min := S[i-N+1]
-			for j := i - N + 2; j <= i; j++ {
-				if !math.IsNaN(S[j]) && S[j] < min {
-					min = S[j]
-				}
+// HHV 最近N天最高价，N=0 时从第一个有效值开始累计
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func HHV(S Series, N int, minPeriods ...int) Series {
+	if N < 0 {
+		return nanSeries(len(S))
+	}
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, _ []int) float64 {
+		max := vals[0]
+		for _, v := range vals[1:] {
+			if v > max {
+				max = v
 			}
-			result[i] = min
-		} else {
-			result[i] = math.NaN()
 		}
+		return max
+	})
+}
+
+// LLV 最近N天最低价，N=0 时从第一个有效值开始累计
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func LLV(S Series, N int, minPeriods ...int) Series {
+	if N < 0 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, _ []int) float64 {
+		min := vals[0]
+		for _, v := range vals[1:] {
+			if v < min {
+				min = v
+			}
+		}
+		return min
+	})
 }
 
 // ------------------ 1级：应用层函数 ---------------------------------
 
-// COUNT 最近N天满足条件的天数
-func COUNT(S []bool, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			count := 0
-			for j := i - N + 1; j <= i; j++ {
-				if j >= 0 && j < len(S) && S[j] {
-					count++
-				}
-			}
-			result[i] = float64(count)
-		} else {
-			result[i] = math.NaN()
-		}
+// COUNT 最近N天满足条件的天数，N=0 时从第一根 K 线开始统计
+// minPeriods 可选，K 线数不足时为 NaN，默认等于 N
+func COUNT(S []bool, N int, minPeriods ...int) Series {
+	if N < 0 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingCount(S, N, resolveMinPeriods(N, minPeriods))
 }
 
 // EVERY 最近N天是否都是True
@@ -588,13 +561,17 @@ func EXIST(S []bool, N int) []bool {
 	return result
 }
 
-// CROSS 判断向上金叉穿越
+// CROSS 判断向上金叉穿越，前一根和当前 K 线上两条线都有效才可能成立
+// 长度为 1 的 S2 按标量广播
 func CROSS(S1, S2 Series) []bool {
 	result := make([]bool, len(S1))
 	for i := 1; i < len(S1); i++ {
-		if i < len(S2) {
-			result[i] = !(S1[i-1] > S2[i-1]) && (S1[i] > S2[i])
+		prev1, prev2 := S1[i-1], valueAt(S2, i-1)
+		cur1, cur2 := S1[i], valueAt(S2, i)
+		if math.IsNaN(prev1) || math.IsNaN(prev2) || math.IsNaN(cur1) || math.IsNaN(cur2) {
+			continue
 		}
+		result[i] = prev1 <= prev2 && cur1 > cur2
 	}
 	return result
 }
@@ -681,11 +658,11 @@ func DMI(CLOSE, HIGH, LOW Series, M1, M2 int) (Series, Series, Series, Series) {
 	TR := SUM(MAX(MAX(SUB(HIGH, LOW), ABS(SUB(HIGH, REF(CLOSE, 1)))), ABS(SUB(LOW, REF(CLOSE, 1)))), M1)
 	HD := SUB(HIGH, REF(HIGH, 1))
 	LD := SUB(REF(LOW, 1), LOW)
-	DMP := SUM(IF(GreaterThan(HD, NewSeries([]float64{0})), HD, NewSeries([]float64{0})), M1)
-	DMM := SUM(IF(GreaterThan(LD, NewSeries([]float64{0})), LD, NewSeries([]float64{0})), M1)
+	DMP := SUM(IF(andBools(GreaterThan(HD, NewSeries([]float64{0})), GreaterThan(HD, LD)), HD, NewSeries([]float64{0})), M1)
+	DMM := SUM(IF(andBools(GreaterThan(LD, NewSeries([]float64{0})), GreaterThan(LD, HD)), LD, NewSeries([]float64{0})), M1)
 	PDI := DIV(MULS(DMP, 100), TR)
 	MDI := DIV(MULS(DMM, 100), TR)
-	ADX := MA(ABS(DIV(SUB(MDI, PDI), ADD(PDI, MDI))), M2)
+	ADX := MA(MULS(ABS(DIV(SUB(MDI, PDI), ADD(PDI, MDI))), 100), M2)
 	ADXR := DIVS(ADD(ADX, REF(ADX, M2)), 2)
 	return PDI, MDI, ADX, ADXR
 }
@@ -792,7 +769,11 @@ func OBV(CLOSE, VOL Series) Series {
 func MFI(CLOSE, HIGH, LOW, VOL Series, N int) Series {
 	TYP := DIVS(ADD(ADD(HIGH, LOW), CLOSE), 3)
 	V1 := DIV(SUM(IF(GreaterThan(TYP, REF(TYP, 1)), MUL(TYP, VOL), NewSeries([]float64{0})), N), SUM(IF(LessThan(TYP, REF(TYP, 1)), MUL(TYP, VOL), NewSeries([]float64{0})), N))
-	return SUB(NewSeries([]float64{100}), DIVS(NewSeries([]float64{100}), 1+V1[0]))
+	result := make(Series, len(V1))
+	for i, v := range V1 {
+		result[i] = 100 - 100/(1+v)
+	}
+	return result
 }
 
 // ASI 振动升降指标
@@ -803,7 +784,8 @@ func ASI(OPEN, CLOSE, HIGH, LOW Series, M1, M2 int) (Series, Series) {
 	CC := ABS(SUB(HIGH, REF(LOW, 1)))
 	DD := ABS(SUB(LC, REF(OPEN, 1)))
 
-	R := IF(GreaterThan(AA, BB), ADD(ADD(AA, DIVS(BB, 2)), DIVS(DD, 4)), IF(GreaterThan(BB, CC), ADD(ADD(BB, DIVS(AA, 2)), DIVS(DD, 4)), ADD(CC, DIVS(DD, 4))))
+	R := IF(andBools(GreaterThan(AA, BB), GreaterThan(AA, CC)), ADD(ADD(AA, DIVS(BB, 2)), DIVS(DD, 4)),
+		IF(andBools(GreaterThan(BB, CC), GreaterThan(BB, AA)), ADD(ADD(BB, DIVS(AA, 2)), DIVS(DD, 4)), ADD(CC, DIVS(DD, 4))))
 
 	X := ADD(SUB(CLOSE, LC), ADD(DIVS(SUB(CLOSE, OPEN), 2), SUB(LC, REF(OPEN, 1))))
 	SI := DIV(MUL(MULS(X, 16), MAX(AA, BB)), R)
@@ -817,7 +799,10 @@ func XSII(CLOSE, HIGH, LOW Series, N int, M float64) (Series, Series, Series, Se
 	AA := MA(DIVS(ADD(ADD(MULS(CLOSE, 2), HIGH), LOW), 4), 5)
 	TD1 := DIVS(MULS(AA, float64(N)), 100)
 	TD2 := DIVS(MULS(AA, 200-float64(N)), 100)
-	DD := MA(CLOSE, 20) // 简化处理，使用MA替代DMA
+	TYP := DIVS(ADD(ADD(MULS(CLOSE, 2), HIGH), LOW), 4)
+	MA20 := MA(CLOSE, 20)
+	CC := DIV(ABS(SUB(TYP, MA20)), MA20)
+	DD := DMAS(CLOSE, CC)
 	TD3 := MULS(DD, 1+M/100)
 	TD4 := MULS(DD, 1-M/100)
 	return TD1, TD2, TD3, TD4
@@ -825,12 +810,12 @@ func XSII(CLOSE, HIGH, LOW Series, N int, M float64) (Series, Series, Series, Se
 
 // 辅助函数：序列运算
 
-// ADD 序列加法
+// ADD 序列加法，长度为 1 的 S2 按标量广播
 func ADD(S1, S2 Series) Series {
 	result := make(Series, len(S1))
 	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] + S2[i]
+		if i < len(S2) || len(S2) == 1 {
+			result[i] = S1[i] + valueAt(S2, i)
 		} else {
 			result[i] = S1[i]
 		}
@@ -838,12 +823,12 @@ func ADD(S1, S2 Series) Series {
 	return result
 }
 
-// SUB 序列减法
+// SUB 序列减法，长度为 1 的 S2 按标量广播
 func SUB(S1, S2 Series) Series {
 	result := make(Series, len(S1))
 	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] - S2[i]
+		if i < len(S2) || len(S2) == 1 {
+			result[i] = S1[i] - valueAt(S2, i)
 		} else {
 			result[i] = S1[i]
 		}
@@ -851,12 +836,12 @@ func SUB(S1, S2 Series) Series {
 	return result
 }
 
-// MUL 序列乘法
+// MUL 序列乘法，长度为 1 的 S2 按标量广播
 func MUL(S1, S2 Series) Series {
 	result := make(Series, len(S1))
 	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] * S2[i]
+		if i < len(S2) || len(S2) == 1 {
+			result[i] = S1[i] * valueAt(S2, i)
 		} else {
 			result[i] = S1[i]
 		}
@@ -873,12 +858,13 @@ func MULS(S Series, scalar float64) Series {
 	return result
 }
 
-// DIV 序列除法
+// DIV 序列除法，除数为 0 或超出范围时为 NaN，长度为 1 的 S2 按标量广播
 func DIV(S1, S2 Series) Series {
 	result := make(Series, len(S1))
 	for i := range S1 {
-		if i < len(S2) && S2[i] != 0 {
-			result[i] = S1[i] / S2[i]
+		d := valueAt(S2, i)
+		if d != 0 && !math.IsNaN(d) {
+			result[i] = S1[i] / d
 		} else {
 			result[i] = math.NaN()
 		}
@@ -900,190 +886,73 @@ func DIVS(S Series, scalar float64) Series {
 }
 
 // AVEDEV 平均绝对偏差
-func AVEDEV(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			slice := S[i-N+1 : i+1]
-			mean := 0.0
-			for _, v := range slice {
-				if !math.IsNaN(v) {
-					mean += v
-				}
-			}
-			mean /= float64(len(slice))
-
-			deviation := 0.0
-			for _, v := range slice {
-				if !math.IsNaN(v) {
-					deviation += math.Abs(v - mean)
-				}
-			}
-			result[i] = deviation / float64(len(slice))
-		} else {
-			result[i] = math.NaN()
-		}
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N
+func AVEDEV(S Series, N int, minPeriods ...int) Series {
+	if N <= 0 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, resolveMinPeriods(N, minPeriods), func(vals []float64, _ []int) float64 {
+		mean := meanOf(vals)
+		deviation := 0.0
+		for _, v := range vals {
+			deviation += math.Abs(v - mean)
+		}
+		return deviation / float64(len(vals))
+	})
 }
 
 // GreaterThan 序列比较：S1 > S2
 func GreaterThan(S1, S2 Series) []bool {
-	result := make([]bool, len(S1))
-	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] > S2[i]
-		} else {
-			result[i] = false
-		}
-	}
-	return result
+	return compareSeries(S1, S2, func(a, b float64) bool { return a > b })
 }
 
 // LessThan 序列比较：S1 < S2
 func LessThan(S1, S2 Series) []bool {
-	result := make([]bool, len(S1))
-	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] < S2[i]
-		} else {
-			result[i] = false
-		}
-	}
-	return result
+	return compareSeries(S1, S2, func(a, b float64) bool { return a < b })
 }
 
 // LessThanOrEqual 序列比较：S1 <= S2
 func LessThanOrEqual(S1, S2 Series) []bool {
-	result := make([]bool, len(S1))
-	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] <= S2[i]
-		} else {
-			result[i] = false
-		}
-	}
-	return result
+	return compareSeries(S1, S2, func(a, b float64) bool { return a <= b })
 }
 
 // Equal 序列比较：S1 == S2
 func Equal(S1, S2 Series) []bool {
-	result := make([]bool, len(S1))
-	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] == S2[i]
-		} else {
-			result[i] = false
-		}
-	}
-	return result
+	return compareSeries(S1, S2, func(a, b float64) bool { return a == b })
 }
 
 // NotEqual 序列比较：S1 != S2
 func NotEqual(S1, S2 Series) []bool {
-	result := make([]bool, len(S1))
-	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] != S2[i]
-		} else {
-			result[i] = true
-		}
-	}
-	return result
+	return compareSeries(S1, S2, func(a, b float64) bool { return a != b })
 }
 
 // GreaterThanOrEqual 序列比较：S1 >= S2
 func GreaterThanOrEqual(S1, S2 Series) []bool {
-	result := make([]bool, len(S1))
-	for i := range S1 {
-		if i < len(S2) {
-			result[i] = S1[i] >= S2[i]
-		} else {
-			result[i] = true
-		}
-	}
-	return result
+	return compareSeries(S1, S2, func(a, b float64) bool { return a >= b })
 }
 
 // SLOPE 返回S序列N周期回线性回归斜率
-func SLOPE(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			slice := S[i-N+1 : i+1]
-			if len(slice) < 2 {
-				result[i] = math.NaN()
-				continue
-			}
-
-			// 计算线性回归斜率
-			sumX := 0.0
-			sumY := 0.0
-			sumXY := 0.0
-			sumXX := 0.0
-
-			for j, v := range slice {
-				if !math.IsNaN(v) {
-					x := float64(j)
-					y := v
-					sumX += x
-					sumY += y
-					sumXY += x * y
-					sumXX += x * x
-				}
-			}
-
-			n := float64(len(slice))
-			slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-			result[i] = slope
-		} else {
-			result[i] = math.NaN()
-		}
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N，至少需要 2 个点
+func SLOPE(S Series, N int, minPeriods ...int) Series {
+	if N < 2 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, max(resolveMinPeriods(N, minPeriods), 2), func(vals []float64, pos []int) float64 {
+		slope, _ := linearRegression(vals, pos)
+		return slope
+	})
 }
 
-// FORCAST 返回S序列N周期回线性回归后的预测值
-func FORCAST(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			slice := S[i-N+1 : i+1]
-			if len(slice) < 2 {
-				result[i] = math.NaN()
-				continue
-			}
-
-			// 计算线性回归
-			sumX := 0.0
-			sumY := 0.0
-			sumXY := 0.0
-			sumXX := 0.0
-
-			for j, v := range slice {
-				if !math.IsNaN(v) {
-					x := float64(j)
-					y := v
-					sumX += x
-					sumY += y
-					sumXY += x * y
-					sumXX += x * x
-				}
-			}
-
-			n := float64(len(slice))
-			slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-			intercept := (sumY - slope*sumX) / n
-
-			// 预测下一个值
-			predictX := float64(N - 1)
-			predictY := slope*predictX + intercept
-			result[i] = predictY
-		} else {
-			result[i] = math.NaN()
-		}
+// FORCAST 返回S序列N周期回线性回归后在当前 K 线上的预测值
+// minPeriods 可选，窗口内有效值不足时为 NaN，默认等于 N，至少需要 2 个点
+func FORCAST(S Series, N int, minPeriods ...int) Series {
+	if N < 2 {
+		return nanSeries(len(S))
 	}
-	return result
+	return rollingApply(S, N, max(resolveMinPeriods(N, minPeriods), 2), func(vals []float64, pos []int) float64 {
+		slope, intercept := linearRegression(vals, pos)
+		return slope*float64(N-1) + intercept
+	})
 }
 
 // BARSLAST 上一次条件成立到当前的周期
@@ -1140,11 +1009,11 @@ func LONGCROSS(S1, S2 Series, N int) []bool {
 	result := make([]bool, len(S1))
 	for i := range S1 {
 		if i >= N && i < len(S2) {
-			// 检查前N个周期内S1是否都小于S2
+			// 检查前N个周期内S1是否都小于S2，NaN 视为不成立
 			allLess := true
 			for j := i - N + 1; j < i; j++ {
 				if j >= 0 && j < len(S1) && j < len(S2) {
-					if S1[j] >= S2[j] {
+					if !(S1[j] < S2[j]) {
 						allLess = false
 						break
 					}
@@ -1219,55 +1088,45 @@ func BARSSINCEN(S []bool, N int) Series {
 	return result
 }
 
-// HHVBARS 求N周期内S最高值到当前周期数
+// HHVBARS 求N周期内S最高值到当前周期数，窗口内有 NaN 时为 NaN
 func HHVBARS(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			slice := S[i-N+1 : i+1]
-			maxValue := slice[0]
-			maxIndex := 0
-			for j, v := range slice {
-				if v > maxValue {
-					maxValue = v
-					maxIndex = j
-				}
+	if N <= 0 {
+		return nanSeries(len(S))
+	}
+	return rollingApply(S, N, N, func(vals []float64, pos []int) float64 {
+		maxIndex := 0
+		for j, v := range vals {
+			if v > vals[maxIndex] {
+				maxIndex = j
 			}
-			result[i] = float64(len(slice) - 1 - maxIndex)
-		} else {
-			result[i] = math.NaN()
 		}
-	}
-	return result
+		return float64(N - 1 - pos[maxIndex])
+	})
 }
 
-// LLVBARS 求N周期内S最低值到当前周期数
+// LLVBARS 求N周期内S最低值到当前周期数，窗口内有 NaN 时为 NaN
 func LLVBARS(S Series, N int) Series {
-	result := make(Series, len(S))
-	for i := range S {
-		if i >= N-1 {
-			slice := S[i-N+1 : i+1]
-			minValue := slice[0]
-			minIndex := 0
-			for j, v := range slice {
-				if v < minValue {
-					minValue = v
-					minIndex = j
-				}
+	if N <= 0 {
+		return nanSeries(len(S))
+	}
+	return rollingApply(S, N, N, func(vals []float64, pos []int) float64 {
+		minIndex := 0
+		for j, v := range vals {
+			if v < vals[minIndex] {
+				minIndex = j
 			}
-			result[i] = float64(len(slice) - 1 - minIndex)
-		} else {
-			result[i] = math.NaN()
 		}
-	}
-	return result
+		return float64(N - 1 - pos[minIndex])
+	})
 }
 
 // TOPRANGE 当前最高价是近多少周期内最高价的最大值
 func TOPRANGE(S Series) Series {
 	result := make(Series, len(S))
 	for i := range S {
-		if i == 0 {
+		if math.IsNaN(S[i]) {
+			result[i] = math.NaN()
+		} else if i == 0 {
 			result[i] = 0
 		} else {
 			count := 0
@@ -1288,7 +1147,9 @@ func TOPRANGE(S Series) Series {
 func LOWRANGE(S Series) Series {
 	result := make(Series, len(S))
 	for i := range S {
-		if i == 0 {
+		if math.IsNaN(S[i]) {
+			result[i] = math.NaN()
+		} else if i == 0 {
 			result[i] = 0
 		} else {
 			count := 0
@@ -1309,12 +1170,15 @@ func LOWRANGE(S Series) Series {
 
 // SAR 抛物转向指标
 func SAR(HIGH, LOW Series, N, S, M int) Series {
+	length := len(HIGH)
+	if N < 2 || length < N {
+		return nanSeries(length)
+	}
 	fStep := float64(S) / 100.0
 	fMax := float64(M) / 100.0
 	af := 0.0
 	isLong := HIGH[N-1] > HIGH[N-2]
 	bFirst := true
-	length := len(HIGH)
 
 	sHHV := REF(HHV(HIGH, N), 1)
 	sLLV := REF(LLV(LOW, N), 1)
@@ -1361,6 +1225,9 @@ func TDX_SAR(High, Low Series, iAFStep, iAFLimit int) Series {
 	afStep := float64(iAFStep) / 100.0
 	afLimit := float64(iAFLimit) / 100.0
 	SarX := make(Series, len(High))
+	if len(High) == 0 {
+		return SarX
+	}
 
 	bull := true
 	af := afStep
@@ -1414,14 +1281,14 @@ func TDX_SAR(High, Low Series, iAFStep, iAFLimit int) Series {
 
 // QRR 量比
 func QRR(VOL Series) Series {
-	return DIV(VOL, MA(REF(VOL, 5), 5))
+	return DIV(VOL, MA(REF(VOL, 1), 5))
 }
 
 // SHO 钱龙短线指标
 func SHO(CLOSE, VOL Series, N int) (Series, Series) {
 	VAR1 := MA(DIV(SUB(VOL, REF(VOL, 1)), REF(VOL, 1)), 5)
 	VAR2 := DIV(MULS(SUB(CLOSE, MA(CLOSE, 24)), 100), MA(CLOSE, 24))
-	SHT := MULS(VAR2, 1+VAR1[0])
+	SHT := MUL(VAR2, ADD(VAR1, NewSeries([]float64{1})))
 	SHTMA := MA(SHT, N)
 	return SHT, SHTMA
 }
@@ -1464,8 +1331,12 @@ func ZTPRICE(Slice Series, n float64) Series {
 // 全局函数映射表
 var functionMap map[string]any
 
+// GetConst 取序列最后一个值，返回同样长度的常量序列
 func GetConst(s Series) Series {
 	result := make(Series, len(s))
+	if len(s) == 0 {
+		return result
+	}
 	last := s[len(s)-1]
 	for i := range s {
 		result[i] = last
@@ -1574,6 +1445,33 @@ func init() {
 		"QRR":     QRR,
 		"SHO":     SHO,
 		"LON":     LON,
+		// 通达信扩展函数，见 tdx.go
+		"CEILING":       CEILING,
+		"FLOOR":         FLOOR,
+		"INTPART":       INTPART,
+		"MOD":           MOD,
+		"SIGN":          SIGN,
+		"EXP":           EXP,
+		"LOG":           LOG,
+		"ATAN":          ATAN,
+		"ASIN":          ASIN,
+		"ACOS":          ACOS,
+		"REVERSE":       REVERSE,
+		"REFX":          REFX,
+		"BACKSET":       BACKSET,
+		"BARSCOUNT":     BARSCOUNT,
+		"BARSSINCE":     BARSSINCE,
+		"CURRBARSCOUNT": CURRBARSCOUNT,
+		"SUMBARS":       SUMBARS,
+		"HOD":           HOD,
+		"LOD":           LOD,
+		"MEMA":          MEMA,
+		"XMA":           XMA,
+		"RANGE":         RANGE,
+		"CROSSDOWN":     CROSSDOWN,
+		"ZIG":           ZIG,
+		"PEAK":          PEAK,
+		"TROUGH":        TROUGH,
 	}
 }
 
@@ -1602,8 +1500,12 @@ func callFunctionByReflection(fn any, args []any) (any, error) {
 	fnValue := reflect.ValueOf(fn)
 	fnType := fnValue.Type()
 
-	// 检查参数数量
-	if fnType.NumIn() != len(args) {
+	// 检查参数数量，可变参数函数至少需要固定参数
+	if fnType.IsVariadic() {
+		if len(args) < fnType.NumIn()-1 {
+			return nil, fmt.Errorf("argument count mismatch: expected at least %d, got %d", fnType.NumIn()-1, len(args))
+		}
+	} else if fnType.NumIn() != len(args) {
 		return nil, fmt.Errorf("argument count mismatch: expected %d, got %d", fnType.NumIn(), len(args))
 	}
 
@@ -1613,7 +1515,12 @@ func callFunctionByReflection(fn any, args []any) (any, error) {
 	// 转换参数类型
 	convertedArgs := make([]reflect.Value, len(args))
 	for i, arg := range args {
-		expectedType := fnType.In(i)
+		var expectedType reflect.Type
+		if fnType.IsVariadic() && i >= fnType.NumIn()-1 {
+			expectedType = fnType.In(fnType.NumIn() - 1).Elem()
+		} else {
+			expectedType = fnType.In(i)
+		}
 		argValue := reflect.ValueOf(arg)
 
 		// 尝试类型转换
@@ -1719,7 +1626,7 @@ func convertType(src reflect.Value, dstType reflect.Type, targetLength int) (ref
 				if series, ok := srcSlice.(Series); ok {
 					dstSlice := make([]bool, len(series))
 					for i, v := range series {
-						dstSlice[i] = v != 0.0
+						dstSlice[i] = isTrue(v)
 					}
 					return reflect.ValueOf(dstSlice), nil
 				}
@@ -1727,7 +1634,7 @@ func convertType(src reflect.Value, dstType reflect.Type, targetLength int) (ref
 				if floatSlice, ok := srcSlice.([]float64); ok {
 					dstSlice := make([]bool, len(floatSlice))
 					for i, v := range floatSlice {
-						dstSlice[i] = v != 0.0
+						dstSlice[i] = isTrue(v)
 					}
 					return reflect.ValueOf(dstSlice), nil
 				}
@@ -0,0 +1,234 @@
+package indicators
+
+//go:generate go run ./internal/adaptergen -dir . -out adapters_gen.go
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Adapter 类型化的指标调用适配器，参数转换失败时返回错误而不是 panic
+// 内置函数的适配器由 adaptergen 根据 functionMap 中的函数签名生成，见 adapters_gen.go
+type Adapter func(args []any) (any, error)
+
+// ArgError 参数转换错误
+type ArgError struct {
+	Func  string // 函数名
+	Index int    // 参数位置，从 0 开始
+	Want  string // 期望的类型
+	Got   any    // 实际传入的值
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("%s: argument %d: cannot use %s as %s", e.Func, e.Index+1, describeArg(e.Got), e.Want)
+}
+
+func describeArg(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("string %q", x)
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// argCountError 参数数量错误
+func argCountError(name string, want, got int) error {
+	return fmt.Errorf("%s: argument count mismatch: expected %d, got %d", name, want, got)
+}
+
+// argRangeError 带可选参数的函数参数数量错误
+func argRangeError(name string, min, max, got int) error {
+	return fmt.Errorf("%s: argument count mismatch: expected %d to %d, got %d", name, min, max, got)
+}
+
+// CallIndicator 调用内置指标函数
+// 优先使用生成的类型化适配器，没有适配器的函数（如通过 RegisterIndicator 注册的用户函数）走反射调用
+func CallIndicator(funcName string, args []any) (ret any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %v", funcName, r)
+		}
+	}()
+	if adapter, ok := adapterMap[funcName]; ok {
+		return adapter(args)
+	}
+	return CallIndicatorByReflection(funcName, args)
+}
+
+// GetAdapter 获取指定函数的类型化适配器
+func GetAdapter(funcName string) (Adapter, bool) {
+	adapter, ok := adapterMap[funcName]
+	return adapter, ok
+}
+
+// RegisterIndicator 注册一个用户指标函数，fn 必须是函数，调用时通过反射转换参数
+// 注册同名函数会覆盖内置函数的适配器
+func RegisterIndicator(name string, fn any) error {
+	if fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return fmt.Errorf("indicator %s must be a function, got %T", name, fn)
+	}
+	functionMap[name] = fn
+	delete(adapterMap, name)
+	return nil
+}
+
+// broadcastLen 获取参数中最长序列的长度，用于标量广播
+// 参数全部是标量时返回 1，标量按长度为 1 的序列处理
+func broadcastLen(args []any) int {
+	maxLen := 0
+	for _, arg := range args {
+		n := 0
+		switch x := arg.(type) {
+		case Series:
+			n = len(x)
+		case []float64:
+			n = len(x)
+		case []bool:
+			n = len(x)
+		case float64, int, bool:
+			n = 1
+		case nil, string:
+		default:
+			if rv := reflect.ValueOf(arg); rv.Kind() == reflect.Slice {
+				n = rv.Len()
+			}
+		}
+		if n > maxLen {
+			maxLen = n
+		}
+	}
+	return maxLen
+}
+
+// argSeries 把参数转换为 Series，标量按 n 广播
+func argSeries(name string, idx int, v any, n int) (Series, error) {
+	switch x := v.(type) {
+	case Series:
+		return x, nil
+	case []float64:
+		return Series(x), nil
+	case float64:
+		return repeatFloat(x, n), nil
+	case int:
+		return repeatFloat(float64(x), n), nil
+	case bool:
+		return repeatFloat(boolToFloat(x), n), nil
+	case []bool:
+		result := make(Series, len(x))
+		for i, b := range x {
+			result[i] = boolToFloat(b)
+		}
+		return result, nil
+	}
+	return nil, &ArgError{Func: name, Index: idx, Want: "Series", Got: v}
+}
+
+// argFloats 把参数转换为 []float64
+func argFloats(name string, idx int, v any, n int) ([]float64, error) {
+	s, err := argSeries(name, idx, v, n)
+	return []float64(s), err
+}
+
+// argBools 把参数转换为 []bool，非零且非 NaN 为 true，标量按 n 广播
+func argBools(name string, idx int, v any, n int) ([]bool, error) {
+	switch x := v.(type) {
+	case []bool:
+		return x, nil
+	case Series:
+		return floatsToBools(x), nil
+	case []float64:
+		return floatsToBools(x), nil
+	case bool:
+		return repeatBool(x, n), nil
+	case float64:
+		return repeatBool(isTrue(x), n), nil
+	case int:
+		return repeatBool(x != 0, n), nil
+	}
+	return nil, &ArgError{Func: name, Index: idx, Want: "[]bool", Got: v}
+}
+
+// argOptInt 读取可选的 int 参数，未传入时返回 nil
+func argOptInt(name string, idx int, args []any) ([]int, error) {
+	if idx >= len(args) {
+		return nil, nil
+	}
+	v, err := argInt(name, idx, args[idx])
+	if err != nil {
+		return nil, err
+	}
+	return []int{v}, nil
+}
+
+// argInt 把参数转换为 int，浮点数截断取整
+func argInt(name string, idx int, v any) (int, error) {
+	switch x := v.(type) {
+	case int:
+		return x, nil
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0, &ArgError{Func: name, Index: idx, Want: "int", Got: v}
+		}
+		return int(x), nil
+	case bool:
+		return int(boolToFloat(x)), nil
+	}
+	return 0, &ArgError{Func: name, Index: idx, Want: "int", Got: v}
+}
+
+// argFloat 把参数转换为 float64
+func argFloat(name string, idx int, v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case int:
+		return float64(x), nil
+	case bool:
+		return boolToFloat(x), nil
+	}
+	return 0, &ArgError{Func: name, Index: idx, Want: "float64", Got: v}
+}
+
+// argAny 参数原样传递，只拒绝 nil 和字符串
+func argAny(name string, idx int, v any) (any, error) {
+	switch v.(type) {
+	case nil, string:
+		return nil, &ArgError{Func: name, Index: idx, Want: "number or Series", Got: v}
+	}
+	return v, nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func repeatFloat(v float64, n int) Series {
+	result := make(Series, n)
+	for i := range result {
+		result[i] = v
+	}
+	return result
+}
+
+func repeatBool(v bool, n int) []bool {
+	result := make([]bool, n)
+	for i := range result {
+		result[i] = v
+	}
+	return result
+}
+
+func floatsToBools(s []float64) []bool {
+	result := make([]bool, len(s))
+	for i, v := range s {
+		result[i] = isTrue(v)
+	}
+	return result
+}
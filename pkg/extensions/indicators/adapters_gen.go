@@ -0,0 +1,2298 @@
+// Code generated by adaptergen. DO NOT EDIT.
+
+package indicators
+
+// adapterMap 内置指标函数的类型化适配器，key 与 functionMap 一致
+var adapterMap = map[string]Adapter{
+	"ABS":                adaptABS,
+	"ACOS":               adaptACOS,
+	"ADD":                adaptADD,
+	"ASI":                adaptASI,
+	"ASIN":               adaptASIN,
+	"ATAN":               adaptATAN,
+	"ATR":                adaptATR,
+	"AVEDEV":             adaptAVEDEV,
+	"BACKSET":            adaptBACKSET,
+	"BARSCOUNT":          adaptBARSCOUNT,
+	"BARSLAST":           adaptBARSLAST,
+	"BARSSINCE":          adaptBARSSINCE,
+	"BARSSINCEN":         adaptBARSSINCEN,
+	"BBI":                adaptBBI,
+	"BETWEEN":            adaptBETWEEN,
+	"BIAS":               adaptBIAS,
+	"BOLL":               adaptBOLL,
+	"BRAR":               adaptBRAR,
+	"CCI":                adaptCCI,
+	"CEILING":            adaptCEILING,
+	"CONST":              adaptCONST,
+	"COS":                adaptCOS,
+	"COUNT":              adaptCOUNT,
+	"CR":                 adaptCR,
+	"CROSS":              adaptCROSS,
+	"CROSSDOWN":          adaptCROSSDOWN,
+	"CURRBARSCOUNT":      adaptCURRBARSCOUNT,
+	"DFMA":               adaptDFMA,
+	"DIFF":               adaptDIFF,
+	"DIV":                adaptDIV,
+	"DMA":                adaptDMA,
+	"DMI":                adaptDMI,
+	"DPO":                adaptDPO,
+	"DTPRICE":            adaptDTPRICE,
+	"EMA":                adaptEMA,
+	"EMV":                adaptEMV,
+	"EVERY":              adaptEVERY,
+	"EXIST":              adaptEXIST,
+	"EXP":                adaptEXP,
+	"EXPMA":              adaptEXPMA,
+	"Equal":              adaptEqual,
+	"FILTER":             adaptFILTER,
+	"FLOOR":              adaptFLOOR,
+	"FORCAST":            adaptFORCAST,
+	"GreaterThan":        adaptGreaterThan,
+	"GreaterThanOrEqual": adaptGreaterThanOrEqual,
+	"HHV":                adaptHHV,
+	"HHVBARS":            adaptHHVBARS,
+	"HOD":                adaptHOD,
+	"IF":                 adaptIF,
+	"INTPART":            adaptINTPART,
+	"KDJ":                adaptKDJ,
+	"KTN":                adaptKTN,
+	"LAST":               adaptLAST,
+	"LLV":                adaptLLV,
+	"LLVBARS":            adaptLLVBARS,
+	"LN":                 adaptLN,
+	"LOD":                adaptLOD,
+	"LOG":                adaptLOG,
+	"LON":                adaptLON,
+	"LONGCROSS":          adaptLONGCROSS,
+	"LOWRANGE":           adaptLOWRANGE,
+	"LessThan":           adaptLessThan,
+	"LessThanOrEqual":    adaptLessThanOrEqual,
+	"MA":                 adaptMA,
+	"MACD":               adaptMACD,
+	"MASS":               adaptMASS,
+	"MAX":                adaptMAX,
+	"MEMA":               adaptMEMA,
+	"MFI":                adaptMFI,
+	"MIN":                adaptMIN,
+	"MOD":                adaptMOD,
+	"MTM":                adaptMTM,
+	"MUL":                adaptMUL,
+	"NotEqual":           adaptNotEqual,
+	"OBV":                adaptOBV,
+	"PEAK":               adaptPEAK,
+	"POW":                adaptPOW,
+	"PSY":                adaptPSY,
+	"QRR":                adaptQRR,
+	"RANGE":              adaptRANGE,
+	"RD":                 adaptRD,
+	"REF":                adaptREF,
+	"REFX":               adaptREFX,
+	"RET":                adaptRET,
+	"REVERSE":            adaptREVERSE,
+	"ROC":                adaptROC,
+	"RSI":                adaptRSI,
+	"SAR":                adaptSAR,
+	"SHO":                adaptSHO,
+	"SIGN":               adaptSIGN,
+	"SIN":                adaptSIN,
+	"SLOPE":              adaptSLOPE,
+	"SMA":                adaptSMA,
+	"SQRT":               adaptSQRT,
+	"STD":                adaptSTD,
+	"SUB":                adaptSUB,
+	"SUM":                adaptSUM,
+	"SUMBARS":            adaptSUMBARS,
+	"TAN":                adaptTAN,
+	"TAQ":                adaptTAQ,
+	"TDX_SAR":            adaptTDX_SAR,
+	"TOPRANGE":           adaptTOPRANGE,
+	"TRIX":               adaptTRIX,
+	"TROUGH":             adaptTROUGH,
+	"VALUEWHEN":          adaptVALUEWHEN,
+	"VR":                 adaptVR,
+	"WMA":                adaptWMA,
+	"WR":                 adaptWR,
+	"XMA":                adaptXMA,
+	"XSII":               adaptXSII,
+	"ZIG":                adaptZIG,
+	"ZTPRICE":            adaptZTPRICE,
+}
+
+// adaptABS 调用 ABS(Series)
+func adaptABS(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("ABS", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ABS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return ABS(a0), nil
+}
+
+// adaptACOS 调用 ACOS(Series)
+func adaptACOS(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("ACOS", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ACOS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return ACOS(a0), nil
+}
+
+// adaptADD 调用 ADD(Series, Series)
+func adaptADD(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("ADD", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ADD", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("ADD", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return ADD(a0, a1), nil
+}
+
+// adaptASI 调用 ASI(Series, Series, Series, Series, int, int)
+func adaptASI(args []any) (any, error) {
+	if len(args) != 6 {
+		return nil, argCountError("ASI", 6, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ASI", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("ASI", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("ASI", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argSeries("ASI", 3, args[3], n)
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("ASI", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	a5, err := argInt("ASI", 5, args[5])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := ASI(a0, a1, a2, a3, a4, a5)
+	return []any{r0, r1}, nil
+}
+
+// adaptASIN 调用 ASIN(Series)
+func adaptASIN(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("ASIN", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ASIN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return ASIN(a0), nil
+}
+
+// adaptATAN 调用 ATAN(Series)
+func adaptATAN(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("ATAN", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ATAN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return ATAN(a0), nil
+}
+
+// adaptATR 调用 ATR(Series, Series, Series, int)
+func adaptATR(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("ATR", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ATR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("ATR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("ATR", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("ATR", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	return ATR(a0, a1, a2, a3), nil
+}
+
+// adaptAVEDEV 调用 AVEDEV(Series, int, ...int)
+func adaptAVEDEV(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("AVEDEV", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("AVEDEV", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("AVEDEV", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("AVEDEV", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return AVEDEV(a0, a1, opt...), nil
+}
+
+// adaptBACKSET 调用 BACKSET([]bool, Series)
+func adaptBACKSET(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("BACKSET", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("BACKSET", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("BACKSET", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return BACKSET(a0, a1), nil
+}
+
+// adaptBARSCOUNT 调用 BARSCOUNT(Series)
+func adaptBARSCOUNT(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("BARSCOUNT", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("BARSCOUNT", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return BARSCOUNT(a0), nil
+}
+
+// adaptBARSLAST 调用 BARSLAST([]bool)
+func adaptBARSLAST(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("BARSLAST", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("BARSLAST", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return BARSLAST(a0), nil
+}
+
+// adaptBARSSINCE 调用 BARSSINCE([]bool)
+func adaptBARSSINCE(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("BARSSINCE", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("BARSSINCE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return BARSSINCE(a0), nil
+}
+
+// adaptBARSSINCEN 调用 BARSSINCEN([]bool, int)
+func adaptBARSSINCEN(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("BARSSINCEN", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("BARSSINCEN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("BARSSINCEN", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return BARSSINCEN(a0, a1), nil
+}
+
+// adaptBBI 调用 BBI(Series, int, int, int, int)
+func adaptBBI(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("BBI", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("BBI", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("BBI", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("BBI", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("BBI", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("BBI", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	return BBI(a0, a1, a2, a3, a4), nil
+}
+
+// adaptBETWEEN 调用 BETWEEN(Series, Series, Series)
+func adaptBETWEEN(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("BETWEEN", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("BETWEEN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("BETWEEN", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("BETWEEN", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	return BETWEEN(a0, a1, a2), nil
+}
+
+// adaptBIAS 调用 BIAS(Series, int, int, int)
+func adaptBIAS(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("BIAS", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("BIAS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("BIAS", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("BIAS", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("BIAS", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2 := BIAS(a0, a1, a2, a3)
+	return []any{r0, r1, r2}, nil
+}
+
+// adaptBOLL 调用 BOLL(Series, int, float64)
+func adaptBOLL(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("BOLL", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("BOLL", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("BOLL", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argFloat("BOLL", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2 := BOLL(a0, a1, a2)
+	return []any{r0, r1, r2}, nil
+}
+
+// adaptBRAR 调用 BRAR(Series, Series, Series, Series, int)
+func adaptBRAR(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("BRAR", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("BRAR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("BRAR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("BRAR", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argSeries("BRAR", 3, args[3], n)
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("BRAR", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := BRAR(a0, a1, a2, a3, a4)
+	return []any{r0, r1}, nil
+}
+
+// adaptCCI 调用 CCI(Series, Series, Series, int)
+func adaptCCI(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("CCI", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CCI", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("CCI", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("CCI", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("CCI", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	return CCI(a0, a1, a2, a3), nil
+}
+
+// adaptCEILING 调用 CEILING(Series)
+func adaptCEILING(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("CEILING", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CEILING", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return CEILING(a0), nil
+}
+
+// adaptCONST 调用 GetConst(Series)
+func adaptCONST(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("CONST", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CONST", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return GetConst(a0), nil
+}
+
+// adaptCOS 调用 COS(Series)
+func adaptCOS(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("COS", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("COS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return COS(a0), nil
+}
+
+// adaptCOUNT 调用 COUNT([]bool, int, ...int)
+func adaptCOUNT(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("COUNT", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("COUNT", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("COUNT", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("COUNT", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return COUNT(a0, a1, opt...), nil
+}
+
+// adaptCR 调用 CR(Series, Series, Series, int)
+func adaptCR(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("CR", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("CR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("CR", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("CR", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	return CR(a0, a1, a2, a3), nil
+}
+
+// adaptCROSS 调用 CROSS(Series, Series)
+func adaptCROSS(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("CROSS", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CROSS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("CROSS", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return CROSS(a0, a1), nil
+}
+
+// adaptCROSSDOWN 调用 CROSSDOWN(Series, Series)
+func adaptCROSSDOWN(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("CROSSDOWN", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CROSSDOWN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("CROSSDOWN", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return CROSSDOWN(a0, a1), nil
+}
+
+// adaptCURRBARSCOUNT 调用 CURRBARSCOUNT(Series)
+func adaptCURRBARSCOUNT(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("CURRBARSCOUNT", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("CURRBARSCOUNT", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return CURRBARSCOUNT(a0), nil
+}
+
+// adaptDFMA 调用 DFMA(Series, int, int, int)
+func adaptDFMA(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("DFMA", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DFMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("DFMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("DFMA", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("DFMA", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := DFMA(a0, a1, a2, a3)
+	return []any{r0, r1}, nil
+}
+
+// adaptDIFF 调用 DIFF(Series, int)
+func adaptDIFF(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("DIFF", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DIFF", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("DIFF", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return DIFF(a0, a1), nil
+}
+
+// adaptDIV 调用 DIV(Series, Series)
+func adaptDIV(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("DIV", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DIV", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("DIV", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return DIV(a0, a1), nil
+}
+
+// adaptDMA 调用 DMA(Series, float64)
+func adaptDMA(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("DMA", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("DMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return DMA(a0, a1), nil
+}
+
+// adaptDMI 调用 DMI(Series, Series, Series, int, int)
+func adaptDMI(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("DMI", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DMI", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("DMI", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("DMI", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("DMI", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("DMI", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2, r3 := DMI(a0, a1, a2, a3, a4)
+	return []any{r0, r1, r2, r3}, nil
+}
+
+// adaptDPO 调用 DPO(Series, int, int, int)
+func adaptDPO(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("DPO", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DPO", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("DPO", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("DPO", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("DPO", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := DPO(a0, a1, a2, a3)
+	return []any{r0, r1}, nil
+}
+
+// adaptDTPRICE 调用 DTPRICE(Series, float64)
+func adaptDTPRICE(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("DTPRICE", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("DTPRICE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("DTPRICE", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return DTPRICE(a0, a1), nil
+}
+
+// adaptEMA 调用 EMA(Series, int)
+func adaptEMA(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("EMA", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("EMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("EMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return EMA(a0, a1), nil
+}
+
+// adaptEMV 调用 EMV(Series, Series, Series, int, int)
+func adaptEMV(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("EMV", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("EMV", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("EMV", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("EMV", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("EMV", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("EMV", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := EMV(a0, a1, a2, a3, a4)
+	return []any{r0, r1}, nil
+}
+
+// adaptEVERY 调用 EVERY([]bool, int)
+func adaptEVERY(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("EVERY", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("EVERY", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("EVERY", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return EVERY(a0, a1), nil
+}
+
+// adaptEXIST 调用 EXIST([]bool, int)
+func adaptEXIST(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("EXIST", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("EXIST", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("EXIST", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return EXIST(a0, a1), nil
+}
+
+// adaptEXP 调用 EXP(Series)
+func adaptEXP(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("EXP", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("EXP", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return EXP(a0), nil
+}
+
+// adaptEXPMA 调用 EXPMA(Series, int, int)
+func adaptEXPMA(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("EXPMA", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("EXPMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("EXPMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("EXPMA", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := EXPMA(a0, a1, a2)
+	return []any{r0, r1}, nil
+}
+
+// adaptEqual 调用 Equal(Series, Series)
+func adaptEqual(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("Equal", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("Equal", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("Equal", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return Equal(a0, a1), nil
+}
+
+// adaptFILTER 调用 FILTER([]bool, int)
+func adaptFILTER(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("FILTER", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("FILTER", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("FILTER", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return FILTER(a0, a1), nil
+}
+
+// adaptFLOOR 调用 FLOOR(Series)
+func adaptFLOOR(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("FLOOR", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("FLOOR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return FLOOR(a0), nil
+}
+
+// adaptFORCAST 调用 FORCAST(Series, int, ...int)
+func adaptFORCAST(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("FORCAST", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("FORCAST", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("FORCAST", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("FORCAST", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return FORCAST(a0, a1, opt...), nil
+}
+
+// adaptGreaterThan 调用 GreaterThan(Series, Series)
+func adaptGreaterThan(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("GreaterThan", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("GreaterThan", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("GreaterThan", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return GreaterThan(a0, a1), nil
+}
+
+// adaptGreaterThanOrEqual 调用 GreaterThanOrEqual(Series, Series)
+func adaptGreaterThanOrEqual(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("GreaterThanOrEqual", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("GreaterThanOrEqual", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("GreaterThanOrEqual", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return GreaterThanOrEqual(a0, a1), nil
+}
+
+// adaptHHV 调用 HHV(Series, int, ...int)
+func adaptHHV(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("HHV", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("HHV", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("HHV", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("HHV", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return HHV(a0, a1, opt...), nil
+}
+
+// adaptHHVBARS 调用 HHVBARS(Series, int)
+func adaptHHVBARS(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("HHVBARS", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("HHVBARS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("HHVBARS", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return HHVBARS(a0, a1), nil
+}
+
+// adaptHOD 调用 HOD(Series, int)
+func adaptHOD(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("HOD", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("HOD", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("HOD", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return HOD(a0, a1), nil
+}
+
+// adaptIF 调用 IF([]bool, Series, Series)
+func adaptIF(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("IF", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("IF", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("IF", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("IF", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	return IF(a0, a1, a2), nil
+}
+
+// adaptINTPART 调用 INTPART(Series)
+func adaptINTPART(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("INTPART", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("INTPART", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return INTPART(a0), nil
+}
+
+// adaptKDJ 调用 KDJ(Series, Series, Series, int, int, int)
+func adaptKDJ(args []any) (any, error) {
+	if len(args) != 6 {
+		return nil, argCountError("KDJ", 6, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("KDJ", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("KDJ", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("KDJ", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("KDJ", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("KDJ", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	a5, err := argInt("KDJ", 5, args[5])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2 := KDJ(a0, a1, a2, a3, a4, a5)
+	return []any{r0, r1, r2}, nil
+}
+
+// adaptKTN 调用 KTN(Series, Series, Series, int, int)
+func adaptKTN(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("KTN", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("KTN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("KTN", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("KTN", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("KTN", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("KTN", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2 := KTN(a0, a1, a2, a3, a4)
+	return []any{r0, r1, r2}, nil
+}
+
+// adaptLAST 调用 LAST([]bool, int, int)
+func adaptLAST(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("LAST", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("LAST", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("LAST", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("LAST", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	return LAST(a0, a1, a2), nil
+}
+
+// adaptLLV 调用 LLV(Series, int, ...int)
+func adaptLLV(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("LLV", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LLV", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("LLV", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("LLV", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return LLV(a0, a1, opt...), nil
+}
+
+// adaptLLVBARS 调用 LLVBARS(Series, int)
+func adaptLLVBARS(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("LLVBARS", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LLVBARS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("LLVBARS", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return LLVBARS(a0, a1), nil
+}
+
+// adaptLN 调用 LN(Series)
+func adaptLN(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("LN", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return LN(a0), nil
+}
+
+// adaptLOD 调用 LOD(Series, int)
+func adaptLOD(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("LOD", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LOD", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("LOD", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return LOD(a0, a1), nil
+}
+
+// adaptLOG 调用 LOG(Series)
+func adaptLOG(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("LOG", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LOG", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return LOG(a0), nil
+}
+
+// adaptLON 调用 LON(Series, Series, Series, Series)
+func adaptLON(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("LON", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LON", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("LON", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("LON", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argSeries("LON", 3, args[3], n)
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := LON(a0, a1, a2, a3)
+	return []any{r0, r1}, nil
+}
+
+// adaptLONGCROSS 调用 LONGCROSS(Series, Series, int)
+func adaptLONGCROSS(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("LONGCROSS", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LONGCROSS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("LONGCROSS", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("LONGCROSS", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	return LONGCROSS(a0, a1, a2), nil
+}
+
+// adaptLOWRANGE 调用 LOWRANGE(Series)
+func adaptLOWRANGE(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("LOWRANGE", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LOWRANGE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return LOWRANGE(a0), nil
+}
+
+// adaptLessThan 调用 LessThan(Series, Series)
+func adaptLessThan(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("LessThan", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LessThan", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("LessThan", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return LessThan(a0, a1), nil
+}
+
+// adaptLessThanOrEqual 调用 LessThanOrEqual(Series, Series)
+func adaptLessThanOrEqual(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("LessThanOrEqual", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("LessThanOrEqual", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("LessThanOrEqual", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return LessThanOrEqual(a0, a1), nil
+}
+
+// adaptMA 调用 MA(Series, int, ...int)
+func adaptMA(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("MA", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("MA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("MA", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return MA(a0, a1, opt...), nil
+}
+
+// adaptMACD 调用 MACD(Series, int, int, int)
+func adaptMACD(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("MACD", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MACD", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("MACD", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("MACD", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("MACD", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2 := MACD(a0, a1, a2, a3)
+	return []any{r0, r1, r2}, nil
+}
+
+// adaptMASS 调用 MASS(Series, Series, int, int, int)
+func adaptMASS(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("MASS", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MASS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("MASS", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("MASS", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("MASS", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("MASS", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := MASS(a0, a1, a2, a3, a4)
+	return []any{r0, r1}, nil
+}
+
+// adaptMAX 调用 MAX(Series, Series)
+func adaptMAX(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("MAX", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MAX", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("MAX", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return MAX(a0, a1), nil
+}
+
+// adaptMEMA 调用 MEMA(Series, int)
+func adaptMEMA(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("MEMA", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MEMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("MEMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return MEMA(a0, a1), nil
+}
+
+// adaptMFI 调用 MFI(Series, Series, Series, Series, int)
+func adaptMFI(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("MFI", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MFI", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("MFI", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("MFI", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argSeries("MFI", 3, args[3], n)
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("MFI", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	return MFI(a0, a1, a2, a3, a4), nil
+}
+
+// adaptMIN 调用 MIN(Series, Series)
+func adaptMIN(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("MIN", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MIN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("MIN", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return MIN(a0, a1), nil
+}
+
+// adaptMOD 调用 MOD(Series, Series)
+func adaptMOD(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("MOD", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MOD", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("MOD", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return MOD(a0, a1), nil
+}
+
+// adaptMTM 调用 MTM(Series, int, int)
+func adaptMTM(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("MTM", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MTM", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("MTM", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("MTM", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := MTM(a0, a1, a2)
+	return []any{r0, r1}, nil
+}
+
+// adaptMUL 调用 MUL(Series, Series)
+func adaptMUL(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("MUL", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("MUL", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("MUL", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return MUL(a0, a1), nil
+}
+
+// adaptNotEqual 调用 NotEqual(Series, Series)
+func adaptNotEqual(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("NotEqual", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("NotEqual", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("NotEqual", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return NotEqual(a0, a1), nil
+}
+
+// adaptOBV 调用 OBV(Series, Series)
+func adaptOBV(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("OBV", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("OBV", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("OBV", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return OBV(a0, a1), nil
+}
+
+// adaptPEAK 调用 PEAK(Series, float64, int)
+func adaptPEAK(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("PEAK", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("PEAK", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("PEAK", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("PEAK", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	return PEAK(a0, a1, a2), nil
+}
+
+// adaptPOW 调用 POW(Series, float64)
+func adaptPOW(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("POW", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("POW", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("POW", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return POW(a0, a1), nil
+}
+
+// adaptPSY 调用 PSY(Series, int, int)
+func adaptPSY(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("PSY", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("PSY", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("PSY", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("PSY", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := PSY(a0, a1, a2)
+	return []any{r0, r1}, nil
+}
+
+// adaptQRR 调用 QRR(Series)
+func adaptQRR(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("QRR", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("QRR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return QRR(a0), nil
+}
+
+// adaptRANGE 调用 RANGE(Series, Series, Series)
+func adaptRANGE(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("RANGE", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("RANGE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("RANGE", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("RANGE", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	return RANGE(a0, a1, a2), nil
+}
+
+// adaptRD 调用 RD(float64, int)
+func adaptRD(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("RD", 2, len(args))
+	}
+	a0, err := argFloat("RD", 0, args[0])
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("RD", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return RD(a0, a1), nil
+}
+
+// adaptREF 调用 REF(Series, any)
+func adaptREF(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("REF", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("REF", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argAny("REF", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return REF(a0, a1), nil
+}
+
+// adaptREFX 调用 REFX(Series, any)
+func adaptREFX(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("REFX", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("REFX", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argAny("REFX", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return REFX(a0, a1), nil
+}
+
+// adaptRET 调用 RET(Series, int)
+func adaptRET(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("RET", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("RET", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("RET", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return RET(a0, a1), nil
+}
+
+// adaptREVERSE 调用 REVERSE(Series)
+func adaptREVERSE(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("REVERSE", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("REVERSE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return REVERSE(a0), nil
+}
+
+// adaptROC 调用 ROC(Series, int, int)
+func adaptROC(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("ROC", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ROC", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("ROC", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("ROC", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := ROC(a0, a1, a2)
+	return []any{r0, r1}, nil
+}
+
+// adaptRSI 调用 RSI(Series, int)
+func adaptRSI(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("RSI", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("RSI", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("RSI", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return RSI(a0, a1), nil
+}
+
+// adaptSAR 调用 SAR(Series, Series, int, int, int)
+func adaptSAR(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("SAR", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SAR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("SAR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("SAR", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("SAR", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("SAR", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	return SAR(a0, a1, a2, a3, a4), nil
+}
+
+// adaptSHO 调用 SHO(Series, Series, int)
+func adaptSHO(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("SHO", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SHO", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("SHO", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("SHO", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := SHO(a0, a1, a2)
+	return []any{r0, r1}, nil
+}
+
+// adaptSIGN 调用 SIGN(Series)
+func adaptSIGN(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("SIGN", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SIGN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return SIGN(a0), nil
+}
+
+// adaptSIN 调用 SIN(Series)
+func adaptSIN(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("SIN", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SIN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return SIN(a0), nil
+}
+
+// adaptSLOPE 调用 SLOPE(Series, int, ...int)
+func adaptSLOPE(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("SLOPE", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SLOPE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("SLOPE", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("SLOPE", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return SLOPE(a0, a1, opt...), nil
+}
+
+// adaptSMA 调用 SMA(Series, int, float64)
+func adaptSMA(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("SMA", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("SMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argFloat("SMA", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	return SMA(a0, a1, a2), nil
+}
+
+// adaptSQRT 调用 SQRT(Series)
+func adaptSQRT(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("SQRT", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SQRT", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return SQRT(a0), nil
+}
+
+// adaptSTD 调用 STD(Series, int, ...int)
+func adaptSTD(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("STD", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("STD", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("STD", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("STD", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return STD(a0, a1, opt...), nil
+}
+
+// adaptSUB 调用 SUB(Series, Series)
+func adaptSUB(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("SUB", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SUB", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("SUB", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return SUB(a0, a1), nil
+}
+
+// adaptSUM 调用 SUM(Series, int, ...int)
+func adaptSUM(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("SUM", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SUM", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("SUM", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("SUM", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return SUM(a0, a1, opt...), nil
+}
+
+// adaptSUMBARS 调用 SUMBARS(Series, Series)
+func adaptSUMBARS(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("SUMBARS", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("SUMBARS", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("SUMBARS", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return SUMBARS(a0, a1), nil
+}
+
+// adaptTAN 调用 TAN(Series)
+func adaptTAN(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("TAN", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("TAN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return TAN(a0), nil
+}
+
+// adaptTAQ 调用 TAQ(Series, Series, int)
+func adaptTAQ(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("TAQ", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("TAQ", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("TAQ", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("TAQ", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2 := TAQ(a0, a1, a2)
+	return []any{r0, r1, r2}, nil
+}
+
+// adaptTDX_SAR 调用 TDX_SAR(Series, Series, int, int)
+func adaptTDX_SAR(args []any) (any, error) {
+	if len(args) != 4 {
+		return nil, argCountError("TDX_SAR", 4, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("TDX_SAR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("TDX_SAR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("TDX_SAR", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("TDX_SAR", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	return TDX_SAR(a0, a1, a2, a3), nil
+}
+
+// adaptTOPRANGE 调用 TOPRANGE(Series)
+func adaptTOPRANGE(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, argCountError("TOPRANGE", 1, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("TOPRANGE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	return TOPRANGE(a0), nil
+}
+
+// adaptTRIX 调用 TRIX(Series, int, int)
+func adaptTRIX(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("TRIX", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("TRIX", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("TRIX", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("TRIX", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := TRIX(a0, a1, a2)
+	return []any{r0, r1}, nil
+}
+
+// adaptTROUGH 调用 TROUGH(Series, float64, int)
+func adaptTROUGH(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("TROUGH", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("TROUGH", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("TROUGH", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("TROUGH", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	return TROUGH(a0, a1, a2), nil
+}
+
+// adaptVALUEWHEN 调用 VALUEWHEN([]bool, Series)
+func adaptVALUEWHEN(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("VALUEWHEN", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argBools("VALUEWHEN", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("VALUEWHEN", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	return VALUEWHEN(a0, a1), nil
+}
+
+// adaptVR 调用 VR(Series, Series, int)
+func adaptVR(args []any) (any, error) {
+	if len(args) != 3 {
+		return nil, argCountError("VR", 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("VR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("VR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argInt("VR", 2, args[2])
+	if err != nil {
+		return nil, err
+	}
+	return VR(a0, a1, a2), nil
+}
+
+// adaptWMA 调用 WMA(Series, int, ...int)
+func adaptWMA(args []any) (any, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, argRangeError("WMA", 2, 3, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("WMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("WMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	opt, err := argOptInt("WMA", 2, args)
+	if err != nil {
+		return nil, err
+	}
+	return WMA(a0, a1, opt...), nil
+}
+
+// adaptWR 调用 WR(Series, Series, Series, int, int)
+func adaptWR(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("WR", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("WR", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("WR", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("WR", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("WR", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argInt("WR", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1 := WR(a0, a1, a2, a3, a4)
+	return []any{r0, r1}, nil
+}
+
+// adaptXMA 调用 XMA(Series, int)
+func adaptXMA(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("XMA", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("XMA", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argInt("XMA", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return XMA(a0, a1), nil
+}
+
+// adaptXSII 调用 XSII(Series, Series, Series, int, float64)
+func adaptXSII(args []any) (any, error) {
+	if len(args) != 5 {
+		return nil, argCountError("XSII", 5, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("XSII", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argSeries("XSII", 1, args[1], n)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := argSeries("XSII", 2, args[2], n)
+	if err != nil {
+		return nil, err
+	}
+	a3, err := argInt("XSII", 3, args[3])
+	if err != nil {
+		return nil, err
+	}
+	a4, err := argFloat("XSII", 4, args[4])
+	if err != nil {
+		return nil, err
+	}
+	r0, r1, r2, r3 := XSII(a0, a1, a2, a3, a4)
+	return []any{r0, r1, r2, r3}, nil
+}
+
+// adaptZIG 调用 ZIG(Series, float64)
+func adaptZIG(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("ZIG", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ZIG", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("ZIG", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return ZIG(a0, a1), nil
+}
+
+// adaptZTPRICE 调用 ZTPRICE(Series, float64)
+func adaptZTPRICE(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, argCountError("ZTPRICE", 2, len(args))
+	}
+	n := broadcastLen(args)
+	a0, err := argSeries("ZTPRICE", 0, args[0], n)
+	if err != nil {
+		return nil, err
+	}
+	a1, err := argFloat("ZTPRICE", 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return ZTPRICE(a0, a1), nil
+}
@@ -0,0 +1,123 @@
+package indicators
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAdapterMatchesReflection 生成的适配器与反射调用结果一致
+func TestAdapterMatchesReflection(t *testing.T) {
+	close := NewSeries([]float64{100, 102, 101, 103, 105, 104, 106, 108, 107, 109})
+	high := NewSeries([]float64{101, 103, 102, 104, 106, 105, 107, 109, 108, 110})
+	low := NewSeries([]float64{99, 101, 100, 102, 104, 103, 105, 107, 106, 108})
+
+	cases := []struct {
+		name string
+		args []any
+	}{
+		{"MA", []any{close, 5.0}},
+		{"SMA", []any{close, 5, 1.0}},
+		{"REF", []any{close, 1.0}},
+		{"MAX", []any{close, 0.0}},
+		{"IF", []any{true, close, 0.0}},
+		{"IF", []any{[]float64{1, 0, 1, 0, 1, 0, 1, 0, 1, 0}, high, low}},
+		{"COUNT", []any{[]float64{1, 0, 1, 0, 1, 0, 1, 0, 1, 0}, 3.0}},
+		{"MACD", []any{close, 3.0, 6.0, 2.0}},
+		{"KDJ", []any{close, high, low, 9.0, 3.0, 3.0}},
+		{"RD", []any{3.14159, 2.0}},
+	}
+	for _, tc := range cases {
+		want, err := CallIndicatorByReflection(tc.name, tc.args)
+		if err != nil {
+			t.Fatalf("%s reflection error: %v", tc.name, err)
+		}
+		got, err := CallIndicator(tc.name, tc.args)
+		if err != nil {
+			t.Fatalf("%s adapter error: %v", tc.name, err)
+		}
+		if !equalWithNaN(got, want) {
+			t.Errorf("%s: adapter = %v, reflection = %v", tc.name, got, want)
+		}
+	}
+}
+
+func TestAdapterErrors(t *testing.T) {
+	close := NewSeries([]float64{1, 2, 3})
+
+	_, err := CallIndicator("MA", []any{close, "invalid"})
+	var argErr *ArgError
+	if !errors.As(err, &argErr) {
+		t.Fatalf("MA with string should return *ArgError, got %v", err)
+	}
+	if argErr.Func != "MA" || argErr.Index != 1 || argErr.Want != "int" {
+		t.Errorf("unexpected ArgError: %+v", argErr)
+	}
+
+	if _, err := CallIndicator("ABS", []any{"CLOSE"}); err == nil || !strings.Contains(err.Error(), `string "CLOSE"`) {
+		t.Errorf("ABS with string should report the value, got %v", err)
+	}
+	if _, err := CallIndicator("REF", []any{close, nil}); err == nil {
+		t.Errorf("REF with nil should fail")
+	}
+	if _, err := CallIndicator("EMA", []any{close}); err == nil || !strings.Contains(err.Error(), "expected 2, got 1") {
+		t.Errorf("EMA with one argument should report argument count, got %v", err)
+	}
+	if _, err := CallIndicator("MA", []any{close, 3.0, 2.0, 1.0}); err == nil || !strings.Contains(err.Error(), "expected 2 to 3, got 4") {
+		t.Errorf("MA with four arguments should report argument range, got %v", err)
+	}
+	if _, err := CallIndicator("NOTEXIST", []any{close}); err == nil {
+		t.Errorf("unknown function should fail")
+	}
+}
+
+func TestRegisterIndicatorFallsBackToReflection(t *testing.T) {
+	double := func(S Series, k float64) Series { return MULS(S, 2*k) }
+	if err := RegisterIndicator("TEST_DOUBLE", double); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(functionMap, "TEST_DOUBLE")
+
+	if _, ok := GetAdapter("TEST_DOUBLE"); ok {
+		t.Errorf("user function should not have a generated adapter")
+	}
+	got, err := CallIndicator("TEST_DOUBLE", []any{[]float64{1, 2}, 1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, Series{2, 4}) {
+		t.Errorf("TEST_DOUBLE = %v", got)
+	}
+	if err := RegisterIndicator("BAD", 1); err == nil {
+		t.Errorf("registering a non-function should fail")
+	}
+}
+
+// equalWithNaN 比较两个结果，NaN 视为相等
+func equalWithNaN(a, b any) bool {
+	return reflect.DeepEqual(normalizeNaN(a), normalizeNaN(b))
+}
+
+func normalizeNaN(v any) any {
+	switch x := v.(type) {
+	case Series:
+		out := make([]any, len(x))
+		for i, f := range x {
+			if f != f {
+				out[i] = "NaN"
+			} else {
+				out[i] = f
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = normalizeNaN(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
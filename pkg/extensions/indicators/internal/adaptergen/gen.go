@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// registryName 指标函数注册表的变量名
+const registryName = "functionMap"
+
+// paramKind 参数类型对应的转换函数，isSlice 表示需要按最长序列广播
+var paramKinds = map[string]struct {
+	conv    string
+	isSlice bool
+}{
+	"Series":      {"argSeries", true},
+	"[]float64":   {"argFloats", true},
+	"[]bool":      {"argBools", true},
+	"int":         {"argInt", false},
+	"float64":     {"argFloat", false},
+	"any":         {"argAny", false},
+	"interface{}": {"argAny", false},
+}
+
+// variadicKinds 支持的可变参数类型，最多接收一个可选参数
+var variadicKinds = map[string]string{
+	"int": "argOptInt",
+}
+
+type adapter struct {
+	Name     string   // 注册名，如 MA
+	Func     string   // Go 函数名，如 MA、GetConst
+	Params   []string // 参数类型
+	Variadic string   // 末尾可变参数的元素类型，为空表示没有
+	Results  int      // 返回值个数
+}
+
+// Generate 解析 dir 下的 Go 源文件，为注册到 functionMap 的每个函数生成适配器代码
+// 返回生成的代码和因签名不支持而跳过的函数说明
+func Generate(dir, outName string) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == outName {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, f)
+	}
+
+	funcs := map[string]*ast.FuncDecl{}
+	registry := map[string]string{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil {
+				funcs[fd.Name.Name] = fd
+			}
+		}
+		collectRegistry(f, registry)
+	}
+	if len(registry) == 0 {
+		return nil, nil, fmt.Errorf("no %s registrations found in %s", registryName, dir)
+	}
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var adapters []adapter
+	var skipped []string
+	for _, name := range names {
+		fn := registry[name]
+		fd, ok := funcs[fn]
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("%s: function %s not found", name, fn))
+			continue
+		}
+		a, err := buildAdapter(fset, name, fd)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		adapters = append(adapters, a)
+	}
+
+	code, err := render(adapters)
+	return code, skipped, err
+}
+
+// collectRegistry 收集 functionMap = map[string]any{...} 和 functionMap["X"] = X 两种注册方式
+func collectRegistry(f *ast.File, registry map[string]string) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		as, ok := n.(*ast.AssignStmt)
+		if !ok || len(as.Lhs) != len(as.Rhs) {
+			return true
+		}
+		for i, lhs := range as.Lhs {
+			switch l := lhs.(type) {
+			case *ast.Ident:
+				if l.Name != registryName {
+					continue
+				}
+				lit, ok := as.Rhs[i].(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				for _, elt := range lit.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					addRegistration(registry, kv.Key, kv.Value)
+				}
+			case *ast.IndexExpr:
+				if id, ok := l.X.(*ast.Ident); ok && id.Name == registryName {
+					addRegistration(registry, l.Index, as.Rhs[i])
+				}
+			}
+		}
+		return true
+	})
+}
+
+func addRegistration(registry map[string]string, key, value ast.Expr) {
+	lit, ok := key.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	fn, ok := value.(*ast.Ident)
+	if !ok {
+		return
+	}
+	name, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	registry[name] = fn.Name
+}
+
+func buildAdapter(fset *token.FileSet, name string, fd *ast.FuncDecl) (adapter, error) {
+	a := adapter{Name: name, Func: fd.Name.Name}
+	if fd.Type.TypeParams != nil {
+		return a, fmt.Errorf("generic function is not supported")
+	}
+	for _, field := range fd.Type.Params.List {
+		if ell, ok := field.Type.(*ast.Ellipsis); ok {
+			elt := exprString(fset, ell.Elt)
+			if _, ok := variadicKinds[elt]; !ok {
+				return a, fmt.Errorf("unsupported variadic parameter type ...%s", elt)
+			}
+			a.Variadic = elt
+			continue
+		}
+		typ := exprString(fset, field.Type)
+		if _, ok := paramKinds[typ]; !ok {
+			return a, fmt.Errorf("unsupported parameter type %s", typ)
+		}
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			a.Params = append(a.Params, typ)
+		}
+	}
+	if fd.Type.Results != nil {
+		for _, field := range fd.Type.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+			a.Results += count
+		}
+	}
+	return a, nil
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+func render(adapters []adapter) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by adaptergen. DO NOT EDIT.\n\n")
+	b.WriteString("package indicators\n\n")
+	b.WriteString("// adapterMap 内置指标函数的类型化适配器，key 与 functionMap 一致\n")
+	b.WriteString("var adapterMap = map[string]Adapter{\n")
+	for _, a := range adapters {
+		fmt.Fprintf(&b, "%q: adapt%s,\n", a.Name, a.Name)
+	}
+	b.WriteString("}\n")
+
+	for _, a := range adapters {
+		fmt.Fprintf(&b, "\n// adapt%s 调用 %s\n", a.Name, signature(a))
+		fmt.Fprintf(&b, "func adapt%s(args []any) (any, error) {\n", a.Name)
+		if a.Variadic != "" {
+			fmt.Fprintf(&b, "if len(args) < %d || len(args) > %d {\nreturn nil, argRangeError(%q, %d, %d, len(args))\n}\n", len(a.Params), len(a.Params)+1, a.Name, len(a.Params), len(a.Params)+1)
+		} else {
+			fmt.Fprintf(&b, "if len(args) != %d {\nreturn nil, argCountError(%q, %d, len(args))\n}\n", len(a.Params), a.Name, len(a.Params))
+		}
+		needLen := false
+		for _, p := range a.Params {
+			if paramKinds[p].isSlice {
+				needLen = true
+			}
+		}
+		if needLen {
+			b.WriteString("n := broadcastLen(args)\n")
+		}
+		var callArgs []string
+		for i, p := range a.Params {
+			kind := paramKinds[p]
+			if kind.isSlice {
+				fmt.Fprintf(&b, "a%d, err := %s(%q, %d, args[%d], n)\n", i, kind.conv, a.Name, i, i)
+			} else {
+				fmt.Fprintf(&b, "a%d, err := %s(%q, %d, args[%d])\n", i, kind.conv, a.Name, i, i)
+			}
+			b.WriteString("if err != nil {\nreturn nil, err\n}\n")
+			callArgs = append(callArgs, fmt.Sprintf("a%d", i))
+		}
+		if a.Variadic != "" {
+			fmt.Fprintf(&b, "opt, err := %s(%q, %d, args)\n", variadicKinds[a.Variadic], a.Name, len(a.Params))
+			b.WriteString("if err != nil {\nreturn nil, err\n}\n")
+			callArgs = append(callArgs, "opt...")
+		}
+		call := fmt.Sprintf("%s(%s)", a.Func, strings.Join(callArgs, ", "))
+		switch a.Results {
+		case 0:
+			fmt.Fprintf(&b, "%s\nreturn nil, nil\n", call)
+		case 1:
+			fmt.Fprintf(&b, "return %s, nil\n", call)
+		default:
+			var rs []string
+			for i := 0; i < a.Results; i++ {
+				rs = append(rs, fmt.Sprintf("r%d", i))
+			}
+			fmt.Fprintf(&b, "%s := %s\n", strings.Join(rs, ", "), call)
+			fmt.Fprintf(&b, "return []any{%s}, nil\n", strings.Join(rs, ", "))
+		}
+		b.WriteString("}\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+func signature(a adapter) string {
+	params := a.Params
+	if a.Variadic != "" {
+		params = append(params[:len(params):len(params)], "..."+a.Variadic)
+	}
+	return fmt.Sprintf("%s(%s)", a.Func, strings.Join(params, ", "))
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedFileUpToDate 确保 adapters_gen.go 与 MyTT.go 中的签名同步
+func TestGeneratedFileUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..")
+	code, skipped, err := Generate(dir, "adapters_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) > 0 {
+		t.Logf("skipped: %v", skipped)
+	}
+	current, err := os.ReadFile(filepath.Join(dir, "adapters_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, current) {
+		t.Errorf("adapters_gen.go is out of date, run go generate ./pkg/extensions/indicators")
+	}
+}
@@ -0,0 +1,32 @@
+// adaptergen 根据 indicators 包中注册到 functionMap 的函数签名，生成类型化的调用适配器。
+//
+// 用法（在 indicators 目录下）：
+//
+//	go generate ./...
+//	go run ./internal/adaptergen -dir . -out adapters_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "indicators 包所在目录")
+	out := flag.String("out", "adapters_gen.go", "生成文件的名称（相对于 dir）")
+	flag.Parse()
+
+	code, skipped, err := Generate(*dir, *out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(os.Stderr, "adaptergen: skip %s\n", s)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
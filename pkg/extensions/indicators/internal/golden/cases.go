@@ -0,0 +1,193 @@
+package golden
+
+import (
+	"fmt"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+// Column 引用行情中的一列或派生序列作为参数
+type Column string
+
+// 可用的参数列
+const (
+	O   Column = "OPEN"
+	H   Column = "HIGH"
+	L   Column = "LOW"
+	C   Column = "CLOSE"
+	V   Column = "VOL"
+	UP  Column = "UP"   // 收阳：CLOSE>OPEN，条件参数
+	M5  Column = "MA5"  // MA(CLOSE,5)
+	M10 Column = "MA10" // MA(CLOSE,10)
+)
+
+// Case 一个函数的 golden 用例，参数取 MyTT 的默认值
+type Case struct {
+	Name    string   // functionMap 中的函数名
+	Args    []any    // Column 或数值参数
+	Outputs []string // 输出列名，为空时按 value、value2… 命名
+}
+
+// Cases 返回所有 golden 用例，每个 functionMap 函数一个
+func Cases() []Case {
+	return cases
+}
+
+var cases = []Case{
+	{Name: "ABS", Args: []any{C}},
+	{Name: "ADD", Args: []any{C, O}},
+	{Name: "ASI", Args: []any{O, C, H, L, 26, 10}, Outputs: []string{"ASI", "ASIT"}},
+	{Name: "ATR", Args: []any{C, H, L, 20}},
+	{Name: "AVEDEV", Args: []any{C, 10}},
+	{Name: "BARSLAST", Args: []any{UP}},
+	{Name: "BARSSINCEN", Args: []any{UP, 10}},
+	{Name: "BBI", Args: []any{C, 3, 6, 12, 20}},
+	{Name: "BETWEEN", Args: []any{C, L, H}},
+	{Name: "BIAS", Args: []any{C, 6, 12, 24}, Outputs: []string{"BIAS1", "BIAS2", "BIAS3"}},
+	{Name: "BOLL", Args: []any{C, 20, 2.0}, Outputs: []string{"UPPER", "MID", "LOWER"}},
+	{Name: "BRAR", Args: []any{O, C, H, L, 26}, Outputs: []string{"AR", "BR"}},
+	{Name: "CCI", Args: []any{C, H, L, 14}},
+	{Name: "CONST", Args: []any{C}},
+	{Name: "COS", Args: []any{C}},
+	{Name: "COUNT", Args: []any{UP, 10}},
+	{Name: "CR", Args: []any{C, H, L, 20}},
+	{Name: "CROSS", Args: []any{M5, M10}},
+	{Name: "DFMA", Args: []any{C, 10, 50, 10}, Outputs: []string{"DIF", "DIFMA"}},
+	{Name: "DIFF", Args: []any{C, 1}},
+	{Name: "DIV", Args: []any{C, O}},
+	{Name: "DMA", Args: []any{C, 0.2}},
+	{Name: "DMI", Args: []any{C, H, L, 14, 6}, Outputs: []string{"PDI", "MDI", "ADX", "ADXR"}},
+	{Name: "DPO", Args: []any{C, 20, 10, 6}, Outputs: []string{"DPO", "MADPO"}},
+	{Name: "DTPRICE", Args: []any{C, 0.1}},
+	{Name: "EMA", Args: []any{C, 12}},
+	{Name: "EMV", Args: []any{H, L, V, 14, 9}, Outputs: []string{"EMV", "MAEMV"}},
+	{Name: "EVERY", Args: []any{UP, 3}},
+	{Name: "EXIST", Args: []any{UP, 5}},
+	{Name: "EXPMA", Args: []any{C, 12, 50}, Outputs: []string{"EXP1", "EXP2"}},
+	{Name: "Equal", Args: []any{C, O}},
+	{Name: "FILTER", Args: []any{UP, 5}},
+	{Name: "FORCAST", Args: []any{C, 10}},
+	{Name: "GreaterThan", Args: []any{C, O}},
+	{Name: "GreaterThanOrEqual", Args: []any{C, O}},
+	{Name: "HHV", Args: []any{H, 20}},
+	{Name: "HHVBARS", Args: []any{H, 20}},
+	{Name: "IF", Args: []any{UP, H, L}},
+	{Name: "KDJ", Args: []any{C, H, L, 9, 3, 3}, Outputs: []string{"K", "D", "J"}},
+	{Name: "KTN", Args: []any{C, H, L, 20, 10}, Outputs: []string{"UPPER", "MID", "LOWER"}},
+	{Name: "LAST", Args: []any{UP, 5, 2}},
+	{Name: "LLV", Args: []any{L, 20}},
+	{Name: "LLVBARS", Args: []any{L, 20}},
+	{Name: "LN", Args: []any{C}},
+	{Name: "LON", Args: []any{C, H, L, V}, Outputs: []string{"LON", "LONMA"}},
+	{Name: "LONGCROSS", Args: []any{M5, M10, 5}},
+	{Name: "LOWRANGE", Args: []any{L}},
+	{Name: "LessThan", Args: []any{C, O}},
+	{Name: "LessThanOrEqual", Args: []any{C, O}},
+	{Name: "MA", Args: []any{C, 5}},
+	{Name: "MACD", Args: []any{C, 12, 26, 9}, Outputs: []string{"DIF", "DEA", "MACD"}},
+	{Name: "MASS", Args: []any{H, L, 9, 25, 6}, Outputs: []string{"MASS", "MA_MASS"}},
+	{Name: "MAX", Args: []any{C, O}},
+	{Name: "MFI", Args: []any{C, H, L, V, 14}},
+	{Name: "MIN", Args: []any{C, O}},
+	{Name: "MTM", Args: []any{C, 12, 6}, Outputs: []string{"MTM", "MTMMA"}},
+	{Name: "MUL", Args: []any{C, O}},
+	{Name: "NotEqual", Args: []any{C, O}},
+	{Name: "OBV", Args: []any{C, V}},
+	{Name: "POW", Args: []any{C, 2.0}},
+	{Name: "PSY", Args: []any{C, 12, 6}, Outputs: []string{"PSY", "PSYMA"}},
+	{Name: "QRR", Args: []any{V}},
+	{Name: "RD", Args: []any{3.14159, 2}},
+	{Name: "REF", Args: []any{C, 1}},
+	{Name: "RET", Args: []any{C, 1}},
+	{Name: "ROC", Args: []any{C, 12, 6}, Outputs: []string{"ROC", "MAROC"}},
+	{Name: "RSI", Args: []any{C, 24}},
+	{Name: "SAR", Args: []any{H, L, 10, 2, 20}},
+	{Name: "SHO", Args: []any{C, V, 5}, Outputs: []string{"SHT", "SHTMA"}},
+	{Name: "SIN", Args: []any{C}},
+	{Name: "SLOPE", Args: []any{C, 10}},
+	{Name: "SMA", Args: []any{C, 10, 1.0}},
+	{Name: "SQRT", Args: []any{C}},
+	{Name: "STD", Args: []any{C, 20}},
+	{Name: "SUB", Args: []any{C, O}},
+	{Name: "SUM", Args: []any{C, 10}},
+	{Name: "TAN", Args: []any{C}},
+	{Name: "TAQ", Args: []any{H, L, 20}, Outputs: []string{"UP", "MID", "DOWN"}},
+	{Name: "TDX_SAR", Args: []any{H, L, 2, 20}},
+	{Name: "TOPRANGE", Args: []any{H}},
+	{Name: "TRIX", Args: []any{C, 12, 20}, Outputs: []string{"TRIX", "TRMA"}},
+	{Name: "VALUEWHEN", Args: []any{UP, C}},
+	{Name: "VR", Args: []any{C, V, 26}},
+	{Name: "WMA", Args: []any{C, 10}},
+	{Name: "WR", Args: []any{C, H, L, 10, 6}, Outputs: []string{"WR", "WR1"}},
+	{Name: "XSII", Args: []any{C, H, L, 102, 7.0}, Outputs: []string{"TD1", "TD2", "TD3", "TD4"}},
+	{Name: "ZTPRICE", Args: []any{C, 0.1}},
+
+	// 通达信扩展函数
+	{Name: "ACOS", Args: []any{UP}},
+	{Name: "ASIN", Args: []any{UP}},
+	{Name: "ATAN", Args: []any{C}},
+	{Name: "BACKSET", Args: []any{UP, 2}},
+	{Name: "BARSCOUNT", Args: []any{C}},
+	{Name: "BARSSINCE", Args: []any{UP}},
+	{Name: "CEILING", Args: []any{C}},
+	{Name: "CROSSDOWN", Args: []any{M5, M10}},
+	{Name: "CURRBARSCOUNT", Args: []any{C}},
+	{Name: "EXP", Args: []any{UP}},
+	{Name: "FLOOR", Args: []any{C}},
+	{Name: "HOD", Args: []any{H, 20}},
+	{Name: "INTPART", Args: []any{C}},
+	{Name: "LOD", Args: []any{L, 20}},
+	{Name: "LOG", Args: []any{C}},
+	{Name: "MEMA", Args: []any{C, 10}},
+	{Name: "MOD", Args: []any{V, 7.0}},
+	{Name: "PEAK", Args: []any{C, 5.0, 1}},
+	{Name: "RANGE", Args: []any{C, L, H}},
+	{Name: "REFX", Args: []any{C, 1}},
+	{Name: "REVERSE", Args: []any{C}},
+	{Name: "SIGN", Args: []any{M5}},
+	{Name: "SUMBARS", Args: []any{V, 5000000.0}},
+	{Name: "TROUGH", Args: []any{C, 5.0, 1}},
+	{Name: "XMA", Args: []any{C, 5}},
+	{Name: "ZIG", Args: []any{C, 5.0}},
+}
+
+// column 取行情中的一列，返回副本，避免函数修改行情数据
+func (f *Fixture) column(c Column) (any, error) {
+	switch c {
+	case O:
+		return indicators.CopySlice(f.Open), nil
+	case H:
+		return indicators.CopySlice(f.High), nil
+	case L:
+		return indicators.CopySlice(f.Low), nil
+	case C:
+		return indicators.CopySlice(f.Close), nil
+	case V:
+		return indicators.CopySlice(f.Vol), nil
+	case UP:
+		return indicators.GreaterThan(f.Close, f.Open), nil
+	case M5:
+		return indicators.MA(f.Close, 5), nil
+	case M10:
+		return indicators.MA(f.Close, 10), nil
+	}
+	return nil, fmt.Errorf("unknown column %s", c)
+}
+
+// resolveArgs 把用例参数解析为实际的调用参数
+func (c Case) resolveArgs(f *Fixture) ([]any, error) {
+	args := make([]any, len(c.Args))
+	for i, arg := range c.Args {
+		col, ok := arg.(Column)
+		if !ok {
+			args[i] = arg
+			continue
+		}
+		v, err := f.column(col)
+		if err != nil {
+			return nil, fmt.Errorf("%s: argument %d: %w", c.Name, i+1, err)
+		}
+		args[i] = v
+	}
+	return args, nil
+}
@@ -0,0 +1,164 @@
+// Package golden 是 indicators 包的参考输出（golden file）测试工具。
+//
+// 固定的 OHLCV 行情数据放在 testdata/fixtures 下，每个 functionMap 函数在每份行情上的期望输出
+// 以 CSV 保存在 testdata/golden/<行情名>/<函数名>.csv。测试时重新计算并按容差比较，
+// 用于发现 SMA、SAR、DMI、ASI 等函数的回归。修改函数后确认结果正确，再用 goldengen 刷新：
+//
+//	go run ./pkg/extensions/indicators/internal/goldengen
+package golden
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+// fixtureColumns 行情 CSV 的列
+var fixtureColumns = []string{"date", "open", "high", "low", "close", "vol"}
+
+// Fixture 一份固定的 OHLCV 行情数据
+type Fixture struct {
+	Name  string
+	Date  []string
+	Open  indicators.Series
+	High  indicators.Series
+	Low   indicators.Series
+	Close indicators.Series
+	Vol   indicators.Series
+}
+
+// Len 返回 K 线数量
+func (f *Fixture) Len() int {
+	return len(f.Date)
+}
+
+// LoadFixture 读取 CSV 行情文件，列为 date,open,high,low,close,vol，空值或 NaN 表示缺失
+func LoadFixture(path string) (*Fixture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(fixtureColumns, ",") {
+		return nil, fmt.Errorf("%s: header must be %s", path, strings.Join(fixtureColumns, ","))
+	}
+
+	f := &Fixture{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	columns := []*indicators.Series{&f.Open, &f.High, &f.Low, &f.Close, &f.Vol}
+	for line, record := range records[1:] {
+		f.Date = append(f.Date, record[0])
+		for i, col := range columns {
+			v, err := parseFloat(record[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: column %s: %w", path, line+2, fixtureColumns[i+1], err)
+			}
+			*col = append(*col, v)
+		}
+	}
+	return f, nil
+}
+
+// LoadFixtures 读取目录下的所有 .csv 行情文件，按名称排序
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		f, err := LoadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// WriteFixture 把行情写成 CSV
+func WriteFixture(path string, f *Fixture) error {
+	rows := [][]string{fixtureColumns}
+	for i, date := range f.Date {
+		rows = append(rows, []string{date,
+			formatFloat(f.Open[i]), formatFloat(f.High[i]), formatFloat(f.Low[i]),
+			formatFloat(f.Close[i]), formatFloat(f.Vol[i])})
+	}
+	return writeCSV(path, rows)
+}
+
+// FixtureFromKlineJSON 从 examples/charttest/000001.SZ.json 格式的 K 线文件生成行情
+func FixtureFromKlineJSON(path, name string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Klines []struct {
+			Open  float64 `json:"open"`
+			High  float64 `json:"high"`
+			Low   float64 `json:"low"`
+			Close float64 `json:"close"`
+			Vol   float64 `json:"vol"`
+			Date  string  `json:"date"`
+		} `json:"klines"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f := &Fixture{Name: name}
+	for _, k := range doc.Klines {
+		f.Date = append(f.Date, k.Date)
+		f.Open = append(f.Open, k.Open)
+		f.High = append(f.High, k.High)
+		f.Low = append(f.Low, k.Low)
+		f.Close = append(f.Close, k.Close)
+		// 成交量取整，避免复权产生的长小数放大 golden 文件
+		f.Vol = append(f.Vol, math.Round(k.Vol))
+	}
+	return f, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "nan") {
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func formatFloat(v float64) string {
+	if math.IsNaN(v) {
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
+
+func writeCSV(path string, rows [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	if err := w.WriteAll(rows); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,211 @@
+package golden
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+// Result 一个函数在一份行情上的输出，每个输出一列
+type Result struct {
+	Names   []string
+	Columns [][]float64
+}
+
+// Run 在行情上执行用例，条件输出按 1/0 保存，标量输出保存为只有一行的列
+func Run(c Case, f *Fixture) (*Result, error) {
+	args, err := c.resolveArgs(f)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := indicators.CallIndicator(c.Name, args)
+	if err != nil {
+		return nil, err
+	}
+	outputs := []any{ret}
+	if multi, ok := ret.([]any); ok {
+		outputs = multi
+	}
+
+	r := &Result{}
+	for i, out := range outputs {
+		col, err := toColumn(out)
+		if err != nil {
+			return nil, fmt.Errorf("%s: output %d: %w", c.Name, i+1, err)
+		}
+		r.Columns = append(r.Columns, col)
+		r.Names = append(r.Names, outputName(c, i))
+	}
+	return r, nil
+}
+
+func outputName(c Case, i int) string {
+	if i < len(c.Outputs) {
+		return c.Outputs[i]
+	}
+	if i == 0 {
+		return "value"
+	}
+	return fmt.Sprintf("value%d", i+1)
+}
+
+func toColumn(v any) ([]float64, error) {
+	switch x := v.(type) {
+	case indicators.Series:
+		return []float64(x), nil
+	case []float64:
+		return x, nil
+	case []bool:
+		col := make([]float64, len(x))
+		for i, b := range x {
+			if b {
+				col[i] = 1
+			}
+		}
+		return col, nil
+	case float64:
+		return []float64{x}, nil
+	case int:
+		return []float64{float64(x)}, nil
+	case bool:
+		if x {
+			return []float64{1}, nil
+		}
+		return []float64{0}, nil
+	}
+	return nil, fmt.Errorf("unsupported output type %s", reflect.TypeOf(v))
+}
+
+// Path 返回 golden 文件的路径
+func Path(dir string, f *Fixture, c Case) string {
+	return filepath.Join(dir, f.Name, c.Name+".csv")
+}
+
+// WriteResult 把输出写成 golden CSV，第一列是日期
+// 与行情等长的输出每根 K 线一行；标量输出只有一行，日期取最后一根 K 线
+func WriteResult(path string, f *Fixture, r *Result) error {
+	rows := [][]string{append([]string{"date"}, r.Names...)}
+	n := 0
+	for _, col := range r.Columns {
+		n = max(n, len(col))
+	}
+	for i := 0; i < n; i++ {
+		row := []string{dateAt(f, i, n)}
+		for _, col := range r.Columns {
+			if i < len(col) {
+				row = append(row, formatFloat(col[i]))
+			} else {
+				row = append(row, "")
+			}
+		}
+		rows = append(rows, row)
+	}
+	return writeCSV(path, rows)
+}
+
+func dateAt(f *Fixture, i, n int) string {
+	if n == f.Len() {
+		return f.Date[i]
+	}
+	if n == 1 && f.Len() > 0 {
+		return f.Date[f.Len()-1]
+	}
+	return fmt.Sprint(i)
+}
+
+// ReadResult 读取 golden CSV
+func ReadResult(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(records) == 0 || len(records[0]) < 2 || records[0][0] != "date" {
+		return nil, fmt.Errorf("%s: invalid header", path)
+	}
+	r := &Result{Names: records[0][1:], Columns: make([][]float64, len(records[0])-1)}
+	for line, record := range records[1:] {
+		for i, cell := range record[1:] {
+			if cell == "" {
+				continue
+			}
+			v, err := parseFloat(cell)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: column %s: %w", path, line+2, r.Names[i], err)
+			}
+			r.Columns[i] = append(r.Columns[i], v)
+		}
+	}
+	return r, nil
+}
+
+// Tolerance 比较容差，|want-got| <= Abs 或 |want-got| <= Rel*|want| 即视为相等
+type Tolerance struct {
+	Abs float64
+	Rel float64
+}
+
+// DefaultTolerance golden 文件保存 12 位有效数字，默认容差覆盖格式化误差和浮点运算顺序差异
+var DefaultTolerance = Tolerance{Abs: 1e-9, Rel: 1e-9}
+
+// Equal 判断两个值在容差内是否相等，两个 NaN 相等，NaN 与数值不相等，同号无穷相等
+func (t Tolerance) Equal(want, got float64) bool {
+	if math.IsNaN(want) || math.IsNaN(got) {
+		return math.IsNaN(want) && math.IsNaN(got)
+	}
+	if math.IsInf(want, 0) || math.IsInf(got, 0) {
+		return want == got
+	}
+	diff := math.Abs(want - got)
+	return diff <= t.Abs || diff <= t.Rel*math.Abs(want)
+}
+
+// Mismatch 一个不一致的值
+type Mismatch struct {
+	Column string
+	Index  int
+	Date   string
+	Want   float64
+	Got    float64
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s[%d] %s: want %v, got %v", m.Column, m.Index, m.Date, m.Want, m.Got)
+}
+
+// Compare 按容差比较期望输出和实际输出，列名、列数或长度不一致时返回错误
+func Compare(f *Fixture, want, got *Result, tol Tolerance) ([]Mismatch, error) {
+	if strings.Join(want.Names, ",") != strings.Join(got.Names, ",") {
+		return nil, fmt.Errorf("outputs = %v, want %v", got.Names, want.Names)
+	}
+	var mismatches []Mismatch
+	for c, wantCol := range want.Columns {
+		gotCol := got.Columns[c]
+		if len(wantCol) != len(gotCol) {
+			return nil, fmt.Errorf("%s: length = %d, want %d", want.Names[c], len(gotCol), len(wantCol))
+		}
+		for i := range wantCol {
+			if !tol.Equal(wantCol[i], gotCol[i]) {
+				mismatches = append(mismatches, Mismatch{
+					Column: want.Names[c],
+					Index:  i,
+					Date:   dateAt(f, i, len(wantCol)),
+					Want:   wantCol[i],
+					Got:    gotCol[i],
+				})
+			}
+		}
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,127 @@
+package golden
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+)
+
+const (
+	fixtureDir = "testdata/fixtures"
+	goldenDir  = "testdata/golden"
+)
+
+// maxReported 每个函数最多报告的不一致数量
+const maxReported = 5
+
+func TestCasesCoverFunctionMap(t *testing.T) {
+	covered := map[string]bool{}
+	for _, c := range Cases() {
+		if covered[c.Name] {
+			t.Errorf("duplicate case %s", c.Name)
+		}
+		covered[c.Name] = true
+		if _, ok := indicators.GetNaNPolicy(c.Name); !ok {
+			t.Errorf("case %s is not a functionMap function", c.Name)
+		}
+	}
+	for _, name := range indicators.GetNaNPolicyNames() {
+		if !covered[name] {
+			t.Errorf("%s has no golden case", name)
+		}
+	}
+}
+
+func TestGolden(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixtures in %s", fixtureDir)
+	}
+	for _, f := range fixtures {
+		for _, c := range Cases() {
+			t.Run(f.Name+"/"+c.Name, func(t *testing.T) {
+				path := Path(goldenDir, f, c)
+				want, err := ReadResult(path)
+				if os.IsNotExist(err) {
+					t.Fatalf("%s 不存在，运行 go run ./pkg/extensions/indicators/internal/goldengen 生成", path)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := Run(c, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mismatches, err := Compare(f, want, got, DefaultTolerance)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i, m := range mismatches {
+					if i == maxReported {
+						t.Errorf("... %d mismatches in total", len(mismatches))
+						break
+					}
+					t.Error(m)
+				}
+			})
+		}
+	}
+}
+
+func TestToleranceEqual(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		want, got float64
+		equal     bool
+	}{
+		{"完全相等", 1.5, 1.5, true},
+		{"相对误差内", 12345.6789, 12345.6789 * (1 + 1e-12), true},
+		{"绝对误差内", 0, 1e-12, true},
+		{"超出容差", 10, 10.0001, false},
+		{"都是 NaN", nan, nan, true},
+		{"期望 NaN", nan, 1, false},
+		{"实际 NaN", 1, nan, false},
+		{"同号无穷", math.Inf(1), math.Inf(1), true},
+		{"异号无穷", math.Inf(1), math.Inf(-1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultTolerance.Equal(tt.want, tt.got); got != tt.equal {
+				t.Errorf("Equal(%v, %v) = %v, want %v", tt.want, tt.got, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	f := &Fixture{Name: "mini", Date: []string{"2024-01-01", "2024-01-02", "2024-01-03"}}
+	r := &Result{
+		Names:   []string{"A", "B"},
+		Columns: [][]float64{{math.NaN(), 1.0 / 3, 2}, {1, 0, 1}},
+	}
+	path := filepath.Join(t.TempDir(), "mini", "X.csv")
+	if err := WriteResult(path, f, r); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadResult(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := Compare(f, r, back, DefaultTolerance)
+	if err != nil || len(mismatches) > 0 {
+		t.Errorf("round trip mismatches = %v, err = %v", mismatches, err)
+	}
+
+	back.Columns[0][2] = 2.5
+	mismatches, _ = Compare(f, r, back, DefaultTolerance)
+	if len(mismatches) != 1 || mismatches[0].Date != "2024-01-03" || mismatches[0].Column != "A" {
+		t.Errorf("mismatches = %v, want A at 2024-01-03", mismatches)
+	}
+}
@@ -0,0 +1,82 @@
+// goldengen 刷新 indicators 的 golden 文件。
+//
+// 修改函数并确认新结果正确（例如与 MyTT.py 的输出核对）后运行：
+//
+//	go run ./pkg/extensions/indicators/internal/goldengen
+//	go run ./pkg/extensions/indicators/internal/goldengen -only SAR,DMI
+//
+// 从 K 线 JSON 导入新的行情数据：
+//
+//	go run ./pkg/extensions/indicators/internal/goldengen -import examples/charttest/000001.SZ.json -name 000001.SZ
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators/internal/golden"
+)
+
+func main() {
+	testdata := flag.String("testdata", "pkg/extensions/indicators/internal/golden/testdata", "golden 测试数据目录")
+	only := flag.String("only", "", "只刷新指定的函数，逗号分隔")
+	importPath := flag.String("import", "", "从 K 线 JSON 导入行情数据，不刷新 golden 文件")
+	name := flag.String("name", "", "导入的行情名称，默认取文件名")
+	flag.Parse()
+
+	fixtureDir := filepath.Join(*testdata, "fixtures")
+	goldenDir := filepath.Join(*testdata, "golden")
+
+	if *importPath != "" {
+		fixtureName := *name
+		if fixtureName == "" {
+			fixtureName = strings.TrimSuffix(filepath.Base(*importPath), filepath.Ext(*importPath))
+		}
+		f, err := golden.FixtureFromKlineJSON(*importPath, fixtureName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		path := filepath.Join(fixtureDir, fixtureName+".csv")
+		if err := golden.WriteFixture(path, f); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(os.Stderr, "goldengen: wrote %s (%d bars)\n", path, f.Len())
+		return
+	}
+
+	selected := map[string]bool{}
+	for _, s := range strings.Split(*only, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			selected[s] = true
+		}
+	}
+
+	fixtures, err := golden.LoadFixtures(fixtureDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		log.Fatalf("no fixtures in %s", fixtureDir)
+	}
+	count := 0
+	for _, f := range fixtures {
+		for _, c := range golden.Cases() {
+			if len(selected) > 0 && !selected[c.Name] {
+				continue
+			}
+			r, err := golden.Run(c, f)
+			if err != nil {
+				log.Fatalf("%s/%s: %v", f.Name, c.Name, err)
+			}
+			if err := golden.WriteResult(golden.Path(goldenDir, f, c), f, r); err != nil {
+				log.Fatal(err)
+			}
+			count++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "goldengen: wrote %d golden files for %d fixtures\n", count, len(fixtures))
+}
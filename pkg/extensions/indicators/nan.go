@@ -0,0 +1,346 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// NaN 与预热期约定
+//
+// 所有序列函数遵循同一套 NaN 规则，保证同一个信号无论先调用哪个函数结果都一致：
+//
+//  1. 逐元素函数（ABS、ADD、MAX、ZTPRICE 等）：NaN 原样传播，输入在某根 K 线上为 NaN，输出在这根 K 线上也是 NaN。
+//  2. 位移函数（REF、DIFF）：移出序列范围的位置为 NaN，其余 NaN 原样传播。
+//  3. 滑动窗口函数（MA、SUM、STD、HHV、LLV、AVEDEV、WMA、SLOPE、FORCAST、COUNT 等）：
+//     窗口内的 NaN 被跳过，只用有效值计算；有效值个数少于 minPeriods 时输出 NaN。
+//     minPeriods 默认等于 N，即前 N-1 根 K 线是预热期，窗口内有任何 NaN 也输出 NaN（与 pandas rolling 一致）；
+//     可以通过可选的最后一个参数指定更小的 minPeriods，例如 MA(C,20,5)。
+//     N=0 表示从第一根 K 线开始累计（SUM、COUNT、HHV、LLV），其余窗口函数 N<=0 时输出全部为 NaN。
+//  4. 递推平滑函数（EMA、SMA、DMA）：第一个有效值之前为 NaN，从第一个有效值开始递推，
+//     中途遇到 NaN 时沿用上一个结果。
+//  5. 条件函数（IF、CROSS、COUNT、EVERY、EXIST、比较运算等）：NaN 在条件中视为 false，
+//     比较运算只要有一边是 NaN 结果就是 false（包括 !=），条件输出不会出现 NaN。
+//  6. 长度为 1 的序列按标量广播，例如 IF(X>0, X, 0) 中的 0。
+//  7. 组合指标（MACD、KDJ、DMI 等）按组成它们的函数继承上述规则，预热期取各组成部分中最长的一个。
+
+// NaNMode 函数处理 NaN 的方式
+type NaNMode int
+
+const (
+	// NaNPropagate 逐元素传播 NaN
+	NaNPropagate NaNMode = iota
+	// NaNShift 位移产生的空位为 NaN，其余 NaN 原样传播
+	NaNShift
+	// NaNWindow 滑动窗口内跳过 NaN，有效值不足 minPeriods 时输出 NaN
+	NaNWindow
+	// NaNCarry 递推函数，第一个有效值之前为 NaN，之后遇到 NaN 沿用上一个值
+	NaNCarry
+	// NaNFalse 条件函数，NaN 视为 false，输出不含 NaN
+	NaNFalse
+	// NaNComposite 组合指标，继承组成函数的规则
+	NaNComposite
+)
+
+func (m NaNMode) String() string {
+	switch m {
+	case NaNPropagate:
+		return "propagate"
+	case NaNShift:
+		return "shift"
+	case NaNWindow:
+		return "window"
+	case NaNCarry:
+		return "carry"
+	case NaNFalse:
+		return "false"
+	case NaNComposite:
+		return "composite"
+	}
+	return "unknown"
+}
+
+// NaNPolicy 单个函数的 NaN 规则
+type NaNPolicy struct {
+	Mode       NaNMode
+	WarmUp     string // 预热期说明
+	MinPeriods bool   // 是否支持可选的 minPeriods 参数
+}
+
+var nanPolicies = map[string]NaNPolicy{
+	// 逐元素函数
+	"ABS":     {Mode: NaNPropagate, WarmUp: "无"},
+	"LN":      {Mode: NaNPropagate, WarmUp: "无"},
+	"POW":     {Mode: NaNPropagate, WarmUp: "无"},
+	"SQRT":    {Mode: NaNPropagate, WarmUp: "无"},
+	"SIN":     {Mode: NaNPropagate, WarmUp: "无"},
+	"COS":     {Mode: NaNPropagate, WarmUp: "无"},
+	"TAN":     {Mode: NaNPropagate, WarmUp: "无"},
+	"MAX":     {Mode: NaNPropagate, WarmUp: "无"},
+	"MIN":     {Mode: NaNPropagate, WarmUp: "无"},
+	"ADD":     {Mode: NaNPropagate, WarmUp: "无"},
+	"SUB":     {Mode: NaNPropagate, WarmUp: "无"},
+	"MUL":     {Mode: NaNPropagate, WarmUp: "无"},
+	"DIV":     {Mode: NaNPropagate, WarmUp: "无，除数为 0 时为 NaN"},
+	"RD":      {Mode: NaNPropagate, WarmUp: "无"},
+	"RET":     {Mode: NaNPropagate, WarmUp: "无，超出范围为 NaN"},
+	"CONST":   {Mode: NaNPropagate, WarmUp: "无，取最后一个值"},
+	"ZTPRICE": {Mode: NaNPropagate, WarmUp: "无"},
+	"DTPRICE": {Mode: NaNPropagate, WarmUp: "无"},
+
+	// 位移函数
+	"REF":  {Mode: NaNShift, WarmUp: "N"},
+	"DIFF": {Mode: NaNShift, WarmUp: "N"},
+
+	// 滑动窗口函数
+	"MA":         {Mode: NaNWindow, WarmUp: "N-1", MinPeriods: true},
+	"SUM":        {Mode: NaNWindow, WarmUp: "N-1，N=0 时从第一个有效值累计", MinPeriods: true},
+	"STD":        {Mode: NaNWindow, WarmUp: "N-1", MinPeriods: true},
+	"HHV":        {Mode: NaNWindow, WarmUp: "N-1，N=0 时从第一个有效值累计", MinPeriods: true},
+	"LLV":        {Mode: NaNWindow, WarmUp: "N-1，N=0 时从第一个有效值累计", MinPeriods: true},
+	"AVEDEV":     {Mode: NaNWindow, WarmUp: "N-1", MinPeriods: true},
+	"WMA":        {Mode: NaNWindow, WarmUp: "N-1", MinPeriods: true},
+	"SLOPE":      {Mode: NaNWindow, WarmUp: "N-1", MinPeriods: true},
+	"FORCAST":    {Mode: NaNWindow, WarmUp: "N-1", MinPeriods: true},
+	"HHVBARS":    {Mode: NaNWindow, WarmUp: "N-1，窗口内有 NaN 时为 NaN"},
+	"LLVBARS":    {Mode: NaNWindow, WarmUp: "N-1，窗口内有 NaN 时为 NaN"},
+	"TOPRANGE":   {Mode: NaNPropagate, WarmUp: "无"},
+	"LOWRANGE":   {Mode: NaNPropagate, WarmUp: "无"},
+	"COUNT":      {Mode: NaNWindow, WarmUp: "N-1，N=0 时从第一根 K 线累计", MinPeriods: true},
+	"BARSSINCEN": {Mode: NaNWindow, WarmUp: "N-1，窗口内没有成立的条件时为 NaN"},
+
+	// 递推平滑函数
+	"EMA": {Mode: NaNCarry, WarmUp: "第一个有效值之前"},
+	"SMA": {Mode: NaNCarry, WarmUp: "第一个有效值之前"},
+	"DMA": {Mode: NaNCarry, WarmUp: "第一个有效值之前"},
+
+	// 条件函数
+	"IF":                 {Mode: NaNFalse, WarmUp: "无，条件为 NaN 时取 B"},
+	"EVERY":              {Mode: NaNFalse, WarmUp: "N-1 输出 false"},
+	"EXIST":              {Mode: NaNFalse, WarmUp: "N-1 输出 false"},
+	"CROSS":              {Mode: NaNFalse, WarmUp: "两条线前一根和当前都有效才可能为 true"},
+	"LONGCROSS":          {Mode: NaNFalse, WarmUp: "N"},
+	"BARSLAST":           {Mode: NaNFalse, WarmUp: "条件第一次成立之前为 NaN"},
+	"VALUEWHEN":          {Mode: NaNFalse, WarmUp: "条件第一次成立之前为 NaN"},
+	"BETWEEN":            {Mode: NaNFalse, WarmUp: "无"},
+	"FILTER":             {Mode: NaNFalse, WarmUp: "无"},
+	"LAST":               {Mode: NaNFalse, WarmUp: "A 输出 false"},
+	"GreaterThan":        {Mode: NaNFalse, WarmUp: "无"},
+	"LessThan":           {Mode: NaNFalse, WarmUp: "无"},
+	"LessThanOrEqual":    {Mode: NaNFalse, WarmUp: "无"},
+	"GreaterThanOrEqual": {Mode: NaNFalse, WarmUp: "无"},
+	"Equal":              {Mode: NaNFalse, WarmUp: "无"},
+	"NotEqual":           {Mode: NaNFalse, WarmUp: "无"},
+
+	// 组合指标
+	"MACD":    {Mode: NaNComposite, WarmUp: "EMA，从第一个有效值开始"},
+	"KDJ":     {Mode: NaNComposite, WarmUp: "N-1"},
+	"RSI":     {Mode: NaNComposite, WarmUp: "1"},
+	"BOLL":    {Mode: NaNComposite, WarmUp: "N-1"},
+	"WR":      {Mode: NaNComposite, WarmUp: "max(N,N1)-1"},
+	"BIAS":    {Mode: NaNComposite, WarmUp: "max(L1,L2,L3)-1"},
+	"PSY":     {Mode: NaNComposite, WarmUp: "N+M-2"},
+	"CCI":     {Mode: NaNComposite, WarmUp: "N-1"},
+	"ATR":     {Mode: NaNComposite, WarmUp: "N"},
+	"BBI":     {Mode: NaNComposite, WarmUp: "max(M1..M4)-1"},
+	"DMI":     {Mode: NaNComposite, WarmUp: "M1+M2"},
+	"TAQ":     {Mode: NaNComposite, WarmUp: "N-1"},
+	"KTN":     {Mode: NaNComposite, WarmUp: "M"},
+	"TRIX":    {Mode: NaNComposite, WarmUp: "M2"},
+	"VR":      {Mode: NaNComposite, WarmUp: "M1"},
+	"CR":      {Mode: NaNComposite, WarmUp: "N"},
+	"EMV":     {Mode: NaNComposite, WarmUp: "2N-1"},
+	"DPO":     {Mode: NaNComposite, WarmUp: "M1+M2-1"},
+	"BRAR":    {Mode: NaNComposite, WarmUp: "M1"},
+	"DFMA":    {Mode: NaNComposite, WarmUp: "max(N1,N2)+M-2"},
+	"MTM":     {Mode: NaNComposite, WarmUp: "N"},
+	"MASS":    {Mode: NaNComposite, WarmUp: "3N1+N2-4"},
+	"ROC":     {Mode: NaNComposite, WarmUp: "N"},
+	"EXPMA":   {Mode: NaNComposite, WarmUp: "EMA，从第一个有效值开始"},
+	"OBV":     {Mode: NaNComposite, WarmUp: "无"},
+	"MFI":     {Mode: NaNComposite, WarmUp: "N"},
+	"ASI":     {Mode: NaNComposite, WarmUp: "M1"},
+	"XSII":    {Mode: NaNComposite, WarmUp: "20"},
+	"SAR":     {Mode: NaNComposite, WarmUp: "N"},
+	"TDX_SAR": {Mode: NaNComposite, WarmUp: "无"},
+	"QRR":     {Mode: NaNComposite, WarmUp: "10"},
+	"SHO":     {Mode: NaNComposite, WarmUp: "24"},
+	"LON":     {Mode: NaNComposite, WarmUp: "1"},
+
+	// 通达信扩展函数
+	"CEILING":       {Mode: NaNPropagate, WarmUp: "无"},
+	"FLOOR":         {Mode: NaNPropagate, WarmUp: "无"},
+	"INTPART":       {Mode: NaNPropagate, WarmUp: "无"},
+	"MOD":           {Mode: NaNPropagate, WarmUp: "无，除数为 0 时为 NaN"},
+	"SIGN":          {Mode: NaNPropagate, WarmUp: "无"},
+	"EXP":           {Mode: NaNPropagate, WarmUp: "无"},
+	"LOG":           {Mode: NaNPropagate, WarmUp: "无"},
+	"ATAN":          {Mode: NaNPropagate, WarmUp: "无"},
+	"ASIN":          {Mode: NaNPropagate, WarmUp: "无"},
+	"ACOS":          {Mode: NaNPropagate, WarmUp: "无"},
+	"REVERSE":       {Mode: NaNPropagate, WarmUp: "无"},
+	"HOD":           {Mode: NaNPropagate, WarmUp: "无，窗口不足 N 时用已有数据"},
+	"LOD":           {Mode: NaNPropagate, WarmUp: "无，窗口不足 N 时用已有数据"},
+	"REFX":          {Mode: NaNComposite, WarmUp: "末尾 N 根为 NaN（未来函数）"},
+	"BARSCOUNT":     {Mode: NaNComposite, WarmUp: "第一个有效值之前"},
+	"CURRBARSCOUNT": {Mode: NaNComposite, WarmUp: "无，只使用序列长度"},
+	"SUMBARS":       {Mode: NaNComposite, WarmUp: "无，累加不足时为 0，NaN 按 0 累加"},
+	"MEMA":          {Mode: NaNComposite, WarmUp: "N-1，之后遇到 NaN 沿用上一个值"},
+	"XMA":           {Mode: NaNComposite, WarmUp: "无，两端用不完整窗口（未来函数）"},
+	"ZIG":           {Mode: NaNComposite, WarmUp: "第一个有效值之前（未来函数）"},
+	"PEAK":          {Mode: NaNComposite, WarmUp: "第 M 个波峰之前（未来函数）"},
+	"TROUGH":        {Mode: NaNComposite, WarmUp: "第 M 个波谷之前（未来函数）"},
+	"BACKSET":       {Mode: NaNFalse, WarmUp: "无（未来函数）"},
+	"BARSSINCE":     {Mode: NaNFalse, WarmUp: "条件第一次成立之前为 NaN"},
+	"RANGE":         {Mode: NaNFalse, WarmUp: "无"},
+	"CROSSDOWN":     {Mode: NaNFalse, WarmUp: "两条线前一根和当前都有效才可能为 true"},
+}
+
+// GetNaNPolicy 获取函数的 NaN 规则
+func GetNaNPolicy(name string) (NaNPolicy, bool) {
+	p, ok := nanPolicies[name]
+	return p, ok
+}
+
+// GetNaNPolicyNames 返回定义了 NaN 规则的所有函数名（已排序）
+func GetNaNPolicyNames() []string {
+	names := make([]string, 0, len(nanPolicies))
+	for name := range nanPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isTrue 条件判断，NaN 视为 false
+func isTrue(v float64) bool {
+	return v != 0 && !math.IsNaN(v)
+}
+
+// valueAt 取序列第 i 个值，长度为 1 的序列按标量广播，超出范围为 NaN
+func valueAt(S Series, i int) float64 {
+	if len(S) == 1 {
+		return S[0]
+	}
+	if i < 0 || i >= len(S) {
+		return math.NaN()
+	}
+	return S[i]
+}
+
+// nanSeries 返回长度为 n 的全 NaN 序列
+func nanSeries(n int) Series {
+	result := make(Series, n)
+	for i := range result {
+		result[i] = math.NaN()
+	}
+	return result
+}
+
+// resolveMinPeriods 解析可选的 minPeriods 参数，默认等于窗口长度 N
+// N<=0 表示累计窗口，默认 minPeriods 为 1
+func resolveMinPeriods(N int, minPeriods []int) int {
+	m := N
+	if N <= 0 {
+		m = 1
+	}
+	if len(minPeriods) > 0 && minPeriods[0] > 0 {
+		m = minPeriods[0]
+	}
+	if N > 0 && m > N {
+		m = N
+	}
+	if m < 1 {
+		m = 1
+	}
+	return m
+}
+
+// rollingApply 对每个窗口内的有效值调用 fn
+// vals 是窗口内的有效值，pos 是它们在窗口中的位置（当前 K 线的位置为 N-1）
+// N<=0 表示从第一根 K 线开始的累计窗口；有效值个数少于 minPeriods 时输出 NaN
+func rollingApply(S Series, N, minPeriods int, fn func(vals []float64, pos []int) float64) Series {
+	result := make(Series, len(S))
+	vals := make([]float64, 0, max(N, 0))
+	pos := make([]int, 0, max(N, 0))
+	for i := range S {
+		start := 0
+		offset := 0
+		if N > 0 {
+			start = i - N + 1
+			offset = start
+			if start < 0 {
+				start = 0
+			}
+		}
+		vals = vals[:0]
+		pos = pos[:0]
+		for j := start; j <= i; j++ {
+			if !math.IsNaN(S[j]) {
+				vals = append(vals, S[j])
+				pos = append(pos, j-offset)
+			}
+		}
+		if len(vals) < minPeriods {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = fn(vals, pos)
+	}
+	return result
+}
+
+// rollingCount 统计每个窗口内条件成立的次数，窗口不完整且 K 线数少于 minPeriods 时输出 NaN
+func rollingCount(S []bool, N, minPeriods int) Series {
+	result := make(Series, len(S))
+	count := 0
+	for i := range S {
+		if S[i] {
+			count++
+		}
+		if N > 0 && i >= N && S[i-N] {
+			count--
+		}
+		bars := i + 1
+		if N > 0 && bars > N {
+			bars = N
+		}
+		if bars < minPeriods {
+			result[i] = math.NaN()
+		} else {
+			result[i] = float64(count)
+		}
+	}
+	return result
+}
+
+func sumOf(vals []float64) float64 {
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum
+}
+
+func meanOf(vals []float64) float64 {
+	return sumOf(vals) / float64(len(vals))
+}
+
+// linearRegression 对 (pos, vals) 做最小二乘回归，返回斜率和截距
+func linearRegression(vals []float64, pos []int) (float64, float64) {
+	n := float64(len(vals))
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for i, y := range vals {
+		x := float64(pos[i])
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return math.NaN(), math.NaN()
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
+	intercept := (sumY - slope*sumX) / n
+	return slope, intercept
+}
@@ -0,0 +1,229 @@
+package indicators
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// nanTestData 带前导 NaN 和中间 NaN 的参考序列
+func nanTestData() Series {
+	nan := math.NaN()
+	return Series{nan, nan, 10, 11, 12, nan, 14, 13, 15, 16, 18, 17, nan, nan, 19, 21, 20, 22, 23, 21, 24, 25, 26, 24, 27}
+}
+
+const nanTestN = 3
+
+// nanTestArgs 根据函数签名构造参数：序列用参考序列，条件用参考序列 > 15，整数为 nanTestN，浮点数为 0.5
+// 可变参数不传
+func nanTestArgs(fn any) []any {
+	data := nanTestData()
+	fnType := reflect.TypeOf(fn)
+	numIn := fnType.NumIn()
+	if fnType.IsVariadic() {
+		numIn--
+	}
+	args := make([]any, numIn)
+	for i := 0; i < numIn; i++ {
+		switch t := fnType.In(i); {
+		case t == reflect.TypeOf(Series{}) || t == reflect.TypeOf([]float64{}):
+			args[i] = CopySlice(data)
+		case t == reflect.TypeOf([]bool{}):
+			args[i] = GreaterThan(data, Series{15})
+		case t.Kind() == reflect.Int:
+			args[i] = nanTestN
+		case t.Kind() == reflect.Float64:
+			args[i] = 0.5
+		default:
+			args[i] = nanTestN
+		}
+	}
+	return args
+}
+
+func TestNaNPolicyCoverage(t *testing.T) {
+	for name, fn := range functionMap {
+		policy, ok := GetNaNPolicy(name)
+		if !ok {
+			t.Errorf("%s 没有定义 NaN 规则", name)
+			continue
+		}
+		if variadic := reflect.TypeOf(fn).IsVariadic(); variadic != policy.MinPeriods {
+			t.Errorf("%s MinPeriods = %v, 函数签名 variadic = %v", name, policy.MinPeriods, variadic)
+		}
+	}
+	for _, name := range GetNaNPolicyNames() {
+		if _, ok := functionMap[name]; !ok {
+			t.Errorf("NaN 规则 %s 没有对应的函数", name)
+		}
+	}
+}
+
+// TestNaNConformance 对每个内置函数用参考序列检查其 NaN 规则
+func TestNaNConformance(t *testing.T) {
+	data := nanTestData()
+	// CONST 取最后一个值，RET 返回标量，不逐根 K 线检查
+	notPerBar := map[string]bool{"CONST": true, "RET": true, "RD": true}
+
+	for _, name := range GetNaNPolicyNames() {
+		policy, _ := GetNaNPolicy(name)
+		t.Run(name, func(t *testing.T) {
+			ret, err := CallIndicator(name, nanTestArgs(functionMap[name]))
+			if err != nil {
+				t.Fatalf("调用失败: %v", err)
+			}
+			outputs := []any{ret}
+			if multi, ok := ret.([]any); ok {
+				outputs = multi
+			}
+			for _, out := range outputs {
+				switch o := out.(type) {
+				case Series:
+					if len(o) != len(data) && !notPerBar[name] {
+						t.Fatalf("输出长度 %d, 期望 %d", len(o), len(data))
+					}
+				case []bool:
+					if len(o) != len(data) {
+						t.Fatalf("输出长度 %d, 期望 %d", len(o), len(data))
+					}
+				}
+			}
+			s, isSeries := ret.(Series)
+			if !isSeries || notPerBar[name] {
+				return
+			}
+
+			switch policy.Mode {
+			case NaNPropagate:
+				for i, v := range data {
+					if math.IsNaN(v) && !math.IsNaN(s[i]) {
+						t.Errorf("第 %d 根输入为 NaN, 输出 %v", i, s[i])
+					}
+				}
+			case NaNShift:
+				for i := 0; i < nanTestN; i++ {
+					if !math.IsNaN(s[i]) {
+						t.Errorf("第 %d 根移出范围应为 NaN, 输出 %v", i, s[i])
+					}
+				}
+			case NaNWindow:
+				_, boolInput := nanTestArgs(functionMap[name])[0].([]bool)
+				for i := range data {
+					window := data[max(i-nanTestN+1, 0) : i+1]
+					hasNaN := false
+					for _, v := range window {
+						hasNaN = hasNaN || math.IsNaN(v)
+					}
+					warmUp := i < nanTestN-1
+					if boolInput {
+						if warmUp && !math.IsNaN(s[i]) {
+							t.Errorf("第 %d 根在预热期内应为 NaN, 输出 %v", i, s[i])
+						}
+						continue
+					}
+					if got := math.IsNaN(s[i]); got != (warmUp || hasNaN) {
+						t.Errorf("第 %d 根 NaN = %v, 期望 %v (窗口 %v)", i, got, warmUp || hasNaN, window)
+					}
+				}
+			case NaNCarry:
+				first := -1
+				for i, v := range data {
+					if !math.IsNaN(v) {
+						first = i
+						break
+					}
+				}
+				for i, v := range s {
+					if (i < first) != math.IsNaN(v) {
+						t.Errorf("第 %d 根输出 %v, 第一个有效值在第 %d 根", i, v, first)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNaNWindowMinPeriods(t *testing.T) {
+	nan := math.NaN()
+	S := Series{nan, 1, 2, nan, 4, 5}
+
+	tests := []struct {
+		name string
+		got  Series
+		want Series
+	}{
+		{"MA 默认 minPeriods", MA(S, 3), Series{nan, nan, nan, nan, nan, nan}},
+		{"MA minPeriods=2", MA(S, 3, 2), Series{nan, nan, 1.5, 1.5, 3, 4.5}},
+		{"SUM minPeriods=1", SUM(S, 2, 1), Series{nan, 1, 3, 2, 4, 9}},
+		{"SUM N=0 累计", SUM(S, 0), Series{nan, 1, 3, 3, 7, 12}},
+		{"HHV N=0 累计", HHV(S, 0), Series{nan, 1, 2, 2, 4, 5}},
+		{"LLV minPeriods=1", LLV(S, 2, 1), Series{nan, 1, 1, 2, 4, 4}},
+		{"COUNT N=0", COUNT([]bool{true, false, true}, 0), Series{1, 1, 2}},
+		{"COUNT 预热期", COUNT([]bool{true, false, true}, 2), Series{nan, 1, 1}},
+		{"MA N=0", MA(S, 0), Series{nan, nan, nan, nan, nan, nan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !equalWithNaN(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	// 通过适配器传入 minPeriods
+	ret, err := CallIndicator("MA", []any{S, 3.0, 2.0})
+	if err != nil || !equalWithNaN(ret, MA(S, 3, 2)) {
+		t.Errorf("CallIndicator MA minPeriods = %v, %v", ret, err)
+	}
+	ret, err = CallIndicatorByReflection("MA", []any{S, 3, 2})
+	if err != nil || !equalWithNaN(ret, MA(S, 3, 2)) {
+		t.Errorf("CallIndicatorByReflection MA minPeriods = %v, %v", ret, err)
+	}
+}
+
+func TestNaNCarryAndConditions(t *testing.T) {
+	nan := math.NaN()
+
+	ema := EMA(Series{nan, nan, 10, nan, 10}, 3)
+	if !equalWithNaN(ema, Series{nan, nan, 10, 10, 10}) {
+		t.Errorf("EMA = %v", ema)
+	}
+	if all := SMA(Series{nan, nan}, 3, 1); !equalWithNaN(all, Series{nan, nan}) {
+		t.Errorf("SMA 全部 NaN = %v", all)
+	}
+
+	cross := CROSS(Series{nan, 1, 3, nan, 3, 1, 3}, Series{2, 2, 2, 2, 2, 2, 2})
+	wantCross := []bool{false, false, true, false, false, false, true}
+	if !reflect.DeepEqual(cross, wantCross) {
+		t.Errorf("CROSS = %v, want %v", cross, wantCross)
+	}
+
+	A := Series{nan, 1, 2}
+	for name, got := range map[string][]bool{
+		"GreaterThan":        GreaterThan(A, Series{0}),
+		"GreaterThanOrEqual": GreaterThanOrEqual(A, Series{1, 1}),
+		"NotEqual":           NotEqual(A, Series{1, nan, 3}),
+	} {
+		if got[0] {
+			t.Errorf("%s NaN 比较应为 false: %v", name, got)
+		}
+	}
+	if got := GreaterThanOrEqual(A, Series{1, 1}); got[2] {
+		t.Errorf("GreaterThanOrEqual 超出范围应为 false: %v", got)
+	}
+
+	// NaN 条件视为 false，标量按长度 1 的序列广播
+	ret, err := CallIndicator("IF", []any{Series{nan, 1, 0}, Series{5, 6, 7}, 0.0})
+	if err != nil || !equalWithNaN(ret, Series{0, 6, 0}) {
+		t.Errorf("IF = %v, %v", ret, err)
+	}
+	if got := IF([]bool{true, false}, Series{9}, Series{1}); !equalWithNaN(got, Series{9, 1}) {
+		t.Errorf("IF 广播 = %v", got)
+	}
+	if got := DIV(Series{2, 4}, Series{2}); !equalWithNaN(got, Series{1, 2}) {
+		t.Errorf("DIV 广播 = %v", got)
+	}
+	if got := MAX(Series{nan, 1, 3}, Series{2}); !equalWithNaN(got, Series{nan, 2, 3}) {
+		t.Errorf("MAX 广播 = %v", got)
+	}
+}
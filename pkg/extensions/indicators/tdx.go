@@ -0,0 +1,498 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// 通达信/麦语言扩展函数
+//
+// 注册在 MyTT.go 的 functionMap 中，与 MyTT 函数遵循同样的 Series 约定和 NaN 规则（见 nan.go）。
+// REFX、BACKSET、ZIG、PEAK、TROUGH、XMA 会用到当前 K 线之后的数据（未来函数），
+// 历史信号会随新 K 线改变，回测时需要注意，可以用 IsFutureFunction 检查。
+
+// futureFunctions 引用未来数据的函数
+var futureFunctions = map[string]bool{
+	"REFX":    true,
+	"BACKSET": true,
+	"ZIG":     true,
+	"PEAK":    true,
+	"TROUGH":  true,
+	"XMA":     true,
+}
+
+// IsFutureFunction 判断函数是否引用未来数据
+func IsFutureFunction(name string) bool {
+	return futureFunctions[name]
+}
+
+// GetFutureFunctionNames 返回所有未来函数名（已排序）
+func GetFutureFunctionNames() []string {
+	names := make([]string, 0, len(futureFunctions))
+	for name := range futureFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mapSeries 对序列逐元素调用 fn
+func mapSeries(S Series, fn func(float64) float64) Series {
+	result := make(Series, len(S))
+	for i, v := range S {
+		result[i] = fn(v)
+	}
+	return result
+}
+
+// ------------------ 数学函数 ---------------------------------
+
+// CEILING 向上取整
+func CEILING(S Series) Series {
+	return mapSeries(S, math.Ceil)
+}
+
+// FLOOR 向下取整
+func FLOOR(S Series) Series {
+	return mapSeries(S, math.Floor)
+}
+
+// INTPART 取整数部分，向 0 截断，INTPART(-3.7)=-3
+func INTPART(S Series) Series {
+	return mapSeries(S, math.Trunc)
+}
+
+// MOD 求模，结果符号与 A 相同，MOD(26,10)=6；B 为 0 时为 NaN，长度为 1 的 B 按标量广播
+func MOD(A, B Series) Series {
+	result := make(Series, len(A))
+	for i, a := range A {
+		b := valueAt(B, i)
+		if b == 0 || math.IsNaN(b) {
+			result[i] = math.NaN()
+		} else {
+			result[i] = math.Mod(a, b)
+		}
+	}
+	return result
+}
+
+// SIGN 求符号，正数为 1，负数为 -1，0 为 0
+func SIGN(S Series) Series {
+	return mapSeries(S, func(v float64) float64 {
+		switch {
+		case v > 0:
+			return 1
+		case v < 0:
+			return -1
+		}
+		return v // 0 和 NaN 原样返回
+	})
+}
+
+// EXP 求 e 的 X 次幂
+func EXP(S Series) Series {
+	return mapSeries(S, math.Exp)
+}
+
+// LOG 求以 10 为底的对数，X<=0 时为 NaN
+func LOG(S Series) Series {
+	return mapSeries(S, func(v float64) float64 {
+		if v <= 0 {
+			return math.NaN()
+		}
+		return math.Log10(v)
+	})
+}
+
+// ATAN 反正切
+func ATAN(S Series) Series {
+	return mapSeries(S, math.Atan)
+}
+
+// ASIN 反正弦，超出 [-1,1] 时为 NaN
+func ASIN(S Series) Series {
+	return mapSeries(S, math.Asin)
+}
+
+// ACOS 反余弦，超出 [-1,1] 时为 NaN
+func ACOS(S Series) Series {
+	return mapSeries(S, math.Acos)
+}
+
+// REVERSE 求相反数
+func REVERSE(S Series) Series {
+	return mapSeries(S, func(v float64) float64 { return -v })
+}
+
+// ------------------ 引用函数 ---------------------------------
+
+// REFX 引用 N 周期后的数据（未来函数），超出序列末尾为 NaN
+// N 可以是 int 或 Series，N<=0 时返回原序列
+func REFX(S Series, N any) Series {
+	switch n := N.(type) {
+	case int, float64:
+		steps := int(toFloat(n))
+		if steps <= 0 {
+			return S
+		}
+		result := make(Series, len(S))
+		for i := range S {
+			if i+steps < len(S) {
+				result[i] = S[i+steps]
+			} else {
+				result[i] = math.NaN()
+			}
+		}
+		return result
+	case Series, []float64:
+		steps := Series(toFloats(n))
+		result := make(Series, len(S))
+		for i := range S {
+			step := valueAt(steps, i)
+			if math.IsNaN(step) || i+int(step) < 0 || i+int(step) >= len(S) {
+				result[i] = math.NaN()
+			} else {
+				result[i] = S[i+int(step)]
+			}
+		}
+		return result
+	}
+	panic("N 类型不支持")
+}
+
+func toFloat(v any) float64 {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case float64:
+		return x
+	}
+	return math.NaN()
+}
+
+func toFloats(v any) []float64 {
+	switch x := v.(type) {
+	case Series:
+		return x
+	case []float64:
+		return x
+	}
+	return nil
+}
+
+// BACKSET 条件成立时，把当前位置到 N-1 周期前的数值设为 1（未来函数），其余为 0
+// N 可以是常数或序列，BACKSET(C>O,2) 表示收阳时当天和前一天都为 1
+func BACKSET(S []bool, N Series) Series {
+	result := make(Series, len(S))
+	for i, cond := range S {
+		n := valueAt(N, i)
+		if !cond || math.IsNaN(n) {
+			continue
+		}
+		for j := max(i-int(n)+1, 0); j <= i; j++ {
+			result[j] = 1
+		}
+	}
+	return result
+}
+
+// ------------------ 周期统计函数 ---------------------------------
+
+// BARSCOUNT 第一个有效数据到当前的周期数（含当前），第一个有效数据之前为 NaN
+// BARSCOUNT(C) 在日线上是上市以来的交易日数
+func BARSCOUNT(S Series) Series {
+	result := make(Series, len(S))
+	first := -1
+	for i, v := range S {
+		if first < 0 && !math.IsNaN(v) {
+			first = i
+		}
+		if first < 0 {
+			result[i] = math.NaN()
+		} else {
+			result[i] = float64(i - first + 1)
+		}
+	}
+	return result
+}
+
+// BARSSINCE 第一次条件成立到当前的周期数，条件第一次成立之前为 NaN
+func BARSSINCE(S []bool) Series {
+	result := make(Series, len(S))
+	first := -1
+	for i, cond := range S {
+		if first < 0 && cond {
+			first = i
+		}
+		if first < 0 {
+			result[i] = math.NaN()
+		} else {
+			result[i] = float64(i - first)
+		}
+	}
+	return result
+}
+
+// CURRBARSCOUNT 到最后一根 K 线的周期数，最后一根为 1，S 只用来确定序列长度
+func CURRBARSCOUNT(S Series) Series {
+	result := make(Series, len(S))
+	for i := range result {
+		result[i] = float64(len(S) - i)
+	}
+	return result
+}
+
+// SUMBARS 将 X 向前累加直到大于等于 A，返回累加的周期数（含当前），累加到第一根仍不足时为 0
+// 例如 SUMBARS(VOL,CAPITAL) 求完全换手的周期数；X 中的 NaN 按 0 累加，A 为 NaN 时结果为 NaN
+func SUMBARS(X, A Series) Series {
+	result := make(Series, len(X))
+	for i := range X {
+		a := valueAt(A, i)
+		if math.IsNaN(a) {
+			result[i] = math.NaN()
+			continue
+		}
+		sum := 0.0
+		for j := i; j >= 0; j-- {
+			if !math.IsNaN(X[j]) {
+				sum += X[j]
+			}
+			if sum >= a {
+				result[i] = float64(i - j + 1)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// HOD 当前值是 N 周期内的第几个高值，最高为 1，N=0 表示从第一根 K 线开始
+// 窗口不足 N 时使用已有数据，窗口内的 NaN 被跳过，当前值为 NaN 时输出 NaN
+func HOD(S Series, N int) Series {
+	return rankInWindow(S, N, func(other, cur float64) bool { return other > cur })
+}
+
+// LOD 当前值是 N 周期内的第几个低值，最低为 1，N=0 表示从第一根 K 线开始
+func LOD(S Series, N int) Series {
+	return rankInWindow(S, N, func(other, cur float64) bool { return other < cur })
+}
+
+func rankInWindow(S Series, N int, better func(other, cur float64) bool) Series {
+	result := make(Series, len(S))
+	for i, cur := range S {
+		if math.IsNaN(cur) || N < 0 {
+			result[i] = math.NaN()
+			continue
+		}
+		start := 0
+		if N > 0 {
+			start = max(i-N+1, 0)
+		}
+		rank := 1
+		for j := start; j < i; j++ {
+			if better(S[j], cur) {
+				rank++
+			}
+		}
+		result[i] = float64(rank)
+	}
+	return result
+}
+
+// ------------------ 均线函数 ---------------------------------
+
+// MEMA 平滑移动平均 Y=(X+Y'*(N-1))/N，起始值为第一个完整窗口的简单平均
+// 起始值之前为 NaN，之后遇到 NaN 沿用上一个值
+func MEMA(S Series, N int) Series {
+	if N <= 0 {
+		return nanSeries(len(S))
+	}
+	ma := MA(S, N)
+	result := make(Series, len(S))
+	seeded := false
+	for i := range S {
+		switch {
+		case !seeded && math.IsNaN(ma[i]):
+			result[i] = math.NaN()
+		case !seeded:
+			result[i] = ma[i]
+			seeded = true
+		case math.IsNaN(S[i]):
+			result[i] = result[i-1]
+		default:
+			result[i] = (S[i] + result[i-1]*float64(N-1)) / float64(N)
+		}
+	}
+	return result
+}
+
+// XMA 偏移（居中）移动平均（未来函数），以当前 K 线为中心取 N 个周期的平均
+// 两端窗口不完整时用已有数据计算，窗口内的 NaN 被跳过
+func XMA(S Series, N int) Series {
+	if N <= 0 {
+		return nanSeries(len(S))
+	}
+	half := N / 2
+	result := make(Series, len(S))
+	for i := range S {
+		start := max(i-half, 0)
+		end := min(i+N-1-half, len(S)-1)
+		sum, count := 0.0, 0
+		for j := start; j <= end; j++ {
+			if !math.IsNaN(S[j]) {
+				sum += S[j]
+				count++
+			}
+		}
+		if count == 0 {
+			result[i] = math.NaN()
+		} else {
+			result[i] = sum / float64(count)
+		}
+	}
+	return result
+}
+
+// ------------------ 条件函数 ---------------------------------
+
+// RANGE A 在 B 和 C 之间（不含边界）：A>B AND A<C
+func RANGE(A, B, C Series) []bool {
+	result := make([]bool, len(A))
+	for i, a := range A {
+		b, c := valueAt(B, i), valueAt(C, i)
+		result[i] = a > b && a < c
+	}
+	return result
+}
+
+// CROSSDOWN 向下穿越，A 从上方向下穿过 B，等价于 CROSS(B,A)
+func CROSSDOWN(A, B Series) []bool {
+	result := make([]bool, len(A))
+	for i := 1; i < len(A); i++ {
+		prevA, prevB := A[i-1], valueAt(B, i-1)
+		curA, curB := A[i], valueAt(B, i)
+		if math.IsNaN(prevA) || math.IsNaN(prevB) || math.IsNaN(curA) || math.IsNaN(curB) {
+			continue
+		}
+		result[i] = prevA >= prevB && curA < curB
+	}
+	return result
+}
+
+// ------------------ 之字转向 ---------------------------------
+
+// zigPivots 计算之字转向的转折点，N 为转向幅度百分比
+// 返回转折点下标，首尾两根有效 K 线总是转折点
+func zigPivots(S Series, N float64) []int {
+	first := -1
+	for i, v := range S {
+		if !math.IsNaN(v) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	p := N / 100
+	pivots := []int{first}
+	trend := 0 // 1 上升，-1 下降，0 尚未确定
+	ext := first
+	last := first
+	for i := first + 1; i < len(S); i++ {
+		v := S[i]
+		if math.IsNaN(v) {
+			continue
+		}
+		last = i
+		switch trend {
+		case 0:
+			if v >= S[first]*(1+p) {
+				trend, ext = 1, i
+			} else if v <= S[first]*(1-p) {
+				trend, ext = -1, i
+			}
+		case 1:
+			if v > S[ext] {
+				ext = i
+			} else if v <= S[ext]*(1-p) {
+				pivots = append(pivots, ext)
+				trend, ext = -1, i
+			}
+		case -1:
+			if v < S[ext] {
+				ext = i
+			} else if v >= S[ext]*(1+p) {
+				pivots = append(pivots, ext)
+				trend, ext = 1, i
+			}
+		}
+	}
+	if trend != 0 && ext != pivots[len(pivots)-1] {
+		pivots = append(pivots, ext)
+	}
+	if last != pivots[len(pivots)-1] {
+		pivots = append(pivots, last)
+	}
+	return pivots
+}
+
+// ZIG 之字转向（未来函数），价格变化超过 N% 时转向，转折点之间线性连接
+// 第一个有效值之前为 NaN
+func ZIG(S Series, N float64) Series {
+	result := nanSeries(len(S))
+	pivots := zigPivots(S, N)
+	if len(pivots) == 1 {
+		result[pivots[0]] = S[pivots[0]]
+	}
+	for k := 1; k < len(pivots); k++ {
+		a, b := pivots[k-1], pivots[k]
+		for i := a; i <= b; i++ {
+			result[i] = S[a] + (S[b]-S[a])*float64(i-a)/float64(b-a)
+		}
+	}
+	return result
+}
+
+// PEAK 之字转向 ZIG(S,N) 的前 M 个波峰值（未来函数），M=1 为最近一个，没有时为 NaN
+func PEAK(S Series, N float64, M int) Series {
+	return zigExtremes(S, N, M, func(v, neighbor float64) bool { return v > neighbor })
+}
+
+// TROUGH 之字转向 ZIG(S,N) 的前 M 个波谷值（未来函数），M=1 为最近一个，没有时为 NaN
+func TROUGH(S Series, N float64, M int) Series {
+	return zigExtremes(S, N, M, func(v, neighbor float64) bool { return v < neighbor })
+}
+
+// zigExtremes 取每根 K 线之前（含当前）第 M 个满足条件的转折点的值
+// 首尾转折点只和一侧比较
+func zigExtremes(S Series, N float64, M int, isExtreme func(v, neighbor float64) bool) Series {
+	result := nanSeries(len(S))
+	if M <= 0 {
+		return result
+	}
+	pivots := zigPivots(S, N)
+	var extremes []int
+	for k, idx := range pivots {
+		ok := true
+		if k > 0 && !isExtreme(S[idx], S[pivots[k-1]]) {
+			ok = false
+		}
+		if k < len(pivots)-1 && !isExtreme(S[idx], S[pivots[k+1]]) {
+			ok = false
+		}
+		if ok && len(pivots) > 1 {
+			extremes = append(extremes, idx)
+		}
+	}
+	next := 0
+	for i := range S {
+		for next < len(extremes) && extremes[next] <= i {
+			next++
+		}
+		if next-M >= 0 {
+			result[i] = S[extremes[next-M]]
+		}
+	}
+	return result
+}
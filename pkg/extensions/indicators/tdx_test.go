@@ -0,0 +1,161 @@
+package indicators
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTDXMathFunctions(t *testing.T) {
+	nan := math.NaN()
+	S := Series{-3.7, -1, 0, 2.5, nan}
+
+	tests := []struct {
+		name string
+		got  Series
+		want Series
+	}{
+		{"CEILING", CEILING(S), Series{-3, -1, 0, 3, nan}},
+		{"FLOOR", FLOOR(S), Series{-4, -1, 0, 2, nan}},
+		{"INTPART", INTPART(S), Series{-3, -1, 0, 2, nan}},
+		{"SIGN", SIGN(S), Series{-1, -1, 0, 1, nan}},
+		{"REVERSE", REVERSE(S), Series{3.7, 1, 0, -2.5, nan}},
+		{"MOD", MOD(Series{26, -7, 5, 5}, Series{10, 3, 0, nan}), Series{6, -1, nan, nan}},
+		{"MOD 标量", MOD(Series{26, 27}, Series{10}), Series{6, 7}},
+		{"EXP", EXP(Series{0, 1}), Series{1, math.E}},
+		{"LOG", LOG(Series{100, 1, 0, -1}), Series{2, 0, nan, nan}},
+		{"ATAN", ATAN(Series{1}), Series{math.Pi / 4}},
+		{"ASIN", ASIN(Series{1, 2}), Series{math.Pi / 2, nan}},
+		{"ACOS", ACOS(Series{1, -2}), Series{0, nan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !equalWithNaN(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTDXBarFunctions(t *testing.T) {
+	nan := math.NaN()
+	cond := []bool{false, true, false, false, true, false}
+
+	tests := []struct {
+		name string
+		got  Series
+		want Series
+	}{
+		{"BARSCOUNT", BARSCOUNT(Series{nan, nan, 10, 11, nan, 12}), Series{nan, nan, 1, 2, 3, 4}},
+		{"BARSSINCE", BARSSINCE(cond), Series{nan, 0, 1, 2, 3, 4}},
+		{"CURRBARSCOUNT", CURRBARSCOUNT(Series{1, 2, 3}), Series{3, 2, 1}},
+		{"BACKSET", BACKSET(cond, Series{2}), Series{1, 1, 0, 1, 1, 0}},
+		{"BACKSET 序列", BACKSET(cond, Series{0, 3, 0, 0, 1, 0}), Series{1, 1, 0, 0, 1, 0}},
+		// 从当前向前累加到 >=10：6+5，7+6，1+7+6，2+1+7
+		{"SUMBARS", SUMBARS(Series{5, 6, 7, 1, 2, 20}, Series{10}), Series{0, 2, 2, 3, 3, 1}},
+		{"HOD", HOD(Series{3, 5, 4, 6, 1}, 3), Series{1, 1, 2, 1, 3}},
+		{"LOD", LOD(Series{3, 5, 4, 6, 1}, 0), Series{1, 2, 2, 4, 1}},
+		{"REFX", REFX(Series{1, 2, 3, 4}, 2), Series{3, 4, nan, nan}},
+		{"REFX 序列", REFX(Series{1, 2, 3, 4}, Series{1, 0, 1, 1}), Series{2, 2, 4, nan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !equalWithNaN(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTDXAverages(t *testing.T) {
+	nan := math.NaN()
+
+	// 起始值为前 3 根的平均 2，之后 Y=(X+2Y')/3
+	mema := MEMA(Series{1, 2, 3, 5, nan, 8}, 3)
+	if !equalWithNaN(mema, Series{nan, nan, 2, 3, 3, 14.0 / 3}) {
+		t.Errorf("MEMA = %v", mema)
+	}
+
+	// 居中窗口，两端用已有数据
+	xma := XMA(Series{1, 2, 3, 4, 5}, 3)
+	if !equalWithNaN(xma, Series{1.5, 2, 3, 4, 4.5}) {
+		t.Errorf("XMA = %v", xma)
+	}
+}
+
+func TestTDXConditions(t *testing.T) {
+	nan := math.NaN()
+
+	rng := RANGE(Series{5, 1, 10, nan}, Series{1}, Series{10})
+	if want := []bool{true, false, false, false}; !reflect.DeepEqual(rng, want) {
+		t.Errorf("RANGE = %v, want %v", rng, want)
+	}
+
+	down := CROSSDOWN(Series{3, 1, 3, 3, nan, 1}, Series{2})
+	if want := []bool{false, true, false, false, false, false}; !reflect.DeepEqual(down, want) {
+		t.Errorf("CROSSDOWN = %v, want %v", down, want)
+	}
+	if !reflect.DeepEqual(CROSSDOWN(Series{3, 1, 2}, Series{2}), CROSS(Series{2, 2, 2}, Series{3, 1, 2})) {
+		t.Errorf("CROSSDOWN(A,B) should equal CROSS(B,A)")
+	}
+}
+
+func TestTDXZigzag(t *testing.T) {
+	nan := math.NaN()
+	S := Series{nan, 10, 11, 12, 10, 9, 10, 12, 13, 12.5}
+
+	// 10% 转向：10 → 12 (上涨 20%) → 9 (下跌 25%) → 13 (上涨 44%)，末尾 12.5 没有达到转向幅度，作为最后一个转折点
+	zig := ZIG(S, 10)
+	want := Series{nan, 10, 11, 12, 10.5, 9, 31.0 / 3, 35.0 / 3, 13, 12.5}
+	for i := range want {
+		if !equalWithNaN(Series{zig[i]}, Series{want[i]}) && math.Abs(zig[i]-want[i]) > 1e-9 {
+			t.Errorf("ZIG[%d] = %v, want %v", i, zig[i], want[i])
+		}
+	}
+
+	peak := PEAK(S, 10, 1)
+	if !equalWithNaN(peak, Series{nan, nan, nan, 12, 12, 12, 12, 12, 13, 13}) {
+		t.Errorf("PEAK(1) = %v", peak)
+	}
+	if peak2 := PEAK(S, 10, 2); !equalWithNaN(peak2, Series{nan, nan, nan, nan, nan, nan, nan, nan, 12, 12}) {
+		t.Errorf("PEAK(2) = %v", peak2)
+	}
+	// 最后一个转折点只和前一个比较，12.5 < 13 也算波谷
+	trough := TROUGH(S, 10, 1)
+	if !equalWithNaN(trough, Series{nan, 10, 10, 10, 10, 9, 9, 9, 9, 12.5}) {
+		t.Errorf("TROUGH(1) = %v", trough)
+	}
+}
+
+func TestTDXRegisteredAndFlagged(t *testing.T) {
+	names := []string{"CEILING", "FLOOR", "INTPART", "MOD", "SIGN", "EXP", "LOG", "ATAN", "ASIN", "ACOS",
+		"REVERSE", "BACKSET", "BARSCOUNT", "BARSSINCE", "CURRBARSCOUNT", "SUMBARS", "REFX",
+		"ZIG", "PEAK", "TROUGH", "MEMA", "XMA", "HOD", "LOD", "RANGE", "CROSSDOWN"}
+	for _, name := range names {
+		if _, ok := GetAdapter(name); !ok {
+			t.Errorf("%s 没有注册适配器", name)
+		}
+	}
+	for _, name := range GetFutureFunctionNames() {
+		if _, ok := functionMap[name]; !ok {
+			t.Errorf("未来函数 %s 没有注册", name)
+		}
+	}
+	if !IsFutureFunction("REFX") || IsFutureFunction("REF") {
+		t.Errorf("IsFutureFunction 结果错误")
+	}
+
+	// 通过解释器的调用方式：标量和条件参数
+	ret, err := CallIndicator("MOD", []any{Series{26, 27}, 10.0})
+	if err != nil || !equalWithNaN(ret, Series{6, 7}) {
+		t.Errorf("MOD = %v, %v", ret, err)
+	}
+	ret, err = CallIndicator("CONST", []any{[]bool{true, false, true}})
+	if err != nil || !equalWithNaN(ret, Series{1, 1, 1}) {
+		t.Errorf("CONST 条件 = %v, %v", ret, err)
+	}
+	ret, err = CallIndicator("CONST", []any{5.0})
+	if err != nil || !equalWithNaN(ret, Series{5}) {
+		t.Errorf("CONST 标量 = %v, %v", ret, err)
+	}
+}
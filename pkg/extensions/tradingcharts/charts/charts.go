@@ -11,7 +11,6 @@ import (
 	"github.com/lyr-2000/mylang/pkg/api"
 	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
 	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
-	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/offline"
 	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
 	"github.com/spf13/cast"
 )
@@ -41,20 +40,39 @@ func (r *Ohlcv) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ChartSetting 声明式的图表配置，可以从 JSON 或 YAML 读取（见 LoadChartSetting），
+// 由 NewKlineChartFromSetting 结合 MaiExecutor 生成图表
 type ChartSetting struct {
-	Kline          []Ohlcv     `json:"kline"`
-	MainIndicators []Indicator `json:"main_indicators"`
-	SubCharts      []SubChart  `json:"sub_charts"`
-}
+	Title          string         `json:"title"`
+	Formula        string         `json:"formula"`     // 麦语言公式，为空时使用 executor 已经执行的结果
+	Period         string         `json:"period"`      // 周期，见 KlineChart.Period
+	ColorMode      KlineColorMode `json:"color_mode"`  // K 线颜色，默认 green_up_and_red_down
+	Height         float64        `json:"height"`      // 图表高度（像素），默认 904
+	MainHeight     float64        `json:"main_height"` // 主图的相对高度，默认 3
+	Kline          []Ohlcv        `json:"kline"`       // K 线，字段为 date、open、high、low、close、vol，为空时使用 executor 中的数据
+	MainIndicators []Indicator    `json:"main_indicators"`
+	SubCharts      []SubChart     `json:"sub_charts"` // 第 n 个副图对应修饰符 SUB<n>
+}
+
+// Indicator 一条指标线
 type Indicator struct {
-	Name   string      `json:"name"`
-	Type   string      `json:"type"`
-	Values [][]float64 `json:"values"`
+	Name   string      `json:"name"`   // 变量名，如公式的输出变量 MA5，或者成交量 V
+	Label  string      `json:"label"`  // 图例名称，默认为 Name
+	Type   string      `json:"type"`   // 画法：line（默认）、stick、colorstick、volstick、pointdot、crossdot，见 DrawKind
+	Color  string      `json:"color"`  // 颜色，如 red、#FF0000
+	Width  float64     `json:"width"`  // 线宽
+	Dot    bool        `json:"dot"`    // 虚线
+	Values [][]float64 `json:"values"` // 直接给出的数据，每行一条与 K 线等长的序列，不为空时不读取变量
 }
+
+// SubChart 一个副图
 type SubChart struct {
 	Name       string      `json:"name"`
+	Height     float64     `json:"height"` // 相对高度，默认 1
 	Indicators []Indicator `json:"indicators"`
+	Lines      []float64   `json:"lines"` // 水平参考线，如 RSI 的 30、70
 }
+
 type GraphSettings struct {
 	ChartSettings map[string]string
 }
@@ -67,14 +85,21 @@ func (r *GraphSettings) GetOrDefault(key string, defaultValue string) string {
 }
 
 type KlineChart struct {
-	Title          string
-	Tmp            map[int][]types.Trace
-	Executor       *api.MaiExecutor
-	Settings       *GraphSettings
-	TickFormatType string
-	KlineColorMode KlineColorMode
-	Period         string // 周期，如 "1d", "1h" 等，用于判断是否需要设置 rangebreaks
-	Calendar       grob.LayoutCalendar // 日历系统，默认为公历（Gregorian）
+	Title              string
+	Tmp                map[int][]types.Trace
+	Executor           *api.MaiExecutor
+	Settings           *GraphSettings
+	TickFormatType     string
+	KlineColorMode     KlineColorMode
+	Period             string                  // 周期，如 "1d", "1h", "5m" 等，写法见 api.ParsePeriod，用于计算 rangebreaks，为空时按 K 线间隔推断
+	DisableRangebreaks bool                    // 不隐藏非交易时间，见 applyRangebreaks
+	Calendar           grob.LayoutCalendar     // 日历系统，默认为公历（Gregorian）
+	Annotations        []grob.LayoutAnnotation // 文字标注，如 DRAWTEXT
+	Shapes             []grob.LayoutShape      // 图形，如 STICKLINE
+	HtmlOptions        []HtmlOption            // 生成 HTML 的选项，见 SetHtmlOptions
+	ImageOptions       ImageOptions            // 导出图片的选项，见 SetImageOptions
+	Height             float64                 // 图表高度（像素），0 为默认的 904
+	PaneHeights        []float64               // 主图和各个副图的相对高度，为空时等高，未给出的按 1 计算
 	// Fig      *grob.Fig
 	// KlineAlias map[string]string
 }
@@ -110,7 +135,7 @@ func (r *KlineChart) LayoutObj(title string) *grob.Fig {
 	if r.TickFormatType == "" {
 		r.SetTickFormatType(TickFormatTypeDateTime)
 	}
-	
+
 	// 根据 TickFormatType 设置 tickformat，使用纯数字格式
 	// Plotly 使用 d3-time-format 语法：
 	// %Y = 年份（4位数字）
@@ -126,32 +151,32 @@ func (r *KlineChart) LayoutObj(title string) *grob.Fig {
 		// 日期时间格式：YYYY-MM-DD HH:MM
 		tickFormat = "%Y-%m-%d %H:%M"
 	}
-	
+
 	// 使用 date 类型，Plotly 会自动解析日期字符串
 	xaxis := &grob.LayoutXaxis{
 		Rangeslider: &grob.LayoutXaxisRangeslider{
 			Visible: types.False,
 		},
-		Tickmode:  "auto",
-		Type:      grob.LayoutXaxisTypeDate, // 设置为 date 类型，Plotly 会自动解析日期字符串
-		Tickformat: types.S(tickFormat),     // 设置纯数字格式，避免显示月份缩写
+		Tickmode:   "auto",
+		Type:       grob.LayoutXaxisTypeDate, // 设置为 date 类型，Plotly 会自动解析日期字符串
+		Tickformat: types.S(tickFormat),      // 设置纯数字格式，避免显示月份缩写
 	}
-	
+
 	xaxis2 := &grob.LayoutXaxis{
 		Rangeslider: &grob.LayoutXaxisRangeslider{
 			Visible: types.False,
 		},
-		Tickmode:  "auto",
-		Type:      grob.LayoutXaxisTypeDate, // 设置为 date 类型，Plotly 会自动解析日期字符串
-		Tickformat: types.S(tickFormat),     // 设置纯数字格式，避免显示月份缩写
+		Tickmode:   "auto",
+		Type:       grob.LayoutXaxisTypeDate, // 设置为 date 类型，Plotly 会自动解析日期字符串
+		Tickformat: types.S(tickFormat),      // 设置纯数字格式，避免显示月份缩写
 	}
-	
+
 	return &grob.Fig{
 		Layout: &grob.Layout{
 			Title: &grob.LayoutTitle{
 				Text: types.StringType(title),
 			},
-			Xaxis: xaxis,
+			Xaxis:  xaxis,
 			XAxis2: xaxis2,
 			// Yaxis: &grob.LayoutYaxis{
 			// 	// Tickformat:  ".6",
@@ -159,7 +184,7 @@ func (r *KlineChart) LayoutObj(title string) *grob.Fig {
 			// 	//tickFormat settings  https://community.plotly.com/t/how-to-change-the-way-large-numbers-are-displayed/72363
 			// },
 			Autosize: types.True,
-			Height:   types.N(904),
+			Height:   types.N(r.height()),
 			Calendar: r.getCalendar(),
 			Grid: &grob.LayoutGrid{
 				Rows:    types.I(2),
@@ -173,6 +198,13 @@ func (r *KlineChart) LayoutObj(title string) *grob.Fig {
 	}
 }
 
+func (r *KlineChart) height() float64 {
+	if r.Height > 0 {
+		return r.Height
+	}
+	return 904
+}
+
 func NewKlineChart(executor *api.MaiExecutor, title string) *KlineChart {
 	return &KlineChart{
 		Executor: executor,
@@ -223,32 +255,58 @@ func (r KlineColorMode) SetColor(x *grob.Candlestick) {
 	}
 }
 
-func (r *KlineChart) AsHtml(writePath string, opts ...FigSettingOpt) {
+// SetHtmlOptions 设置 AsHtml、AsHtmlString 生成 HTML 的选项
+// 默认从 CDN 加载 plotly.js，在没有网络的机器上查看时使用 EmbedPlotlyJS() 或 PlotlyJSFile(path) 把 plotly.js 写入 HTML
+func (r *KlineChart) SetHtmlOptions(opts ...HtmlOption) {
+	r.HtmlOptions = opts
+}
+
+// AsHtml 把图表写入 HTML 文件，读取 plotly.js 或写文件失败时返回错误
+func (r *KlineChart) AsHtml(writePath string, opts ...FigSettingOpt) error {
 	w := r.ObjInit(opts...)
-	offline.ToHtml(w, writePath)
+	return WriteHtml(w, writePath, r.HtmlOptions...)
 }
 
-// AsHtmlString 直接返回 HTML 字符串，不写入文件（更高效）
-func (r *KlineChart) AsHtmlString(opts ...FigSettingOpt) string {
+// AsHtmlString 直接返回 HTML 字符串，不写入文件（更高效），读取 plotly.js 失败时返回错误
+func (r *KlineChart) AsHtmlString(opts ...FigSettingOpt) (string, error) {
 	w := r.ObjInit(opts...)
-	buf := offline.FigToBuffer(w)
+	buf, err := FigToHtml(w, r.HtmlOptions...)
+	if err != nil {
+		return "", err
+	}
 	htmlContent := buf.String()
-	
+
 	// 确保 HTML 包含 UTF-8 编码声明
 	if !strings.Contains(htmlContent, "charset") && !strings.Contains(htmlContent, "UTF-8") {
 		// 如果缺少 charset 声明，在 head 标签中添加
 		htmlContent = strings.Replace(htmlContent, "<head>", "<head>\n\t\t<meta charset=\"UTF-8\">", 1)
 	}
-	
-	return htmlContent
+
+	return htmlContent, nil
 }
 
 func (r *KlineChart) SetDefaultKlineChart() {
+	date := r.Executor.GetDateTimeArray()
+	volumeData := r.Executor.GetFloat64Array("V")
+	r.AddCharts(0, r.klineTrace())
+	r.AddCharts(1,
+		&grob.Bar{
+			Uid:       "2",
+			Name:      types.S("Volume"),
+			X:         types.DataArray(date),
+			Y:         types.DataArray(volumeData),
+			Xaxis:     types.S("x"),
+			Yaxis:     types.S("y2"),
+			Hovertext: types.ArrayOKArray[types.StringType](),
+		})
+}
+
+// klineTrace 由 O、H、L、C 和日期时间数组生成的 K 线
+func (r *KlineChart) klineTrace() *grob.Candlestick {
 	closeData := r.Executor.GetFloat64Array("C")
 	openData := r.Executor.GetFloat64Array("O")
 	highData := r.Executor.GetFloat64Array("H")
 	lowData := r.Executor.GetFloat64Array("L")
-	volumeData := r.Executor.GetFloat64Array("V")
 	// dateTime if set
 	date := r.Executor.GetDateTimeArray()
 	kl := &grob.Candlestick{
@@ -264,25 +322,14 @@ func (r *KlineChart) SetDefaultKlineChart() {
 		Hovertext: types.ArrayOKArray[types.StringType](pnl(openData, closeData)...),
 	}
 	r.KlineColorMode.SetColor(kl)
-	r.AddCharts(0, kl)
-	r.AddCharts(1,
-		&grob.Bar{
-			Uid:       "2",
-			Name:      types.S("Volume"),
-			X:         types.DataArray(date),
-			Y:         types.DataArray(volumeData),
-			Xaxis:     types.S("x"),
-			Yaxis:     types.S("y2"),
-			Hovertext: types.ArrayOKArray[types.StringType](),
-		})
-
+	return kl
 }
 
 func Size(k float64) *types.ArrayOK[*types.NumberType] {
 	return types.ArrayOKValue(types.N(k))
 }
 
-func Color(x string) *types.ArrayOK[*types.ColorWithColorScale]{
+func Color(x string) *types.ArrayOK[*types.ColorWithColorScale] {
 	return types.ArrayOKValue(types.UseColor("black"))
 }
 
@@ -332,7 +379,6 @@ func HoverTextArray(texts ...string) *types.ArrayOK[*types.StringType] {
 	return &types.ArrayOK[*types.StringType]{Array: arr}
 }
 
-
 func ArrayFromCond(b any, cond any) *types.DataArrayType {
 	// 转为数组，并且反射迭代。b 作为主数组, cond 是条件数组（如bool或0/1），当条件为true(或1等)时才输出
 	bVal := reflect.ValueOf(b)
@@ -359,9 +405,9 @@ func ArrayFromCond(b any, cond any) *types.DataArrayType {
 			// log.Printf("ArrayFromCond condElem %v %T %v", d,d,condElem.Kind())
 			// add = false
 			switch dx := d.(type) {
-			case float64,float32:
+			case float64, float32:
 				add = cast.ToInt(dx) != 0
-			case int,int32,byte,uint64,int8,uint16,int16,uint32:
+			case int, int32, byte, uint64, int8, uint16, int16, uint32:
 				add = cast.ToInt(dx) != 0
 			case bool:
 				add = dx
@@ -457,6 +503,7 @@ func (rb *KlineChart) AddMarker(idx int, name string, x *types.DataArrayType, y
 
 func (r *KlineChart) ObjInit(opts ...FigSettingOpt) *grob.Fig {
 	fig := r.LayoutObj(r.Title)
+	r.applyRangebreaks(fig.Layout)
 	type tmp struct {
 		w      int
 		slices []types.Trace
@@ -469,8 +516,10 @@ func (r *KlineChart) ObjInit(opts ...FigSettingOpt) *grob.Fig {
 		return tmpx[i].w < tmpx[j].w
 	})
 	var subplots [][]string
+	var panes []int
 	for _, slot := range tmpx {
 		w := slot.w
+		panes = append(panes, w)
 		fig.AddTraces(slot.slices...)
 		x := "xy"
 		if w != 0 {
@@ -482,6 +531,9 @@ func (r *KlineChart) ObjInit(opts ...FigSettingOpt) *grob.Fig {
 	fig.Layout.Grid.Rows = types.I(len(tmpx))
 	fig.Layout.Grid.Columns = types.I(1)
 	fig.Layout.Grid.Subplots = subplots
+	r.applyPaneHeights(fig.Layout, panes)
+	fig.Layout.Annotations = append(fig.Layout.Annotations, r.Annotations...)
+	fig.Layout.Shapes = append(fig.Layout.Shapes, r.Shapes...)
 	for _, opt := range opts {
 		opt(fig)
 	}
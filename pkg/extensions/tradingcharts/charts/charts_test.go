@@ -0,0 +1,97 @@
+package charts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+)
+
+func TestAsHtmlOffline(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-10"})
+	executor.SetVar("C", []float64{11, 10})
+	chart := NewKlineChart(executor, "test")
+	chart.AddMainCharts()
+
+	if html, err := chart.AsHtmlString(); err != nil || !strings.Contains(html, "cdn.plot.ly") {
+		t.Errorf("default html should load plotly.js from CDN, err = %v", err)
+	}
+
+	dir := t.TempDir()
+	js := filepath.Join(dir, "plotly.min.js")
+	if err := os.WriteFile(js, []byte("window.Plotly={newPlot:function(){}};"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chart.SetHtmlOptions(PlotlyJSFile(js))
+	out := filepath.Join(dir, "kline.html")
+	if err := chart.AsHtml(out); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html := string(b); strings.Contains(html, "cdn.plot.ly") || !strings.Contains(html, "window.Plotly={newPlot") {
+		t.Errorf("offline html = %s", html)
+	}
+
+	// 读取 plotly.js 失败时返回错误，不改为从 CDN 加载
+	chart.SetHtmlOptions(PlotlyJSFile(filepath.Join(dir, "missing.js")))
+	if html, err := chart.AsHtmlString(); err == nil || html != "" {
+		t.Errorf("AsHtmlString with a missing plotly.js should return an error, html = %q", html)
+	}
+}
+
+func TestFigToHtml(t *testing.T) {
+	fig := &grob.Fig{}
+	buf, err := FigToHtml(fig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html := buf.String(); !strings.Contains(html, `<script src="https://cdn.plot.ly/plotly-2.34.0.min.js"></script>`) {
+		t.Errorf("default html = %s", html)
+	}
+
+	js := filepath.Join(t.TempDir(), "plotly.min.js")
+	if err := os.WriteFile(js, []byte(`var Plotly={s:"</script>"};`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buf, err = FigToHtml(fig, PlotlyJSFile(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html := buf.String(); strings.Contains(html, "cdn.plot.ly") || !strings.Contains(html, `var Plotly={s:"<\/script>"};`) {
+		t.Errorf("inline html = %s", html)
+	}
+
+	if _, err := FigToHtml(fig, PlotlyJSFile(filepath.Join(t.TempDir(), "missing.js"))); err == nil {
+		t.Errorf("missing plotly.js file should return an error")
+	}
+	if err := WriteHtml(fig, filepath.Join(t.TempDir(), "no", "such", "dir.html")); err == nil {
+		t.Errorf("WriteHtml should return the write error")
+	}
+}
+
+func TestEmbedPlotlyJS(t *testing.T) {
+	fig := &grob.Fig{}
+	b, err := PlotlyJS(fig.Info())
+	if err != nil {
+		t.Fatalf("plotly-2.34.0.min.js should be embedded: %v", err)
+	}
+	buf, err := FigToHtml(fig, EmbedPlotlyJS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html := buf.String(); strings.Contains(html, "<script src=") || len(html) < len(b) {
+		t.Errorf("embedded html should inline plotly.js")
+	}
+
+	if _, err := PlotlyJS(types.Version{Name: "Plotly 0.0.0", Cdn: "https://cdn.plot.ly/plotly-0.0.0.min.js"}); err == nil {
+		t.Errorf("a version that is not embedded should return an error")
+	}
+}
@@ -0,0 +1,192 @@
+package charts
+
+import (
+	"math"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+)
+
+// iconStyle DRAWICON 图标编号对应的标记
+type iconStyle struct {
+	symbol grob.ScatterMarkerSymbol
+	color  string
+}
+
+// drawIcons 通达信常用的图标：1 买入箭头，2 卖出箭头，其余按形状区分，未知编号画蓝色圆点
+var drawIcons = map[int]iconStyle{
+	1: {grob.ScatterMarkerSymbolTriangleUp, "red"},
+	2: {grob.ScatterMarkerSymbolTriangleDown, "green"},
+	3: {grob.ScatterMarkerSymbolCircle, "orange"},
+	4: {grob.ScatterMarkerSymbolCircle, "gray"},
+	5: {grob.ScatterMarkerSymbolStar, "red"},
+	6: {grob.ScatterMarkerSymbolStar, "green"},
+	7: {grob.ScatterMarkerSymbolDiamond, "red"},
+	8: {grob.ScatterMarkerSymbolDiamond, "green"},
+	9: {grob.ScatterMarkerSymbolSquare, "orange"},
+}
+
+// AddDrawings 把公式执行时画图函数记录的命令画到图上，executor 为 nil 时使用 r.Executor
+// DRAWTEXT 画成文字标注，DRAWICON 画成散点标记，STICKLINE 画成线段，
+// DRAWKLINE 画成 K 线，PARTLINE 按颜色分段画线。主图或副图和颜色由语句的修饰符决定，见 ParseDrawStyle
+func (r *KlineChart) AddDrawings(executor *api.MaiExecutor) {
+	if executor == nil {
+		executor = r.Executor
+	}
+	dates := executor.GetDateTimeArray()
+	xAt := func(i int) any {
+		if i < len(dates) {
+			return dates[i]
+		}
+		return i
+	}
+	for _, d := range executor.GetDrawings() {
+		name := d.Name
+		if name == "" {
+			name = string(d.Kind)
+		}
+		style := ParseDrawStyle(name, d.SuffixParams)
+		if style.NoDraw {
+			continue
+		}
+		xref, yref := Xaxis(), Yaxis(style.Chart)
+		switch d.Kind {
+		case api.DrawingText:
+			color := style.Color
+			if color == "" {
+				color = "black"
+			}
+			for i, ok := range d.Cond {
+				if !ok || math.IsNaN(d.Price[i]) {
+					continue
+				}
+				r.Annotations = append(r.Annotations, grob.LayoutAnnotation{
+					X:         xAt(i),
+					Y:         d.Price[i],
+					Xref:      grob.LayoutAnnotationXref(xref),
+					Yref:      grob.LayoutAnnotationYref(yref),
+					Text:      types.S(d.Text),
+					Showarrow: types.False,
+					Font:      &grob.LayoutAnnotationFont{Color: types.C(color)},
+				})
+			}
+		case api.DrawingIcon:
+			icon, ok := drawIcons[d.Icon]
+			if !ok {
+				icon = iconStyle{grob.ScatterMarkerSymbolCircle, "blue"}
+			}
+			if style.Color != "" {
+				icon.color = style.Color
+			}
+			var x, y []any
+			for i, ok := range d.Cond {
+				if ok && !math.IsNaN(d.Price[i]) {
+					x, y = append(x, xAt(i)), append(y, d.Price[i])
+				}
+			}
+			r.AddCharts(style.Chart, &grob.Scatter{
+				Name:  types.S(style.Name),
+				X:     types.DataArray(x),
+				Y:     types.DataArray(y),
+				Mode:  grob.ScatterModeMarkers,
+				Xaxis: types.S(xref),
+				Yaxis: types.S(yref),
+				Marker: &grob.ScatterMarker{
+					Symbol: types.ArrayOKValue(icon.symbol),
+					Color:  types.ArrayOKValue(types.UseColor(types.C(icon.color))),
+					Size:   Size(10),
+				},
+			})
+		case api.DrawingStickLine:
+			up, down := r.upDownColors()
+			width := math.Max(d.Width, 1)
+			dash := ""
+			switch {
+			case d.Empty == -1:
+				dash = "dot"
+			case d.Empty != 0:
+				width = 1
+			}
+			for i, ok := range d.Cond {
+				if !ok || math.IsNaN(d.Price[i]) || math.IsNaN(d.Price2[i]) {
+					continue
+				}
+				color := style.Color
+				if color == "" {
+					color = up
+					if d.Price2[i] < d.Price[i] {
+						color = down
+					}
+				}
+				r.Shapes = append(r.Shapes, grob.LayoutShape{
+					Type:  grob.LayoutShapeTypeLine,
+					X0:    xAt(i),
+					X1:    xAt(i),
+					Y0:    d.Price[i],
+					Y1:    d.Price2[i],
+					Xref:  grob.LayoutShapeXref(xref),
+					Yref:  grob.LayoutShapeYref(yref),
+					Line:  &grob.LayoutShapeLine{Color: types.C(color), Width: types.N(width), Dash: types.S(dash)},
+					Layer: grob.LayoutShapeLayerAbove,
+				})
+			}
+		case api.DrawingKline:
+			x := make([]any, len(d.Close))
+			for i := range x {
+				x[i] = xAt(i)
+			}
+			kl := &grob.Candlestick{
+				Name:  types.S(style.Name),
+				X:     types.DataArray(x),
+				High:  Array(d.High),
+				Open:  Array(d.Open),
+				Low:   Array(d.Low),
+				Close: Array(d.Close),
+				Xaxis: types.S(xref),
+				Yaxis: types.S(yref),
+			}
+			r.KlineColorMode.SetColor(kl)
+			r.AddCharts(style.Chart, kl)
+		case api.DrawingPartLine:
+			for _, tr := range partLineTraces(style, d, xAt) {
+				tr.Xaxis, tr.Yaxis = types.S(xref), types.S(yref)
+				r.AddCharts(style.Chart, tr)
+			}
+		}
+	}
+}
+
+// partLineTraces 每种颜色一条线，第 i 根 K 线的颜色决定 i-1 到 i 这一段
+func partLineTraces(style DrawStyle, d api.Drawing, xAt func(int) any) []*grob.Scatter {
+	var order []float64
+	ys := map[float64][]any{}
+	for i := 1; i < len(d.Price); i++ {
+		c := d.Colors[i]
+		if math.IsNaN(c) || math.IsNaN(d.Price[i-1]) || math.IsNaN(d.Price[i]) {
+			continue
+		}
+		y, ok := ys[c]
+		if !ok {
+			y = make([]any, len(d.Price))
+			order = append(order, c)
+		}
+		y[i-1], y[i] = d.Price[i-1], d.Price[i]
+		ys[c] = y
+	}
+	x := make([]any, len(d.Price))
+	for i := range x {
+		x[i] = xAt(i)
+	}
+	var traces []*grob.Scatter
+	for _, c := range order {
+		traces = append(traces, &grob.Scatter{
+			Name: types.S(style.Name),
+			X:    types.DataArray(x),
+			Y:    types.DataArray(ys[c]),
+			Mode: grob.ScatterModeLines,
+			Line: &grob.ScatterLine{Color: types.C(api.RGBHex(c)), Width: types.N(math.Max(style.LineWidth, 1))},
+		})
+	}
+	return traces
+}
@@ -0,0 +1,63 @@
+package charts
+
+import (
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+)
+
+func TestAddDrawings(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-10", "2024-04-11"})
+	executor.SetVar("O", []float64{10, 11, 12})
+	executor.SetVar("H", []float64{12, 12, 14})
+	executor.SetVar("L", []float64{9, 10, 11})
+	executor.SetVar("C", []float64{11, 10, 13})
+	err := executor.RunCode(`
+DRAWTEXT(C>O,H,'B'),COLORRED;
+DRAWICON(C<O,L,2);
+STICKLINE(1,O,C,2,-1),SUB1;
+K:DRAWKLINE(H,O,L,C),SUB2;
+PARTLINE(C,C>O,RGB(255,0,0),C<=O,RGB(0,255,0));
+HIDE:DRAWICON(1,C,1),NODRAW;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart := NewKlineChart(executor, "test")
+	chart.AddDrawings(nil)
+
+	if len(chart.Annotations) != 2 || chart.Annotations[0].X != "2024-04-09" || chart.Annotations[1].Y != 14.0 ||
+		chart.Annotations[0].Text != "B" || chart.Annotations[0].Font.Color != types.C("red") {
+		t.Errorf("annotations = %+v", chart.Annotations)
+	}
+	icon := chart.Tmp[0][0].(*grob.Scatter)
+	if x := icon.X.Value().([]any); len(x) != 1 || x[0] != "2024-04-10" || *icon.Marker.Symbol.Value != grob.ScatterMarkerSymbolTriangleDown {
+		t.Errorf("DRAWICON trace = %+v", icon)
+	}
+	if len(chart.Shapes) != 3 || chart.Shapes[0].Yref != "y2" || chart.Shapes[0].Line.Dash != "dot" || *chart.Shapes[0].Line.Width != 2 {
+		t.Errorf("shapes = %+v", chart.Shapes)
+	}
+	if _, ok := chart.Tmp[2][0].(*grob.Candlestick); !ok {
+		t.Errorf("DRAWKLINE trace = %+v", chart.Tmp[2])
+	}
+
+	// 第 2 根下跌，第 3 根上涨：绿色一段 0-1，红色一段 1-2
+	if len(chart.Tmp[0]) != 3 {
+		t.Fatalf("main traces = %d", len(chart.Tmp[0]))
+	}
+	green, red := chart.Tmp[0][1].(*grob.Scatter), chart.Tmp[0][2].(*grob.Scatter)
+	if green.Line.Color != "#00FF00" || red.Line.Color != "#FF0000" {
+		t.Errorf("PARTLINE colors = %s, %s", green.Line.Color, red.Line.Color)
+	}
+	if y := red.Y.Value().([]any); y[0] != nil || y[1] != 10.0 || y[2] != 13.0 {
+		t.Errorf("PARTLINE red = %v", y)
+	}
+
+	fig := chart.ObjInit()
+	if len(fig.Layout.Annotations) != 2 || len(fig.Layout.Shapes) != 3 {
+		t.Errorf("layout annotations = %d, shapes = %d", len(fig.Layout.Annotations), len(fig.Layout.Shapes))
+	}
+}
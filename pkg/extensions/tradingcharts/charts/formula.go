@@ -0,0 +1,270 @@
+package charts
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+	"github.com/lyr-2000/mylang/pkg/mylang"
+	"github.com/spf13/cast"
+)
+
+// DrawKind 输出变量的画法
+type DrawKind string
+
+const (
+	DrawLine       DrawKind = "LINE"       // 默认折线
+	DrawStick      DrawKind = "STICK"      // 柱线
+	DrawColorStick DrawKind = "COLORSTICK" // 红绿柱，大于等于 0 为上涨颜色
+	DrawVolStick   DrawKind = "VOLSTICK"   // 成交量柱，按 K 线涨跌着色
+	DrawPointDot   DrawKind = "POINTDOT"   // 小圆点
+	DrawCrossDot   DrawKind = "CROSSDOT"   // 小叉
+)
+
+// cssColors 通达信颜色中与 CSS 颜色同名的部分，其余使用 mylang.Modifier 的 #RRGGBB
+var cssColors = map[string]string{
+	"RED":     "red",
+	"GREEN":   "green",
+	"BLUE":    "blue",
+	"WHITE":   "white",
+	"BLACK":   "black",
+	"GRAY":    "gray",
+	"YELLOW":  "yellow",
+	"CYAN":    "cyan",
+	"MAGENTA": "magenta",
+	"ORANGE":  "orange",
+}
+
+// 变量名以 $G<n> 结尾时放到第 n 个副图，如 RSI$G2
+var groupNamePattern = regexp.MustCompile(`\$G([0-9]+)$`)
+
+// DrawStyle 由修饰符和变量名解析出的画法
+type DrawStyle struct {
+	Name      string   // 图例名称，去掉了 $G<n> 后缀
+	Kind      DrawKind // 画法
+	Color     string   // 颜色，空为默认颜色
+	LineWidth float64  // 线宽，0 为默认线宽
+	Dot       bool     // DOTLINE 虚线
+	NoDraw    bool     // NODRAW 不画
+	Chart     int      // 0 为主图，n 为第 n 个副图
+}
+
+// ParseDrawStyle 解析输出变量的画法
+// 主图或副图的选择：修饰符 MAIN / SUB<n> 优先，其次是变量名的 $G<n> 后缀，
+// 没有指定时 VOLSTICK 放在第 1 个副图（成交量），其余放在主图
+func ParseDrawStyle(name string, params []string) DrawStyle {
+	style := DrawStyle{Name: name, Kind: DrawLine, Chart: -1}
+	if m := groupNamePattern.FindStringSubmatch(name); m != nil {
+		style.Name = strings.TrimSuffix(name, m[0])
+		style.Chart = cast.ToInt(m[1])
+	}
+	// 无效的修饰符在编译时已经记录为 Diagnostic，这里忽略
+	mods, _ := mylang.ParseModifiers(params)
+	for _, m := range mods {
+		switch m.Kind {
+		case mylang.ModifierVisibility:
+			style.NoDraw = style.NoDraw || m.Name == "NODRAW"
+		case mylang.ModifierLineStyle:
+			switch m.Name {
+			case "DOTLINE", "DASHLINE":
+				style.Dot = true
+			case "LINESTICK":
+				style.Kind = DrawStick
+			case "CIRCLEDOT":
+				style.Kind = DrawPointDot
+			default:
+				style.Kind = DrawKind(m.Name)
+			}
+		case mylang.ModifierPane:
+			style.Chart = m.Value
+		case mylang.ModifierColor:
+			style.Color = m.Color
+			if c, ok := cssColors[m.Name]; ok {
+				style.Color = c
+			}
+		case mylang.ModifierLineThick:
+			if m.Value > 0 {
+				style.LineWidth = float64(m.Value)
+			}
+		}
+	}
+	if style.Chart < 0 {
+		style.Chart = 0
+		if style.Kind == DrawVolStick {
+			style.Chart = 1
+		}
+	}
+	return style
+}
+
+// AddFormulaOutputs 把公式中所有 : 输出变量按声明顺序画到图上，executor 为 nil 时使用 r.Executor
+// 画法由修饰符决定，见 ParseDrawStyle，NODRAW 的变量不画
+func (r *KlineChart) AddFormulaOutputs(executor *api.MaiExecutor) {
+	if executor == nil {
+		executor = r.Executor
+	}
+	dates := executor.GetDateTimeArray()
+	for _, name := range executor.GetOutputVariableNames() {
+		params, _ := executor.GetSuffixParams(name)
+		style := ParseDrawStyle(name, params)
+		if style.NoDraw {
+			continue
+		}
+		value, ok := executor.GetVariable(name)
+		if !ok {
+			continue
+		}
+		n := len(dates)
+		if n == 0 {
+			n = outputLen(value)
+		}
+		r.AddCharts(style.Chart, r.formulaTrace(executor, style, Array(dates), outputValues(value, n)))
+	}
+}
+
+func (r *KlineChart) formulaTrace(executor *api.MaiExecutor, style DrawStyle, x *types.DataArrayType, y []float64) types.Trace {
+	xaxis, yaxis := types.S(Xaxis()), types.S(Yaxis(style.Chart))
+	switch style.Kind {
+	case DrawStick, DrawColorStick, DrawVolStick:
+		bar := &grob.Bar{
+			Name:  types.S(style.Name),
+			X:     x,
+			Y:     Array(y),
+			Xaxis: xaxis,
+			Yaxis: yaxis,
+		}
+		switch style.Kind {
+		case DrawColorStick:
+			bar.Marker = &grob.BarMarker{Color: r.stickColors(y, func(i int) bool { return y[i] >= 0 })}
+		case DrawVolStick:
+			open, close := priceArray(executor, "O", "OPEN"), priceArray(executor, "C", "CLOSE")
+			bar.Marker = &grob.BarMarker{Color: r.stickColors(y, func(i int) bool {
+				return i < len(open) && i < len(close) && close[i] >= open[i]
+			})}
+		default:
+			if style.Color != "" {
+				bar.Marker = &grob.BarMarker{Color: types.ArrayOKValue(types.UseColor(types.C(style.Color)))}
+			}
+		}
+		return bar
+	case DrawPointDot, DrawCrossDot:
+		marker := &grob.ScatterMarker{Size: Size(4)}
+		if style.Kind == DrawCrossDot {
+			marker.Size = Size(6)
+			marker.Symbol = types.ArrayOKValue(grob.ScatterMarkerSymbolX)
+		}
+		if style.Color != "" {
+			marker.Color = types.ArrayOKValue(types.UseColor(types.C(style.Color)))
+		}
+		return &grob.Scatter{
+			Name:   types.S(style.Name),
+			X:      x,
+			Y:      Array(y),
+			Mode:   grob.ScatterModeMarkers,
+			Marker: marker,
+			Xaxis:  xaxis,
+			Yaxis:  yaxis,
+		}
+	}
+	line := &grob.ScatterLine{}
+	if style.Color != "" {
+		line.Color = types.C(style.Color)
+	}
+	if style.LineWidth > 0 {
+		line.Width = types.N(style.LineWidth)
+	}
+	if style.Dot {
+		line.Dash = types.S("dot")
+	}
+	return &grob.Scatter{
+		Name:  types.S(style.Name),
+		X:     x,
+		Y:     Array(y),
+		Mode:  grob.ScatterModeLines,
+		Line:  line,
+		Xaxis: xaxis,
+		Yaxis: yaxis,
+	}
+}
+
+// upDownColors 与 K 线颜色模式一致的上涨、下跌颜色
+func (r *KlineChart) upDownColors() (string, string) {
+	switch r.KlineColorMode {
+	case "", GreenUpAndRedDown:
+		return "green", "red"
+	}
+	return "red", "green"
+}
+
+func (r *KlineChart) stickColors(y []float64, isUp func(i int) bool) *types.ArrayOK[*types.ColorWithColorScale] {
+	up, down := r.upDownColors()
+	colors := make([]types.Color, len(y))
+	for i := range y {
+		if isUp(i) {
+			colors[i] = types.C(up)
+		} else {
+			colors[i] = types.C(down)
+		}
+	}
+	return types.ArrayOKArray(types.UseColors(colors)...)
+}
+
+func priceArray(executor *api.MaiExecutor, names ...string) []float64 {
+	for _, name := range names {
+		if d := executor.GetFloat64Array(name); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+func outputLen(v any) int {
+	switch x := v.(type) {
+	case indicators.Series:
+		return len(x)
+	case []float64:
+		return len(x)
+	case []bool:
+		return len(x)
+	case float64, int, bool:
+		return 1
+	}
+	return 0
+}
+
+// outputValues 把输出变量转为长度为 n 的数值，条件为 1/0，标量按 n 广播
+func outputValues(v any, n int) []float64 {
+	var src []float64
+	switch x := v.(type) {
+	case indicators.Series:
+		src = x
+	case []float64:
+		src = x
+	case []bool:
+		src = make([]float64, len(x))
+		for i, b := range x {
+			if b {
+				src[i] = 1
+			}
+		}
+	case bool:
+		src = []float64{0}
+		if x {
+			src[0] = 1
+		}
+	default:
+		f, err := cast.ToFloat64E(v)
+		if err != nil {
+			f = math.NaN()
+		}
+		src = []float64{f}
+	}
+	if len(src) == 1 && n > 1 {
+		return FloatRepeatToArray(src[0], n)
+	}
+	return src
+}
@@ -0,0 +1,82 @@
+package charts
+
+import (
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+)
+
+func TestParseDrawStyle(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []string
+		want   DrawStyle
+	}{
+		{"MA5", nil, DrawStyle{Name: "MA5", Kind: DrawLine}},
+		{"MA5", []string{"COLORRED", "LINETHICK2", "DOTLINE"}, DrawStyle{Name: "MA5", Kind: DrawLine, Color: "red", LineWidth: 2, Dot: true}},
+		{"X", []string{"COLOR0000FF"}, DrawStyle{Name: "X", Kind: DrawLine, Color: "#FF0000"}},
+		{"X", []string{"nodraw"}, DrawStyle{Name: "X", Kind: DrawLine, NoDraw: true}},
+		{"RSI$G2", nil, DrawStyle{Name: "RSI", Kind: DrawLine, Chart: 2}},
+		{"RSI$G2", []string{"MAIN"}, DrawStyle{Name: "RSI", Kind: DrawLine, Chart: 0}},
+		{"MACD", []string{"COLORSTICK", "SUB3"}, DrawStyle{Name: "MACD", Kind: DrawColorStick, Chart: 3}},
+		{"VOL", []string{"VOLSTICK"}, DrawStyle{Name: "VOL", Kind: DrawVolStick, Chart: 1}},
+		{"B", []string{"STICK", "POINTDOT"}, DrawStyle{Name: "B", Kind: DrawPointDot}},
+		{"S", []string{"CROSSDOT", "LINETHICK0"}, DrawStyle{Name: "S", Kind: DrawCrossDot}},
+	}
+	for _, tt := range tests {
+		if got := ParseDrawStyle(tt.name, tt.params); got != tt.want {
+			t.Errorf("ParseDrawStyle(%s, %v) = %+v, want %+v", tt.name, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestAddFormulaOutputs(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-10", "2024-04-11"})
+	executor.SetVar("O", []float64{10, 11, 12})
+	executor.SetVar("C", []float64{11, 10, 13})
+	err := executor.RunCode(`
+B:C-O,COLORSTICK,SUB2;
+A:C,COLORRED;
+HIDE:C*2,NODRAW;
+UP:C>O,POINTDOT;
+V:C*100,VOLSTICK;
+A2:=C;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart := NewKlineChart(executor, "test")
+	chart.AddFormulaOutputs(nil)
+
+	if len(chart.Tmp[0]) != 2 || len(chart.Tmp[1]) != 1 || len(chart.Tmp[2]) != 1 {
+		t.Fatalf("traces = %v", chart.Tmp)
+	}
+	line, ok := chart.Tmp[0][0].(*grob.Scatter)
+	if !ok || line.Name != "A" || line.Mode != grob.ScatterModeLines || line.Line.Color != types.C("red") {
+		t.Errorf("A trace = %+v", chart.Tmp[0][0])
+	}
+	dots, ok := chart.Tmp[0][1].(*grob.Scatter)
+	if !ok || dots.Mode != grob.ScatterModeMarkers || len(dots.Y.Value().([]any)) != 3 {
+		t.Errorf("UP trace = %+v", chart.Tmp[0][1])
+	}
+
+	// 默认颜色模式为绿涨红跌
+	colors := func(bar *grob.Bar) []types.Color {
+		var out []types.Color
+		for _, c := range bar.Marker.Color.Array {
+			out = append(out, *c.Color)
+		}
+		return out
+	}
+	stick := chart.Tmp[2][0].(*grob.Bar)
+	if got := colors(stick); len(got) != 3 || got[0] != "green" || got[1] != "red" || stick.Yaxis != "y3" {
+		t.Errorf("COLORSTICK colors = %v, yaxis = %s", got, stick.Yaxis)
+	}
+	vol := chart.Tmp[1][0].(*grob.Bar)
+	if got := colors(vol); len(got) != 3 || got[0] != "green" || got[1] != "red" || got[2] != "green" {
+		t.Errorf("VOLSTICK colors = %v", got)
+	}
+}
@@ -0,0 +1,163 @@
+package charts
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"text/template"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+)
+
+// plotlyJS 内置的 plotly.js，文件名取自对应版本 CDN 地址的文件名，见 plotlyjs/README.md
+//
+//go:embed plotlyjs
+var plotlyJS embed.FS
+
+// HtmlOptions 生成 HTML 时加载 plotly.js 的方式，默认从 CDN 加载
+type HtmlOptions struct {
+	EmbedPlotlyJS bool   // 使用内置的 plotly.js，与图表的 plotly 版本一致
+	PlotlyJSPath  string // 从本地文件读取 plotly.js，优先于 EmbedPlotlyJS
+}
+
+type HtmlOption func(o *HtmlOptions)
+
+// EmbedPlotlyJS 把内置的 plotly.js 写入 HTML
+func EmbedPlotlyJS() HtmlOption {
+	return func(o *HtmlOptions) {
+		o.EmbedPlotlyJS = true
+	}
+}
+
+// PlotlyJSFile 把本地文件 path 中的 plotly.js 写入 HTML
+func PlotlyJSFile(path string) HtmlOption {
+	return func(o *HtmlOptions) {
+		o.PlotlyJSPath = path
+	}
+}
+
+// PlotlyJS 返回内置的 version 版本的 plotly.js，没有内置时返回错误
+func PlotlyJS(version types.Version) ([]byte, error) {
+	name := path.Base(version.Cdn)
+	if version.Cdn == "" || name == "." || name == "/" {
+		return nil, fmt.Errorf("plotly version %q has no CDN url", version.Name)
+	}
+	b, err := plotlyJS.ReadFile("plotlyjs/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("plotly.js %s is not embedded, add it to charts/plotlyjs or use PlotlyJSFile: %w", name, err)
+	}
+	return b, nil
+}
+
+// plotlyScript 按选项返回要写入 HTML 的 plotly.js，从 CDN 加载时返回空字符串
+func plotlyScript(version types.Version, opts ...HtmlOption) (string, error) {
+	var o HtmlOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var (
+		b   []byte
+		err error
+	)
+	switch {
+	case o.PlotlyJSPath != "":
+		b, err = os.ReadFile(o.PlotlyJSPath)
+	case o.EmbedPlotlyJS:
+		b, err = PlotlyJS(version)
+	default:
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	// 写在 <script> 标签里，不能提前出现 </script
+	return string(bytes.ReplaceAll(b, []byte("</script"), []byte(`<\/script`))), nil
+}
+
+// FigToHtml 把图表生成为单个 HTML 文件的内容，plotly.js 的加载方式见 HtmlOptions
+func FigToHtml(fig types.Fig, opts ...HtmlOption) (*bytes.Buffer, error) {
+	figBytes, err := json.Marshal(fig)
+	if err != nil {
+		return nil, err
+	}
+	script, err := plotlyScript(fig.Info(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	data := struct {
+		Version    types.Version
+		PlotlyJS   string
+		B64Content string
+	}{
+		Version:  fig.Info(),
+		PlotlyJS: script,
+		// base64 编码，避免特殊字符的问题
+		B64Content: base64.StdEncoding.EncodeToString(figBytes),
+	}
+	buf := &bytes.Buffer{}
+	if err := singleFileTemplate.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// WriteHtml 把图表写入 HTML 文件，见 FigToHtml
+func WriteHtml(fig types.Fig, writePath string, opts ...HtmlOption) error {
+	buf, err := FigToHtml(fig, opts...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(writePath, buf.Bytes(), 0o644)
+}
+
+var singleFileTemplate = template.Must(template.New("plotly").Parse(`
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="UTF-8" />
+		{{- if .PlotlyJS }}
+		<script type="text/javascript">{{ .PlotlyJS }}</script>
+		{{- else }}
+		<script src="{{ .Version.Cdn }}"></script>
+		{{- end }}
+	</head>
+	<body>
+		<div id="plot"></div>
+	<script>
+		data = JSON.parse(decodeURIComponent(escape(window.atob('{{ .B64Content }}'))))
+		Plotly.newPlot('plot', data, {}, {locale: 'zh-CN'});
+	</script>
+	</body>
+</html>
+`))
+
+// listenAndServe 运行 srv 直到 ctx 结束，然后关闭服务并等待请求处理完。
+// 请求的 context 派生自 ctx，SSE 这样的长连接也会随之结束
+func listenAndServe(ctx context.Context, srv *http.Server) error {
+	srv.BaseContext = func(net.Listener) context.Context { return ctx }
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,868 @@
+package charts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+	"github.com/spf13/cast"
+)
+
+// ImageOptions 导出 SVG、PNG 图片的选项
+type ImageOptions struct {
+	Width    int     // 宽度，默认 1200
+	Height   int     // 高度，默认使用 Layout.Height，没有设置时为 800
+	Scale    float64 // PNG 的缩放倍数，默认 1，高分屏可以用 2
+	FontFile string  // PNG 使用的 TrueType/OpenType 字体文件，默认 Go 字体，不含中文，需要中文时指定中文字体
+}
+
+// SetImageOptions 设置 AsSvg、AsPng 导出图片的选项
+func (r *KlineChart) SetImageOptions(opt ImageOptions) {
+	r.ImageOptions = opt
+}
+
+// AsSvg 不依赖浏览器，把图表导出为 SVG 文件
+// 支持 KlineChart 用到的 K 线、柱线、折线和散点、多个副图、图例、文字标注和线段
+func (r *KlineChart) AsSvg(writePath string, opts ...FigSettingOpt) error {
+	return os.WriteFile(writePath, r.AsSvgBytes(opts...), 0o644)
+}
+
+// AsSvgBytes 返回 SVG 内容
+func (r *KlineChart) AsSvgBytes(opts ...FigSettingOpt) []byte {
+	return FigToSvg(r.ObjInit(opts...), r.ImageOptions)
+}
+
+// AsPng 不依赖浏览器，把图表导出为 PNG 文件，画法与 AsSvg 相同
+func (r *KlineChart) AsPng(writePath string, opts ...FigSettingOpt) error {
+	var buf bytes.Buffer
+	if err := r.WritePng(&buf, opts...); err != nil {
+		return err
+	}
+	return os.WriteFile(writePath, buf.Bytes(), 0o644)
+}
+
+// WritePng 把 PNG 写入 w
+func (r *KlineChart) WritePng(w io.Writer, opts ...FigSettingOpt) error {
+	return FigToPng(r.ObjInit(opts...), w, r.ImageOptions)
+}
+
+// FigToSvg 把图表渲染为 SVG
+func FigToSvg(fig *grob.Fig, opt ImageOptions) []byte {
+	fr := newFigRenderer(fig, opt)
+	c := newSvgCanvas(fr.width, fr.height)
+	fr.render(c)
+	return c.bytes()
+}
+
+// FigToPng 把图表渲染为 PNG 写入 w
+func FigToPng(fig *grob.Fig, w io.Writer, opt ImageOptions) error {
+	fr := newFigRenderer(fig, opt)
+	c, err := newPngCanvas(fr.width, fr.height, opt)
+	if err != nil {
+		return err
+	}
+	fr.render(c)
+	return c.encode(w)
+}
+
+// canvas SVG 和 PNG 共用的绘图接口，坐标单位为像素，原点在左上角
+type canvas interface {
+	polyline(pts []point, color string, width float64, dash bool)
+	polygon(pts []point, fill string)
+	// text 画文字，y 为文字的垂直中心，anchor 为 start、middle 或 end
+	text(p point, s string, size float64, color string, anchor string)
+}
+
+type point struct{ x, y float64 }
+
+// plotlyColors plotly 默认的轨迹颜色
+var plotlyColors = []string{
+	"#636efa", "#EF553B", "#00cc96", "#ab63fa", "#FFA15A",
+	"#19d3f3", "#FF6692", "#B6E880", "#FF97FF", "#FECB52",
+}
+
+const (
+	imageFontSize  = 12
+	imageTitleSize = 17
+	gridColor      = "#e5e5e5"
+	axisTextColor  = "#444444"
+)
+
+// imageTrace 从 plotly 轨迹中取出的绘图数据
+type imageTrace struct {
+	kind       string // candlestick、bar、scatter
+	name       string
+	axis       string // y 轴，如 y、y2
+	showLegend bool
+	x          []int // 每个点在 x 轴上的位置
+	y          []float64
+	open       []float64
+	high       []float64
+	low        []float64
+	close      []float64
+	color      string
+	colors     []string // 每个点的颜色，如红绿柱
+	upColor    string
+	downColor  string
+	lines      bool
+	markers    bool
+	width      float64
+	dash       bool
+	symbol     string
+	symbols    []string // 每个点的标记，如开平仓的箭头
+	size       float64
+	fill       string // fill 为 tozeroy 时与 0 之间填充的颜色
+}
+
+// imagePane 一个主图或副图
+type imagePane struct {
+	axis     string
+	top      float64
+	bottom   float64
+	min, max float64
+	hasData  bool
+}
+
+type figRenderer struct {
+	fig           *grob.Fig
+	width, height float64
+	left, right   float64
+	xLabels       []string
+	xIndex        map[string]int
+	traces        []*imageTrace
+	panes         []*imagePane
+	paneByAxis    map[string]*imagePane
+}
+
+func newFigRenderer(fig *grob.Fig, opt ImageOptions) *figRenderer {
+	fr := &figRenderer{fig: fig, width: 1200, height: 800, paneByAxis: map[string]*imagePane{}}
+	if opt.Width > 0 {
+		fr.width = float64(opt.Width)
+	}
+	if opt.Height > 0 {
+		fr.height = float64(opt.Height)
+	} else if fig.Layout != nil && fig.Layout.Height != nil {
+		fr.height = *fig.Layout.Height
+	}
+	fr.buildXAxis()
+	fr.buildPanes()
+	for i, t := range fig.Data {
+		if it := fr.readTrace(t, i); it != nil {
+			fr.traces = append(fr.traces, it)
+		}
+	}
+	fr.computeRanges()
+	return fr
+}
+
+// buildXAxis 收集所有轨迹的 x 值作为类别，都是日期时间时按时间排序，否则按出现顺序
+// 与 plotly 的日期轴不同，停牌、周末等没有 K 线的日期不占位置
+func (fr *figRenderer) buildXAxis() {
+	var keys []string
+	values := map[string]any{}
+	for _, t := range fr.fig.Data {
+		for _, v := range traceX(t) {
+			k := fmt.Sprint(v)
+			if _, ok := values[k]; !ok {
+				values[k] = v
+				keys = append(keys, k)
+			}
+		}
+	}
+	times := make(map[string]time.Time, len(keys))
+	allTimes := len(keys) > 0
+	for _, k := range keys {
+		// 数字按序号处理，不当作时间戳
+		_, isNumber := values[k].(int)
+		t, ok := api.ParseDateTime(values[k], time.Local)
+		if !ok || isNumber {
+			allTimes = false
+			break
+		}
+		times[k] = t
+	}
+	if allTimes {
+		sort.SliceStable(keys, func(i, j int) bool { return times[keys[i]].Before(times[keys[j]]) })
+		hasClock := false
+		for _, t := range times {
+			if t.Hour() != 0 || t.Minute() != 0 {
+				hasClock = true
+				break
+			}
+		}
+		layout := "2006-01-02"
+		if hasClock {
+			layout = "2006-01-02 15:04"
+		}
+		fr.xLabels = make([]string, len(keys))
+		for i, k := range keys {
+			fr.xLabels[i] = times[k].Format(layout)
+		}
+	} else {
+		fr.xLabels = keys
+	}
+	fr.xIndex = make(map[string]int, len(keys))
+	for i, k := range keys {
+		fr.xIndex[k] = i
+	}
+}
+
+// traceX 轨迹的 x 值，没有 x 时使用序号
+func traceX(t types.Trace) []any {
+	var x *types.DataArrayType
+	var n int
+	switch t := t.(type) {
+	case *grob.Candlestick:
+		x, n = t.X, len(dataValues(t.Close))
+	case *grob.Bar:
+		x, n = t.X, len(dataValues(t.Y))
+	case *grob.Scatter:
+		x, n = t.X, len(dataValues(t.Y))
+	default:
+		return nil
+	}
+	if vals := dataValues(x); vals != nil {
+		return vals
+	}
+	out := make([]any, n)
+	for i := range out {
+		out[i] = i
+	}
+	return out
+}
+
+func dataValues(d *types.DataArrayType) []any {
+	if d == nil || d.Value() == nil {
+		return nil
+	}
+	return TransferToArray(d.Value(), false)
+}
+
+func dataFloats(d *types.DataArrayType) []float64 {
+	vals := dataValues(d)
+	out := make([]float64, len(vals))
+	for i, v := range vals {
+		f, err := cast.ToFloat64E(v)
+		if v == nil || err != nil {
+			f = math.NaN()
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// buildPanes 按 Layout.Grid.Subplots 的顺序排列主图和副图，没有设置时按轨迹的 y 轴出现顺序
+func (fr *figRenderer) buildPanes() {
+	var axes []string
+	if fr.fig.Layout != nil && fr.fig.Layout.Grid != nil {
+		if rows, ok := fr.fig.Layout.Grid.Subplots.([][]string); ok {
+			for _, row := range rows {
+				for _, sp := range row {
+					if i := strings.Index(sp, "y"); i >= 0 {
+						axes = append(axes, sp[i:])
+					}
+				}
+			}
+		}
+	}
+	for _, t := range fr.fig.Data {
+		axes = append(axes, traceYaxis(t))
+	}
+	for _, a := range axes {
+		if _, ok := fr.paneByAxis[a]; !ok {
+			p := &imagePane{axis: a}
+			fr.paneByAxis[a] = p
+			fr.panes = append(fr.panes, p)
+		}
+	}
+	if len(fr.panes) == 0 {
+		p := &imagePane{axis: "y"}
+		fr.paneByAxis["y"] = p
+		fr.panes = append(fr.panes, p)
+	}
+}
+
+func traceYaxis(t types.Trace) string {
+	var axis types.StringType
+	switch t := t.(type) {
+	case *grob.Candlestick:
+		axis = t.Yaxis
+	case *grob.Bar:
+		axis = t.Yaxis
+	case *grob.Scatter:
+		axis = t.Yaxis
+	}
+	if axis == "" {
+		return "y"
+	}
+	return string(axis)
+}
+
+func (fr *figRenderer) xPositions(t types.Trace) []int {
+	x := traceX(t)
+	out := make([]int, len(x))
+	for i, v := range x {
+		out[i] = fr.xIndex[fmt.Sprint(v)]
+	}
+	return out
+}
+
+func (fr *figRenderer) readTrace(t types.Trace, idx int) *imageTrace {
+	it := &imageTrace{axis: traceYaxis(t), showLegend: true, x: fr.xPositions(t), color: plotlyColors[idx%len(plotlyColors)]}
+	switch t := t.(type) {
+	case *grob.Candlestick:
+		if t.Visible == false || t.Visible == "legendonly" {
+			return nil
+		}
+		it.kind, it.name = "candlestick", string(t.Name)
+		it.showLegend = t.Showlegend == nil || *t.Showlegend
+		it.open, it.high, it.low, it.close = dataFloats(t.Open), dataFloats(t.High), dataFloats(t.Low), dataFloats(t.Close)
+		it.upColor, it.downColor = "#3D9970", "#FF4136"
+		if t.Increasing != nil && t.Increasing.Line != nil && t.Increasing.Line.Color != "" {
+			it.upColor = string(t.Increasing.Line.Color)
+		}
+		if t.Decreasing != nil && t.Decreasing.Line != nil && t.Decreasing.Line.Color != "" {
+			it.downColor = string(t.Decreasing.Line.Color)
+		}
+		it.color = it.upColor
+	case *grob.Bar:
+		if t.Visible == false || t.Visible == "legendonly" {
+			return nil
+		}
+		it.kind, it.name, it.y = "bar", string(t.Name), dataFloats(t.Y)
+		it.showLegend = t.Showlegend == nil || *t.Showlegend
+		if t.Marker != nil {
+			it.color, it.colors = arrayOKColors(t.Marker.Color, it.color)
+		}
+	case *grob.Scatter:
+		if t.Visible == false || t.Visible == "legendonly" {
+			return nil
+		}
+		it.kind, it.name, it.y = "scatter", string(t.Name), dataFloats(t.Y)
+		it.showLegend = t.Showlegend == nil || *t.Showlegend
+		mode := string(t.Mode)
+		it.lines = mode == "" || strings.Contains(mode, "lines")
+		it.markers = strings.Contains(mode, "markers")
+		it.width, it.size, it.symbol = 2, 6, "circle"
+		if t.Line != nil {
+			if t.Line.Color != "" {
+				it.color = string(t.Line.Color)
+			}
+			if t.Line.Width != nil {
+				it.width = *t.Line.Width
+			}
+			it.dash = t.Line.Dash != "" && t.Line.Dash != "solid"
+		}
+		if t.Marker != nil {
+			if !it.lines || t.Marker.Color != nil {
+				it.color, it.colors = arrayOKColors(t.Marker.Color, it.color)
+			}
+			if t.Marker.Size != nil && t.Marker.Size.Value != nil && *t.Marker.Size.Value != nil {
+				it.size = **t.Marker.Size.Value
+			}
+			if t.Marker.Symbol != nil && t.Marker.Symbol.Value != nil {
+				it.symbol = fmt.Sprint(*t.Marker.Symbol.Value)
+			}
+			if t.Marker.Symbol != nil {
+				for _, sym := range t.Marker.Symbol.Array {
+					it.symbols = append(it.symbols, fmt.Sprint(*sym))
+				}
+			}
+		}
+		if t.Fill == grob.ScatterFillTozeroy {
+			it.fill = string(t.Fillcolor)
+			if it.fill == "" {
+				it.fill = it.color
+			}
+		}
+	default:
+		return nil
+	}
+	return it
+}
+
+// arrayOKColors 取出单个颜色或者每个点的颜色
+func arrayOKColors(c *types.ArrayOK[*types.ColorWithColorScale], def string) (string, []string) {
+	if c == nil {
+		return def, nil
+	}
+	if c.Array != nil {
+		colors := make([]string, len(c.Array))
+		for i, v := range c.Array {
+			colors[i] = def
+			if v != nil && v.Color != nil {
+				colors[i] = string(*v.Color)
+			}
+		}
+		return def, colors
+	}
+	if c.Value != nil && c.Value.Color != nil {
+		return string(*c.Value.Color), nil
+	}
+	return def, nil
+}
+
+func (fr *figRenderer) computeRanges() {
+	include := func(axis string, vals ...float64) {
+		p, ok := fr.paneByAxis[axis]
+		if !ok {
+			return
+		}
+		for _, v := range vals {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if !p.hasData {
+				p.min, p.max, p.hasData = v, v, true
+				continue
+			}
+			p.min, p.max = math.Min(p.min, v), math.Max(p.max, v)
+		}
+	}
+	for _, t := range fr.traces {
+		switch t.kind {
+		case "candlestick":
+			include(t.axis, t.high...)
+			include(t.axis, t.low...)
+		case "bar":
+			include(t.axis, 0)
+			include(t.axis, t.y...)
+		default:
+			include(t.axis, t.y...)
+		}
+	}
+	if fr.fig.Layout != nil {
+		for _, a := range fr.fig.Layout.Annotations {
+			include(string(a.Yref), cast.ToFloat64(a.Y))
+		}
+		for _, s := range fr.fig.Layout.Shapes {
+			include(string(s.Yref), cast.ToFloat64(s.Y0), cast.ToFloat64(s.Y1))
+		}
+	}
+	for _, p := range fr.panes {
+		if !p.hasData {
+			p.min, p.max = 0, 1
+			continue
+		}
+		pad := (p.max - p.min) * 0.05
+		if pad == 0 {
+			pad = math.Max(math.Abs(p.max)*0.05, 1)
+		}
+		p.min, p.max = p.min-pad, p.max+pad
+	}
+}
+
+func (fr *figRenderer) render(c canvas) {
+	c.polygon(rectPoints(0, 0, fr.width, fr.height), "white")
+
+	top := 16.0
+	if fr.fig.Layout != nil && fr.fig.Layout.Title != nil && fr.fig.Layout.Title.Text != "" {
+		c.text(point{fr.width / 2, top + imageTitleSize/2}, string(fr.fig.Layout.Title.Text), imageTitleSize, "#222222", "middle")
+		top += imageTitleSize + 10
+	}
+	top = fr.renderLegend(c, top) + 8
+
+	fr.left, fr.right = 70, fr.width-20
+	bottom := fr.height - 40
+	const gap = 24.0
+	// 高度按 y 轴 domain 的比例分配，没有设置时等高
+	weights := make([]float64, len(fr.panes))
+	total := 0.0
+	for i, p := range fr.panes {
+		weights[i] = 1
+		if d, ok := fr.paneDomain(p.axis); ok {
+			weights[i] = d
+		}
+		total += weights[i]
+	}
+	avail := bottom - top - gap*float64(len(fr.panes)-1)
+	for i, p := range fr.panes {
+		p.top = top
+		if i > 0 {
+			p.top = fr.panes[i-1].bottom + gap
+		}
+		p.bottom = p.top + avail*weights[i]/total
+	}
+
+	xTicks := fr.xTicks()
+	for _, p := range fr.panes {
+		for _, v := range niceTicks(p.min, p.max, math.Max(2, math.Floor((p.bottom-p.top)/50))) {
+			y := fr.yPos(p, v)
+			c.polyline([]point{{fr.left, y}, {fr.right, y}}, gridColor, 1, false)
+			c.text(point{fr.left - 6, y}, formatTick(v, p.min, p.max), imageFontSize, axisTextColor, "end")
+		}
+		for _, i := range xTicks {
+			x := fr.xPos(float64(i))
+			c.polyline([]point{{x, p.top}, {x, p.bottom}}, gridColor, 1, false)
+		}
+		c.polyline([]point{{fr.left, p.bottom}, {fr.right, p.bottom}}, "#bbbbbb", 1, false)
+	}
+	last := fr.panes[len(fr.panes)-1]
+	for _, i := range xTicks {
+		c.text(point{fr.xPos(float64(i)), last.bottom + 14}, fr.xLabels[i], imageFontSize, axisTextColor, "middle")
+	}
+
+	fr.renderLayoutItems(c, true)
+	for _, t := range fr.traces {
+		if p, ok := fr.paneByAxis[t.axis]; ok {
+			fr.renderTrace(c, p, t)
+		}
+	}
+	fr.renderLayoutItems(c, false)
+}
+
+// paneDomain y 轴 domain 的跨度，如 y2 对应 Layout.YAxis2
+func (fr *figRenderer) paneDomain(axis string) (float64, bool) {
+	if fr.fig.Layout == nil {
+		return 0, false
+	}
+	idx := 0
+	if axis != "y" {
+		n, err := strconv.Atoi(strings.TrimPrefix(axis, "y"))
+		if err != nil {
+			return 0, false
+		}
+		idx = n - 1
+	}
+	y := layoutYaxis(fr.fig.Layout, idx, false)
+	if y == nil {
+		return 0, false
+	}
+	d, ok := y.Domain.([]float64)
+	if !ok || len(d) != 2 || d[1] <= d[0] {
+		return 0, false
+	}
+	return d[1] - d[0], true
+}
+
+// renderLegend 在标题下方按行排列图例，返回图例底部的位置
+func (fr *figRenderer) renderLegend(c canvas, top float64) float64 {
+	if fr.fig.Layout != nil && fr.fig.Layout.Legend != nil && fr.fig.Layout.Legend.Visible != nil && !*fr.fig.Layout.Legend.Visible {
+		return top
+	}
+	x, y := 70.0, top+8
+	drawn := false
+	for _, t := range fr.traces {
+		if !t.showLegend || t.name == "" {
+			continue
+		}
+		w := 30 + textWidth(t.name, imageFontSize) + 16
+		if x+w > fr.width-20 && x > 70 {
+			x, y = 70, y+20
+		}
+		switch {
+		case t.kind == "scatter" && t.lines:
+			c.polyline([]point{{x, y}, {x + 22, y}}, t.color, math.Min(t.width, 4), t.dash)
+		case t.kind == "scatter":
+			c.polygon(symbolPoints(t.symbol, x+11, y, 8), t.color)
+		default:
+			c.polygon(rectPoints(x+5, y-5, x+17, y+5), t.color)
+		}
+		c.text(point{x + 28, y}, t.name, imageFontSize, axisTextColor, "start")
+		x += w
+		drawn = true
+	}
+	if !drawn {
+		return top
+	}
+	return y + 10
+}
+
+// xTicks 选取均匀分布的 x 轴刻度，相邻刻度之间至少留出标签的宽度
+func (fr *figRenderer) xTicks() []int {
+	n := len(fr.xLabels)
+	if n == 0 {
+		return nil
+	}
+	labelWidth := 0.0
+	for _, l := range fr.xLabels {
+		labelWidth = math.Max(labelWidth, textWidth(l, imageFontSize))
+	}
+	maxTicks := int((fr.right - fr.left) / (labelWidth + 30))
+	if maxTicks < 1 {
+		maxTicks = 1
+	}
+	step := int(math.Ceil(float64(n) / float64(maxTicks)))
+	var ticks []int
+	for i := 0; i < n; i += step {
+		ticks = append(ticks, i)
+	}
+	return ticks
+}
+
+func (fr *figRenderer) slot() float64 {
+	n := math.Max(float64(len(fr.xLabels)), 1)
+	return (fr.right - fr.left) / n
+}
+
+func (fr *figRenderer) xPos(i float64) float64 {
+	return fr.left + (i+0.5)*fr.slot()
+}
+
+func (fr *figRenderer) yPos(p *imagePane, v float64) float64 {
+	return p.bottom - (v-p.min)/(p.max-p.min)*(p.bottom-p.top)
+}
+
+func (fr *figRenderer) renderTrace(c canvas, p *imagePane, t *imageTrace) {
+	bw := math.Max(fr.slot()*0.7, 1)
+	colorAt := func(i int) string {
+		if i < len(t.colors) && t.colors[i] != "" {
+			return t.colors[i]
+		}
+		return t.color
+	}
+	switch t.kind {
+	case "candlestick":
+		for i, xi := range t.x {
+			if i >= len(t.open) || i >= len(t.high) || i >= len(t.low) || i >= len(t.close) {
+				break
+			}
+			o, h, l, cl := t.open[i], t.high[i], t.low[i], t.close[i]
+			if math.IsNaN(o) || math.IsNaN(h) || math.IsNaN(l) || math.IsNaN(cl) {
+				continue
+			}
+			color := t.upColor
+			if cl < o {
+				color = t.downColor
+			}
+			x := fr.xPos(float64(xi))
+			c.polyline([]point{{x, fr.yPos(p, h)}, {x, fr.yPos(p, l)}}, color, 1, false)
+			y0, y1 := fr.yPos(p, math.Max(o, cl)), fr.yPos(p, math.Min(o, cl))
+			if y1-y0 < 1 {
+				y1 = y0 + 1
+			}
+			c.polygon(rectPoints(x-bw/2, y0, x+bw/2, y1), color)
+		}
+	case "bar":
+		for i, xi := range t.x {
+			if i >= len(t.y) || math.IsNaN(t.y[i]) {
+				continue
+			}
+			x := fr.xPos(float64(xi))
+			y0, y1 := fr.yPos(p, 0), fr.yPos(p, t.y[i])
+			c.polygon(rectPoints(x-bw/2, math.Min(y0, y1), x+bw/2, math.Max(y0, y1)), colorAt(i))
+		}
+	case "scatter":
+		if t.lines {
+			var seg []point
+			drawSeg := func() {
+				if len(seg) < 2 {
+					return
+				}
+				if t.fill != "" {
+					y0 := fr.yPos(p, 0)
+					c.polygon(append(append([]point{{seg[0].x, y0}}, seg...), point{seg[len(seg)-1].x, y0}), t.fill)
+				}
+				c.polyline(seg, t.color, t.width, t.dash)
+			}
+			for i, xi := range t.x {
+				if i >= len(t.y) || math.IsNaN(t.y[i]) {
+					drawSeg()
+					seg = nil
+					continue
+				}
+				seg = append(seg, point{fr.xPos(float64(xi)), fr.yPos(p, t.y[i])})
+			}
+			drawSeg()
+		}
+		if t.markers {
+			for i, xi := range t.x {
+				if i >= len(t.y) || math.IsNaN(t.y[i]) {
+					continue
+				}
+				x, y := fr.xPos(float64(xi)), fr.yPos(p, t.y[i])
+				symbol := t.symbol
+				if i < len(t.symbols) {
+					symbol = t.symbols[i]
+				}
+				if symbol == "x" || symbol == "cross" {
+					s := t.size / 2
+					c.polyline([]point{{x - s, y - s}, {x + s, y + s}}, colorAt(i), 1.5, false)
+					c.polyline([]point{{x - s, y + s}, {x + s, y - s}}, colorAt(i), 1.5, false)
+					continue
+				}
+				c.polygon(symbolPoints(symbol, x, y, t.size), colorAt(i))
+			}
+		}
+	}
+}
+
+// renderLayoutItems 画 Layout 中的线段和文字标注，如 STICKLINE、DRAWTEXT
+// below 为 true 时只画 layer 为 below 的图形，画在 trace 下面
+func (fr *figRenderer) renderLayoutItems(c canvas, below bool) {
+	if fr.fig.Layout == nil {
+		return
+	}
+	xAt := func(v any) (float64, bool) {
+		i, ok := fr.xIndex[fmt.Sprint(v)]
+		return fr.xPos(float64(i)), ok
+	}
+	for _, s := range fr.fig.Layout.Shapes {
+		if (s.Layer == grob.LayoutShapeLayerBelow) != below {
+			continue
+		}
+		// "y2 domain" 表示按副图的高度比例，0 为底部，1 为顶部
+		axis, domain := strings.CutSuffix(string(s.Yref), " domain")
+		p, ok := fr.paneByAxis[axis]
+		if !ok {
+			continue
+		}
+		x0, ok0 := xAt(s.X0)
+		x1, ok1 := xAt(s.X1)
+		if !ok0 || !ok1 {
+			continue
+		}
+		y0, y1 := fr.yPos(p, cast.ToFloat64(s.Y0)), fr.yPos(p, cast.ToFloat64(s.Y1))
+		if domain {
+			y0 = p.bottom - cast.ToFloat64(s.Y0)*(p.bottom-p.top)
+			y1 = p.bottom - cast.ToFloat64(s.Y1)*(p.bottom-p.top)
+		}
+		color, width, dash := "#444444", 2.0, false
+		if s.Line != nil {
+			if s.Line.Color != "" {
+				color = string(s.Line.Color)
+			}
+			if s.Line.Width != nil {
+				width = *s.Line.Width
+			}
+			dash = s.Line.Dash != "" && s.Line.Dash != "solid"
+		}
+		if s.Type == grob.LayoutShapeTypeRect {
+			if s.Fillcolor != "" {
+				c.polygon(rectPoints(math.Min(x0, x1), math.Min(y0, y1), math.Max(x0, x1), math.Max(y0, y1)), string(s.Fillcolor))
+			}
+			if width > 0 {
+				c.polyline([]point{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}, color, width, dash)
+			}
+			continue
+		}
+		c.polyline([]point{{x0, y0}, {x1, y1}}, color, width, dash)
+	}
+	if below {
+		return
+	}
+	for _, a := range fr.fig.Layout.Annotations {
+		p, ok := fr.paneByAxis[string(a.Yref)]
+		if !ok {
+			continue
+		}
+		x, ok := xAt(a.X)
+		if !ok {
+			continue
+		}
+		color, size := "#222222", float64(imageFontSize)
+		if a.Font != nil {
+			if a.Font.Color != "" {
+				color = string(a.Font.Color)
+			}
+			if a.Font.Size != nil {
+				size = *a.Font.Size
+			}
+		}
+		c.text(point{x, fr.yPos(p, cast.ToFloat64(a.Y))}, string(a.Text), size, color, "middle")
+	}
+}
+
+func rectPoints(x0, y0, x1, y1 float64) []point {
+	return []point{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+}
+
+// symbolPoints 标记的多边形，size 为直径
+func symbolPoints(symbol string, x, y, size float64) []point {
+	r := size / 2
+	switch symbol {
+	case "square":
+		return rectPoints(x-r, y-r, x+r, y+r)
+	case "diamond":
+		return []point{{x, y - r}, {x + r, y}, {x, y + r}, {x - r, y}}
+	case "triangle-up":
+		return []point{{x, y - r}, {x + r, y + r}, {x - r, y + r}}
+	case "triangle-down":
+		return []point{{x - r, y - r}, {x + r, y - r}, {x, y + r}}
+	case "star":
+		pts := make([]point, 10)
+		for i := range pts {
+			rr := r
+			if i%2 == 1 {
+				rr = r * 0.45
+			}
+			a := -math.Pi/2 + float64(i)*math.Pi/5
+			pts[i] = point{x + rr*math.Cos(a), y + rr*math.Sin(a)}
+		}
+		return pts
+	}
+	pts := make([]point, 16)
+	for i := range pts {
+		a := float64(i) * 2 * math.Pi / 16
+		pts[i] = point{x + r*math.Cos(a), y + r*math.Sin(a)}
+	}
+	return pts
+}
+
+// niceTicks 在 [min, max] 中选取大约 n 个整齐的刻度
+func niceTicks(min, max, n float64) []float64 {
+	step := niceStep((max - min) / n)
+	if step <= 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return nil
+	}
+	var ticks []float64
+	for v := math.Ceil(min/step) * step; v <= max; v += step {
+		ticks = append(ticks, math.Round(v/step)*step)
+	}
+	return ticks
+}
+
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 0
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / mag; {
+	case f <= 1:
+		return mag
+	case f <= 2:
+		return 2 * mag
+	case f <= 5:
+		return 5 * mag
+	}
+	return 10 * mag
+}
+
+// formatTick 格式化 y 轴刻度，较大的数使用 k、M、G 后缀
+func formatTick(v, min, max float64) string {
+	abs := math.Max(math.Abs(min), math.Abs(max))
+	unit, suffix := 1.0, ""
+	switch {
+	case abs >= 1e9:
+		unit, suffix = 1e9, "G"
+	case abs >= 1e6:
+		unit, suffix = 1e6, "M"
+	case abs >= 1e4:
+		unit, suffix = 1e3, "k"
+	}
+	// 先保留 4 位小数去掉浮点误差，+0 把 -0 变成 0
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(v/unit, 'f', 4, 64), 64)
+	return strconv.FormatFloat(f+0, 'f', -1, 64) + suffix
+}
+
+// textWidth 估算文字宽度，中文等宽字符按一个字号计算
+func textWidth(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		if r > 0x2e80 {
+			w += size
+		} else {
+			w += size * 0.6
+		}
+	}
+	return w
+}
@@ -0,0 +1,137 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+)
+
+func imageTestChart(t *testing.T) *KlineChart {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-10", "2024-04-11", "2024-04-12"})
+	executor.SetVarMap(map[string][]float64{
+		"O": {10, 11, 12, 12.5},
+		"H": {12, 12, 14, 13},
+		"L": {9, 10, 11, 11.5},
+		"C": {11, 10.5, 13, 12},
+		"V": {100, 200, 150, 120},
+	})
+	err := executor.RunCode("MA2:MA(C,2),COLORBLUE;\nDIFF$G2:C-O,COLORSTICK;\nDRAWTEXT(C>O,H,'买入'),COLORRED;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart := NewKlineChart(executor, "test")
+	chart.SetDefaultKlineChart()
+	chart.AddFormulaOutputs(nil)
+	chart.AddDrawings(nil)
+	return chart
+}
+
+func TestAsSvg(t *testing.T) {
+	chart := imageTestChart(t)
+	chart.SetImageOptions(ImageOptions{Width: 800, Height: 600})
+	svg := chart.AsSvgBytes()
+
+	var doc struct {
+		Width    string `xml:"width,attr"`
+		Polygons []struct {
+			Fill string `xml:"fill,attr"`
+		} `xml:"polygon"`
+		Texts []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(svg, &doc); err != nil {
+		t.Fatalf("invalid svg: %v", err)
+	}
+	if doc.Width != "800" {
+		t.Errorf("width = %s", doc.Width)
+	}
+	texts := strings.Join(doc.Texts, "|")
+	for _, want := range []string{"Kline", "MA2", "DIFF", "Volume", "买入", "2024-04-09"} {
+		if !strings.Contains(texts, want) {
+			t.Errorf("svg texts %q missing %s", texts, want)
+		}
+	}
+	// 4 根 K 线中 3 根上涨、1 根下跌，默认绿涨红跌
+	fills := map[string]int{}
+	for _, p := range doc.Polygons {
+		fills[p.Fill]++
+	}
+	if fills["green"] < 3 || fills["red"] < 1 {
+		t.Errorf("polygon fills = %v", fills)
+	}
+
+	path := filepath.Join(t.TempDir(), "kline.svg")
+	if err := chart.AsSvg(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsPng(t *testing.T) {
+	chart := imageTestChart(t)
+	chart.SetImageOptions(ImageOptions{Width: 400, Height: 300, Scale: 2})
+	var buf bytes.Buffer
+	if err := chart.WritePng(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 800 || b.Dy() != 600 {
+		t.Fatalf("bounds = %v", b)
+	}
+	counts := map[color.RGBA]int{}
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 800; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			counts[color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}]++
+		}
+	}
+	if counts[color.RGBA{255, 255, 255, 255}] == 0 || counts[color.RGBA{0, 128, 0, 255}] == 0 || counts[color.RGBA{255, 0, 0, 255}] == 0 {
+		t.Errorf("png should contain white background, green and red candles")
+	}
+
+	chart.SetImageOptions(ImageOptions{FontFile: filepath.Join(t.TempDir(), "missing.ttf")})
+	if err := chart.AsPng(filepath.Join(t.TempDir(), "kline.png")); err == nil {
+		t.Errorf("missing font file should fail")
+	}
+}
+
+func TestImageHelpers(t *testing.T) {
+	colors := map[string]color.RGBA{
+		"red":                 {255, 0, 0, 255},
+		"#0f0":                {0, 255, 0, 255},
+		"#1E90FF":             {30, 144, 255, 255},
+		"rgb(10, 20, 30)":     {10, 20, 30, 255},
+		"rgba(200,100,0,0.5)": {100, 50, 0, 127},
+		"unknown":             {0, 0, 0, 255},
+	}
+	for s, want := range colors {
+		if got := parseColor(s); got != want {
+			t.Errorf("parseColor(%s) = %v, want %v", s, got, want)
+		}
+	}
+	if got := niceTicks(9.3, 14.2, 5); !reflect.DeepEqual(got, []float64{10, 11, 12, 13, 14}) {
+		t.Errorf("niceTicks = %v", got)
+	}
+	for _, tt := range []struct {
+		v, min, max float64
+		want        string
+	}{
+		{0.30000000000000004, 0, 1, "0.3"},
+		{-0.0, -1, 1, "0"},
+		{1500000, 0, 2e6, "1.5M"},
+		{20000, 0, 3e4, "20k"},
+	} {
+		if got := formatTick(tt.v, tt.min, tt.max); got != tt.want {
+			t.Errorf("formatTick(%v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package charts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+)
+
+// Bar 一根 K 线，Time 为日期时间数组的元素，可以是日期字符串、unix 秒或 time.Time
+type Bar struct {
+	Time   any
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// barVarNames 每个价格字段可能绑定的变量名，都没有绑定时使用第一个
+var barVarNames = [][]string{
+	{"O", "OPEN"},
+	{"H", "HIGH"},
+	{"L", "LOW"},
+	{"C", "CLOSE"},
+	{"V", "VOLUME"},
+}
+
+// LiveServer 实时图表服务，每个品种绑定一个 MaiExecutor，路由为 /<symbol>/
+// 收到新的 K 线后重新执行公式，通过 SSE 推送给浏览器：只是在末尾追加数据时用 Plotly.extendTraces 增量更新，
+// 否则（例如更新最后一根 K 线、DRAWTEXT 新增标注）推送整个图表
+type LiveServer struct {
+	Addr        string
+	HtmlOptions []HtmlOption // 加载 plotly.js 的方式，见 EmbedPlotlyJS
+	mu          sync.RWMutex
+	charts      map[string]*LiveChart
+	symbols     []string
+}
+
+func NewLiveServer(addr string) *LiveServer {
+	if addr == "" {
+		addr = "localhost:8080"
+	}
+	return &LiveServer{Addr: addr, charts: make(map[string]*LiveChart)}
+}
+
+// AddSymbol 添加一个品种，code 为公式，为空时使用 executor 已经预编译的公式
+// executor 中已有的 K 线数据会先画出来，之后用 LiveChart.AppendBar 追加
+func (s *LiveServer) AddSymbol(symbol string, executor *api.MaiExecutor, code string) (*LiveChart, error) {
+	if code != "" {
+		if err := executor.CompileCode(code); err != nil {
+			return nil, err
+		}
+	}
+	c := &LiveChart{Symbol: symbol, Executor: executor, subscribers: make(map[chan liveEvent]struct{})}
+	if err := c.Refresh(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.charts[symbol]; !ok {
+		s.symbols = append(s.symbols, symbol)
+	}
+	s.charts[symbol] = c
+	return c, nil
+}
+
+// Chart 获取品种的图表，不存在时返回 nil
+func (s *LiveServer) Chart(symbol string) *LiveChart {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.charts[symbol]
+}
+
+// Handler 返回 http.Handler，可以挂到已有的服务上
+//
+//	/                  品种列表
+//	/<symbol>/         图表页面
+//	/<symbol>/figure   当前图表的 JSON
+//	/<symbol>/events   SSE 推送，连接后先推送整个图表
+func (s *LiveServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serveIndex)
+	mux.HandleFunc("GET /{symbol}/{$}", s.withChart(s.servePage))
+	mux.HandleFunc("GET /{symbol}/figure", s.withChart(func(c *LiveChart, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(c.FigureJSON())
+	}))
+	mux.HandleFunc("GET /{symbol}/events", s.withChart(func(c *LiveChart, w http.ResponseWriter, r *http.Request) {
+		c.serveEvents(w, r)
+	}))
+	return mux
+}
+
+// ListenAndServe 启动服务，ctx 结束时关闭所有连接并返回 nil
+func (s *LiveServer) ListenAndServe(ctx context.Context) error {
+	return listenAndServe(ctx, &http.Server{Addr: s.Addr, Handler: s.Handler()})
+}
+
+func (s *LiveServer) withChart(h func(c *LiveChart, w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := s.Chart(r.PathValue("symbol"))
+		if c == nil {
+			http.NotFound(w, r)
+			return
+		}
+		h(c, w, r)
+	}
+}
+
+func (s *LiveServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	symbols := append([]string(nil), s.symbols...)
+	s.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := liveIndexTemplate.Execute(w, symbols); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *LiveServer) servePage(c *LiveChart, w http.ResponseWriter, r *http.Request) {
+	version := (&grob.Fig{}).Info()
+	script, err := plotlyScript(version, s.HtmlOptions...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// Symbol 等由 html/template 转义，plotly.js 原样写入 <script>
+	data := struct {
+		Symbol   string
+		Cdn      string
+		PlotlyJS template.JS
+	}{c.Symbol, version.Cdn, template.JS(script)}
+	if err := livePageTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// LiveChart 一个品种的实时图表
+type LiveChart struct {
+	Symbol   string
+	Executor *api.MaiExecutor
+	// Setup 画图，默认画 K 线、成交量、公式输出变量和画图函数，
+	// 见 SetDefaultKlineChart、AddFormulaOutputs、AddDrawings。修改后调用 Refresh
+	Setup func(chart *KlineChart)
+
+	mu          sync.Mutex
+	figJSON     []byte
+	layoutJSON  []byte
+	traces      []map[string]any
+	subscribers map[chan liveEvent]struct{}
+}
+
+// liveEvent 推送给浏览器的事件，reset 为整个图表，extend 为 []traceUpdate
+type liveEvent struct {
+	name string
+	data []byte
+}
+
+// traceUpdate Plotly.extendTraces 的参数：第 Index 条 trace 每个数组属性追加的数据
+type traceUpdate struct {
+	Index  int                `json:"index"`
+	Update map[string][][]any `json:"update"`
+}
+
+// AppendBar 追加 K 线并推送更新，时间与最后一根 K 线相同时替换最后一根（盘中更新）
+func (c *LiveChart) AppendBar(bars ...Bar) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, bar := range bars {
+		c.appendBar(bar)
+	}
+	return c.refresh()
+}
+
+// Refresh 重新执行公式并推送更新，直接修改了 Executor 中的数据后调用
+func (c *LiveChart) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refresh()
+}
+
+// FigureJSON 当前图表的 JSON
+func (c *LiveChart) FigureJSON() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.figJSON
+}
+
+func (c *LiveChart) appendBar(bar Bar) {
+	e := c.Executor
+	times := e.GetDateTimeArray()
+	replace := len(times) > 0 && sameBarTime(times[len(times)-1], bar.Time)
+	if replace {
+		times[len(times)-1] = bar.Time
+	} else {
+		times = append(times, bar.Time)
+	}
+	e.SetVar(e.DateTimeKey, times)
+
+	values := []float64{bar.Open, bar.High, bar.Low, bar.Close, bar.Volume}
+	for i, names := range barVarNames {
+		bound := false
+		for _, name := range names {
+			if _, ok := e.GetVariable(name); !ok {
+				continue
+			}
+			bound = true
+			e.SetVar(name, appendValue(e.GetFloat64Array(name), values[i], replace))
+		}
+		if !bound {
+			e.SetVar(names[0], appendValue(nil, values[i], replace))
+		}
+	}
+}
+
+func appendValue(arr []float64, v float64, replace bool) []float64 {
+	if replace && len(arr) > 0 {
+		arr[len(arr)-1] = v
+		return arr
+	}
+	return append(arr, v)
+}
+
+func sameBarTime(a, b any) bool {
+	ta, okA := api.ParseDateTime(a, time.UTC)
+	tb, okB := api.ParseDateTime(b, time.UTC)
+	if okA && okB {
+		return ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func (c *LiveChart) refresh() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s 执行公式失败: %v", c.Symbol, r)
+		}
+	}()
+	e := c.Executor
+	if e.PreCompiledProgram != nil && (len(e.GetDateTimeArray()) > 0 || len(e.GetFloat64Array("C")) > 0) {
+		if err := e.ExecuteProgram(); err != nil {
+			return err
+		}
+	}
+	chart := NewKlineChart(e, c.Symbol)
+	chart.Title = c.Symbol
+	if c.Setup != nil {
+		c.Setup(chart)
+	} else {
+		chart.SetDefaultKlineChart()
+		chart.AddFormulaOutputs(e)
+		chart.AddDrawings(e)
+	}
+	fig := chart.ObjInit()
+	figJSON, err := json.Marshal(fig)
+	if err != nil {
+		return err
+	}
+	layoutJSON, err := json.Marshal(fig.Layout)
+	if err != nil {
+		return err
+	}
+	traces := make([]map[string]any, len(fig.Data))
+	for i, t := range fig.Data {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			return err
+		}
+		traces[i] = flattenTrace("", m, map[string]any{})
+	}
+
+	event := liveEvent{name: "reset", data: figJSON}
+	if c.figJSON != nil && string(layoutJSON) == string(c.layoutJSON) {
+		if updates, ok := diffTraces(c.traces, traces); ok {
+			event.name = "extend"
+			event.data, _ = json.Marshal(updates)
+			if len(updates) == 0 {
+				event.data = nil
+			}
+		}
+	}
+	c.figJSON, c.layoutJSON, c.traces = figJSON, layoutJSON, traces
+	if event.data != nil {
+		c.broadcast(event)
+	}
+	return nil
+}
+
+// flattenTrace 把嵌套的属性展开为 marker.color 这样的路径，与 Plotly.extendTraces 的写法一致
+func flattenTrace(prefix string, m map[string]any, out map[string]any) map[string]any {
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			flattenTrace(prefix+k+".", sub, out)
+			continue
+		}
+		out[prefix+k] = v
+	}
+	return out
+}
+
+// diffTraces 比较两次的 trace，只有数组属性在末尾追加了数据时返回追加的部分，否则返回 false，需要推送整个图表
+func diffTraces(old, new []map[string]any) ([]traceUpdate, bool) {
+	if len(old) != len(new) {
+		return nil, false
+	}
+	var updates []traceUpdate
+	for i := range new {
+		if len(old[i]) != len(new[i]) {
+			return nil, false
+		}
+		update := map[string][][]any{}
+		for k, nv := range new[i] {
+			ov, ok := old[i][k]
+			if !ok {
+				return nil, false
+			}
+			na, isArr := nv.([]any)
+			oa, wasArr := ov.([]any)
+			if !isArr || !wasArr {
+				if !reflect.DeepEqual(ov, nv) {
+					return nil, false
+				}
+				continue
+			}
+			if len(na) < len(oa) || !reflect.DeepEqual(oa, na[:len(oa)]) {
+				return nil, false
+			}
+			if len(na) > len(oa) {
+				update[k] = [][]any{na[len(oa):]}
+			}
+		}
+		if len(update) > 0 {
+			updates = append(updates, traceUpdate{Index: i, Update: update})
+		}
+	}
+	return updates, true
+}
+
+// broadcast 推送事件，来不及接收的连接直接断开，浏览器重连后会收到整个图表
+func (c *LiveChart) broadcast(event liveEvent) {
+	for ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(c.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (c *LiveChart) subscribe() (chan liveEvent, []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan liveEvent, 16)
+	c.subscribers[ch] = struct{}{}
+	return ch, c.figJSON
+}
+
+func (c *LiveChart) unsubscribe(ch chan liveEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.subscribers[ch]; ok {
+		delete(c.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (c *LiveChart) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	ch, fig := c.subscribe()
+	defer c.unsubscribe(ch)
+
+	writeEvent := func(e liveEvent) {
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, e.data)
+		flusher.Flush()
+	}
+	writeEvent(liveEvent{name: "reset", data: fig})
+	ping := time.NewTicker(15 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeEvent(e)
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+var liveIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+	<head><meta charset="UTF-8" /></head>
+	<body>
+		<ul>
+		{{- range . }}
+			<li><a href="{{ . }}/">{{ . }}</a></li>
+		{{- end }}
+		</ul>
+	</body>
+</html>
+`))
+
+var livePageTemplate = template.Must(template.New("live").Parse(`<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="UTF-8" />
+		<title>{{ .Symbol }}</title>
+		{{- if .PlotlyJS }}
+		<script type="text/javascript">{{ .PlotlyJS }}</script>
+		{{- else }}
+		<script src="{{ .Cdn }}"></script>
+		{{- end }}
+	</head>
+	<body>
+		<div id="plot"></div>
+	<script>
+		const events = new EventSource('events')
+		events.addEventListener('reset', e => {
+			const fig = JSON.parse(e.data)
+			Plotly.react('plot', fig.data || [], fig.layout || {}, {locale: 'zh-CN'})
+		})
+		events.addEventListener('extend', e => {
+			for (const u of JSON.parse(e.data)) {
+				Plotly.extendTraces('plot', u.update, [u.index])
+			}
+		})
+	</script>
+	</body>
+</html>
+`))
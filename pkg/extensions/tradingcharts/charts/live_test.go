@@ -0,0 +1,201 @@
+package charts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+)
+
+// readEvent 读取一个 SSE 事件
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var name, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestLiveServer(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09", "2024-04-10", "2024-04-11"})
+	executor.SetVarMap(map[string][]float64{
+		"O": {10, 11, 12},
+		"H": {12, 12, 14},
+		"L": {9, 10, 11},
+		"C": {11, 10.5, 13},
+		"V": {100, 200, 150},
+	})
+	server := NewLiveServer("")
+	chart, err := server.AddSymbol("000001.SZ", executor, "MA2:MA(C,2),COLORBLUE;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	for path, want := range map[string]string{
+		"/":                  `href="000001.SZ/"`,
+		"/000001.SZ/":        "new EventSource('events')",
+		"/000001.SZ/figure":  `"MA2"`,
+		"/600000.SH/figure":  "404 page not found",
+		"/600000.SH/events/": "404 page not found",
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(b), want) {
+			t.Errorf("GET %s = %s, want %s", path, b, want)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/000001.SZ/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	if name, data := readEvent(t, events); name != "reset" || !strings.Contains(data, `"2024-04-11"`) {
+		t.Fatalf("first event = %s %s", name, data)
+	}
+
+	// 新的 K 线只推送追加的数据
+	if err := chart.AppendBar(Bar{Time: "2024-04-12", Open: 13, High: 15, Low: 12, Close: 14, Volume: 300}); err != nil {
+		t.Fatal(err)
+	}
+	name, data := readEvent(t, events)
+	if name != "extend" {
+		t.Fatalf("event = %s %s", name, data)
+	}
+	var updates []traceUpdate
+	if err := json.Unmarshal([]byte(data), &updates); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("updates = %s", data)
+	}
+	kline, ma, vol := updates[0].Update, updates[1].Update, updates[2].Update
+	if kline["x"][0][0] != "2024-04-12" || kline["close"][0][0] != 14.0 || ma["y"][0][0] != 13.5 || vol["y"][0][0] != 300.0 {
+		t.Errorf("updates = %s", data)
+	}
+	if got := executor.GetFloat64Array("C"); len(got) != 4 {
+		t.Errorf("C = %v", got)
+	}
+
+	// 更新最后一根 K 线时推送整个图表
+	if err := chart.AppendBar(Bar{Time: "2024-04-12", Open: 13, High: 16, Low: 12, Close: 15, Volume: 400}); err != nil {
+		t.Fatal(err)
+	}
+	if name, data := readEvent(t, events); name != "reset" || !strings.Contains(data, "15") {
+		t.Errorf("event = %s %s", name, data)
+	}
+	if got := executor.GetFloat64Array("C"); len(got) != 4 || got[3] != 15 {
+		t.Errorf("C = %v", got)
+	}
+}
+
+func TestLiveServerEscape(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-09"})
+	executor.SetVar("C", []float64{11})
+	dir := t.TempDir()
+	js := filepath.Join(dir, "plotly.min.js")
+	if err := os.WriteFile(js, []byte("window.Plotly={s:'<b>'};"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := NewLiveServer("")
+	server.HtmlOptions = []HtmlOption{PlotlyJSFile(js)}
+	symbol := `<b>"x"</b>`
+	if _, err := server.AddSymbol(symbol, executor, "MA2:MA(C,2);"); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	get := func(path string) string {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+	if html := get("/"); strings.Contains(html, symbol) || !strings.Contains(html, "&lt;b&gt;&#34;x&#34;&lt;/b&gt;") {
+		t.Errorf("index = %s", html)
+	}
+	html := get("/" + url.PathEscape(symbol) + "/")
+	if strings.Contains(html, "<title>"+symbol) || !strings.Contains(html, "<title>&lt;b&gt;") {
+		t.Errorf("page title should be escaped: %s", html)
+	}
+	if !strings.Contains(html, "window.Plotly={s:'<b>'};") {
+		t.Errorf("plotly.js should be written as is: %s", html)
+	}
+}
+
+func TestLiveServerShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewLiveServer(l.Addr().String())
+	l.Close()
+	executor := api.NewMaiExecutor()
+	if _, err := server.AddSymbol("000001.SZ", executor, "MA2:MA(C,2);"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ListenAndServe(ctx) }()
+
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = http.Get("http://" + server.Addr + "/000001.SZ/events"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	readEvent(t, bufio.NewReader(resp.Body))
+
+	// 有连接着的 SSE 时也能关闭
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,234 @@
+package charts
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// pngCanvas 用 golang.org/x/image/vector 光栅化绘图命令，不依赖浏览器和 cgo
+type pngCanvas struct {
+	img   *image.RGBA
+	scale float64
+	font  *opentype.Font
+	faces map[float64]font.Face
+}
+
+func newPngCanvas(width, height float64, opt ImageOptions) (*pngCanvas, error) {
+	scale := opt.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	data := goregular.TTF
+	if opt.FontFile != "" {
+		b, err := os.ReadFile(opt.FontFile)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+	f, err := parseFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font %s: %w", opt.FontFile, err)
+	}
+	w, h := int(math.Ceil(width*scale)), int(math.Ceil(height*scale))
+	return &pngCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, w, h)),
+		scale: scale,
+		font:  f,
+		faces: map[float64]font.Face{},
+	}, nil
+}
+
+// parseFont 解析字体，字体集合（.ttc）使用第一个字体
+func parseFont(data []byte) (*opentype.Font, error) {
+	f, err := opentype.Parse(data)
+	if err == nil {
+		return f, nil
+	}
+	coll, cerr := opentype.ParseCollection(data)
+	if cerr != nil || coll.NumFonts() == 0 {
+		return nil, err
+	}
+	return coll.Font(0)
+}
+
+func (c *pngCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+func (c *pngCanvas) polygon(pts []point, fill string) {
+	if len(pts) < 3 {
+		return
+	}
+	scaled := make([]point, len(pts))
+	for i, p := range pts {
+		scaled[i] = point{p.x * c.scale, p.y * c.scale}
+	}
+	c.fill(scaled, parseColor(fill))
+}
+
+// fill 填充多边形，坐标已经缩放，光栅化只在多边形所在的区域进行
+func (c *pngCanvas) fill(pts []point, col color.RGBA) {
+	minX, minY, maxX, maxY := pts[0].x, pts[0].y, pts[0].x, pts[0].y
+	for _, p := range pts[1:] {
+		minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+		maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+	}
+	r := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(c.img.Bounds())
+	if r.Empty() {
+		return
+	}
+	z := vector.NewRasterizer(r.Dx(), r.Dy())
+	ox, oy := float64(r.Min.X), float64(r.Min.Y)
+	z.MoveTo(float32(pts[0].x-ox), float32(pts[0].y-oy))
+	for _, p := range pts[1:] {
+		z.LineTo(float32(p.x-ox), float32(p.y-oy))
+	}
+	z.ClosePath()
+	z.Draw(c.img, r, image.NewUniform(col), image.Point{})
+}
+
+func (c *pngCanvas) polyline(pts []point, col string, width float64, dash bool) {
+	rgba := parseColor(col)
+	w := math.Max(width*c.scale, 1)
+	for i := 1; i < len(pts); i++ {
+		a := point{pts[i-1].x * c.scale, pts[i-1].y * c.scale}
+		b := point{pts[i].x * c.scale, pts[i].y * c.scale}
+		if !dash {
+			c.segment(a, b, w, rgba)
+			continue
+		}
+		// 虚线：线段和间隔都是两倍线宽
+		length := math.Hypot(b.x-a.x, b.y-a.y)
+		step := 2 * w
+		for d := 0.0; d < length; d += 2 * step {
+			e := math.Min(d+step, length)
+			c.segment(lerp(a, b, d/length), lerp(a, b, e/length), w, rgba)
+		}
+	}
+}
+
+// segment 把宽度为 w 的线段画成四边形，两端各延长半个线宽使折线的拐角连续
+func (c *pngCanvas) segment(a, b point, w float64, col color.RGBA) {
+	dx, dy := b.x-a.x, b.y-a.y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return
+	}
+	ux, uy := dx/l*w/2, dy/l*w/2
+	nx, ny := -uy, ux
+	if w <= 1.5 {
+		// 细线不延长，避免端点颜色过深
+		ux, uy = 0, 0
+	}
+	c.fill([]point{
+		{a.x - ux + nx, a.y - uy + ny},
+		{b.x + ux + nx, b.y + uy + ny},
+		{b.x + ux - nx, b.y + uy - ny},
+		{a.x - ux - nx, a.y - uy - ny},
+	}, col)
+}
+
+func lerp(a, b point, t float64) point {
+	return point{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t}
+}
+
+func (c *pngCanvas) face(size float64) font.Face {
+	if f, ok := c.faces[size]; ok {
+		return f
+	}
+	f, err := opentype.NewFace(c.font, &opentype.FaceOptions{Size: size * c.scale, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil
+	}
+	c.faces[size] = f
+	return f
+}
+
+func (c *pngCanvas) text(p point, s string, size float64, col string, anchor string) {
+	face := c.face(size)
+	if face == nil || s == "" {
+		return
+	}
+	d := &font.Drawer{Dst: c.img, Src: image.NewUniform(parseColor(col)), Face: face}
+	x := p.x * c.scale
+	switch anchor {
+	case "middle":
+		x -= float64(d.MeasureString(s)) / 64 / 2
+	case "end":
+		x -= float64(d.MeasureString(s)) / 64
+	}
+	m := face.Metrics()
+	// 基线放在垂直中心向下半个字高的位置
+	y := p.y*c.scale + float64(m.Ascent-m.Descent)/64/2
+	d.Dot = fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+	d.DrawString(s)
+}
+
+// namedColors 常用的 CSS 颜色名
+var namedColors = map[string]color.RGBA{
+	"black":     {0, 0, 0, 255},
+	"white":     {255, 255, 255, 255},
+	"red":       {255, 0, 0, 255},
+	"green":     {0, 128, 0, 255},
+	"lime":      {0, 255, 0, 255},
+	"blue":      {0, 0, 255, 255},
+	"yellow":    {255, 255, 0, 255},
+	"cyan":      {0, 255, 255, 255},
+	"magenta":   {255, 0, 255, 255},
+	"orange":    {255, 165, 0, 255},
+	"brown":     {165, 42, 42, 255},
+	"purple":    {128, 0, 128, 255},
+	"pink":      {255, 192, 203, 255},
+	"gray":      {128, 128, 128, 255},
+	"grey":      {128, 128, 128, 255},
+	"lightgray": {211, 211, 211, 255},
+	"lightgrey": {211, 211, 211, 255},
+	"darkgray":  {169, 169, 169, 255},
+	"silver":    {192, 192, 192, 255},
+	"gold":      {255, 215, 0, 255},
+}
+
+// parseColor 解析 #RGB、#RRGGBB、rgb()、rgba() 和常用颜色名，无法解析时为黑色
+func parseColor(s string) color.RGBA {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c
+	}
+	if strings.HasPrefix(s, "#") {
+		h := s[1:]
+		if len(h) == 3 {
+			h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+		}
+		if v, err := strconv.ParseUint(h, 16, 32); err == nil && len(h) == 6 {
+			return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}
+		}
+	}
+	if i := strings.Index(s, "("); i > 0 && strings.HasSuffix(s, ")") && strings.HasPrefix(s, "rgb") {
+		parts := strings.Split(s[i+1:len(s)-1], ",")
+		if len(parts) >= 3 {
+			var v [4]float64
+			v[3] = 1
+			for k := 0; k < len(parts) && k < 4; k++ {
+				v[k], _ = strconv.ParseFloat(strings.TrimSpace(parts[k]), 64)
+			}
+			// color.RGBA 使用预乘 alpha
+			a := math.Max(0, math.Min(1, v[3]))
+			return color.RGBA{uint8(v[0] * a), uint8(v[1] * a), uint8(v[2] * a), uint8(a * 255)}
+		}
+	}
+	return color.RGBA{0, 0, 0, 255}
+}
@@ -0,0 +1,149 @@
+package charts
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+)
+
+// medianInterval 相邻 K 线间隔的中位数，偶数个时取较小的一个
+func medianInterval(times []time.Time) time.Duration {
+	var gaps []time.Duration
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d > 0 {
+			gaps = append(gaps, d)
+		}
+	}
+	if len(gaps) == 0 {
+		return 0
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[(len(gaps)-1)/2]
+}
+
+// computeRangebreaks 按 K 线的实际时间计算需要隐藏的非交易时间：
+// 没有 K 线的周末、工作日中没有 K 线的日期（节假日、停牌），日内周期还有每天没有 K 线的时段（夜间、A 股 11:30~13:00 午休）。
+// 周线及以上不需要隐藏。ok 为 false 表示时间无法解析或者日内的时段不规则，应该改用 category 坐标轴
+func computeRangebreaks(times []time.Time, interval time.Duration) (breaks []grob.LayoutXaxisRangebreak, ok bool) {
+	if len(times) < 2 {
+		return nil, true
+	}
+	sorted := append([]time.Time(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	if interval <= 0 {
+		interval = medianInterval(sorted)
+	}
+	const day = 24 * time.Hour
+	if interval <= 0 || interval >= 5*day {
+		return nil, true
+	}
+
+	hasDate := map[string]bool{}
+	weekend := false
+	for _, t := range sorted {
+		hasDate[t.Format(time.DateOnly)] = true
+		weekend = weekend || t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	}
+	if !weekend {
+		breaks = append(breaks, grob.LayoutXaxisRangebreak{
+			Pattern: grob.LayoutXaxisRangebreakPatternDayOfWeek,
+			Bounds:  []any{6, 1},
+		})
+	}
+	var missing []any
+	first, last := sorted[0], sorted[len(sorted)-1]
+	for d := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, first.Location()); d.Before(last); d = d.AddDate(0, 0, 1) {
+		if !weekend && (d.Weekday() == time.Saturday || d.Weekday() == time.Sunday) {
+			continue
+		}
+		if s := d.Format(time.DateOnly); !hasDate[s] {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		breaks = append(breaks, grob.LayoutXaxisRangebreak{Values: missing})
+	}
+	if interval >= day {
+		return breaks, true
+	}
+
+	// 日内：相邻两个有 K 线的时刻相差超过 1.5 个周期时隐藏中间的时段，两端各留半个周期给 K 线
+	hours := map[float64]bool{}
+	for _, t := range sorted {
+		hours[float64(t.Hour())+float64(t.Minute())/60+float64(t.Second())/3600] = true
+	}
+	tods := make([]float64, 0, len(hours))
+	for h := range hours {
+		tods = append(tods, h)
+	}
+	sort.Float64s(tods)
+	step := interval.Hours()
+	var hourBreaks []grob.LayoutXaxisRangebreak
+	for i, h := range tods {
+		next := tods[0] + 24
+		if i+1 < len(tods) {
+			next = tods[i+1]
+		}
+		if next-h <= 1.5*step {
+			continue
+		}
+		hourBreaks = append(hourBreaks, grob.LayoutXaxisRangebreak{
+			Pattern: grob.LayoutXaxisRangebreakPatternHour,
+			Bounds:  []any{roundHour(h + step/2), roundHour(next - step/2)},
+		})
+	}
+	// 交易时段通常不超过 3 段（如夜盘、上午、下午），更多说明数据不规则
+	if len(hourBreaks) > 3 {
+		return nil, false
+	}
+	return append(breaks, hourBreaks...), true
+}
+
+// roundHour 保留到秒，超过 24 点的按第二天计算
+func roundHour(h float64) float64 {
+	return math.Round(math.Mod(h, 24)*3600) / 3600
+}
+
+// applyRangebreaks 根据日期时间数组给所有 x 轴设置 rangebreaks，时间无法解析或者不规则时改用 category 坐标轴
+func (r *KlineChart) applyRangebreaks(layout *grob.Layout) {
+	if r.DisableRangebreaks || r.Executor == nil {
+		return
+	}
+	dates := r.Executor.GetDateTimeArray()
+	if len(dates) == 0 {
+		return
+	}
+	loc := r.Executor.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	times := make([]time.Time, len(dates))
+	ok := true
+	for i, d := range dates {
+		if times[i], ok = api.ParseDateTime(d, loc); !ok {
+			break
+		}
+	}
+	var breaks []grob.LayoutXaxisRangebreak
+	if ok {
+		var interval time.Duration
+		if p, err := api.ParsePeriod(r.Period); err == nil {
+			interval = p.Duration()
+		}
+		breaks, ok = computeRangebreaks(times, interval)
+	}
+	for _, axis := range []*grob.LayoutXaxis{layout.Xaxis, layout.XAxis2, layout.XAxis3, layout.XAxis4, layout.XAxis5, layout.XAxis6} {
+		if axis == nil {
+			continue
+		}
+		if !ok {
+			axis.Type = grob.LayoutXaxisTypeCategory
+			axis.Tickformat = ""
+			continue
+		}
+		axis.Rangebreaks = breaks
+	}
+}
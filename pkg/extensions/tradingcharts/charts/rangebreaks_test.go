@@ -0,0 +1,92 @@
+package charts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+)
+
+func TestComputeRangebreaksDaily(t *testing.T) {
+	// 2024-04-04、04-05 清明节休市
+	var times []time.Time
+	for _, d := range []string{"2024-04-01", "2024-04-02", "2024-04-03", "2024-04-08", "2024-04-09", "2024-04-10"} {
+		tm, _ := time.ParseInLocation(time.DateOnly, d, time.Local)
+		times = append(times, tm)
+	}
+	breaks, ok := computeRangebreaks(times, 0)
+	want := []grob.LayoutXaxisRangebreak{
+		{Pattern: grob.LayoutXaxisRangebreakPatternDayOfWeek, Bounds: []any{6, 1}},
+		{Values: []any{"2024-04-04", "2024-04-05"}},
+	}
+	if !ok || !reflect.DeepEqual(breaks, want) {
+		t.Errorf("breaks = %+v", breaks)
+	}
+
+	// 周末也有 K 线时不隐藏周末
+	var crypto []time.Time
+	for i := 0; i < 10; i++ {
+		crypto = append(crypto, times[0].AddDate(0, 0, i))
+	}
+	if breaks, ok := computeRangebreaks(crypto, 0); !ok || len(breaks) != 0 {
+		t.Errorf("crypto breaks = %+v", breaks)
+	}
+	// 周线不需要隐藏
+	if breaks, _ := computeRangebreaks(times, 7*24*time.Hour); breaks != nil {
+		t.Errorf("weekly breaks = %+v", breaks)
+	}
+}
+
+func TestComputeRangebreaksIntraday(t *testing.T) {
+	// A 股 1 分钟线，时间为 K 线结束时间：9:31~11:30，13:01~15:00
+	var times []time.Time
+	for _, day := range []string{"2024-04-11", "2024-04-12", "2024-04-15"} {
+		d, _ := time.ParseInLocation(time.DateOnly, day, time.Local)
+		for m := 9*60 + 31; m <= 15*60; m++ {
+			if m > 11*60+30 && m <= 13*60 {
+				continue
+			}
+			times = append(times, d.Add(time.Duration(m)*time.Minute))
+		}
+	}
+	breaks, ok := computeRangebreaks(times, 0)
+	if !ok || len(breaks) != 3 {
+		t.Fatalf("breaks = %+v", breaks)
+	}
+	if breaks[0].Pattern != grob.LayoutXaxisRangebreakPatternDayOfWeek {
+		t.Errorf("weekend break = %+v", breaks[0])
+	}
+	lunch, night := breaks[1].Bounds.([]any), breaks[2].Bounds.([]any)
+	if lunch[0].(float64) != roundHour(11.5+1.0/120) || lunch[1].(float64) != roundHour(13+1.0/120) {
+		t.Errorf("lunch break = %v", lunch)
+	}
+	if night[0].(float64) != roundHour(15+1.0/120) || night[1].(float64) != roundHour(9.5+1.0/120) {
+		t.Errorf("night break = %v", night)
+	}
+}
+
+func TestApplyRangebreaks(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-03", "2024-04-08", "2024-04-09"})
+	executor.SetVar("C", []float64{1, 2, 3})
+	chart := NewKlineChart(executor, "test")
+	fig := chart.ObjInit()
+	if len(fig.Layout.Xaxis.Rangebreaks) != 2 || !reflect.DeepEqual(fig.Layout.XAxis2.Rangebreaks, fig.Layout.Xaxis.Rangebreaks) {
+		t.Errorf("rangebreaks = %+v", fig.Layout.Xaxis.Rangebreaks)
+	}
+
+	chart.DisableRangebreaks = true
+	if fig := chart.ObjInit(); fig.Layout.Xaxis.Rangebreaks != nil {
+		t.Errorf("rangebreaks should be disabled")
+	}
+
+	// 无法解析的时间改用 category 坐标轴
+	executor.SetVar("dateTime", []any{"第1根", "第2根", "第3根"})
+	chart.DisableRangebreaks = false
+	fig = chart.ObjInit()
+	if fig.Layout.Xaxis.Type != grob.LayoutXaxisTypeCategory || fig.Layout.XAxis2.Type != grob.LayoutXaxisTypeCategory {
+		t.Errorf("xaxis type = %s", fig.Layout.Xaxis.Type)
+	}
+}
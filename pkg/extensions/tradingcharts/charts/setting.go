@@ -0,0 +1,238 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadChartSetting 解析 JSON 或 YAML 格式的图表配置，YAML 使用与 JSON 相同的字段名
+func LoadChartSetting(data []byte) (*ChartSetting, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("解析图表配置失败: %w", err)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("解析图表配置失败: %w", err)
+		}
+		data = b
+	}
+	var setting ChartSetting
+	if err := json.Unmarshal(data, &setting); err != nil {
+		return nil, fmt.Errorf("解析图表配置失败: %w", err)
+	}
+	return &setting, nil
+}
+
+// ReadChartSetting 读取图表配置文件，见 LoadChartSetting
+func ReadChartSetting(path string) (*ChartSetting, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadChartSetting(b)
+}
+
+// klineFields K 线字段对应的变量名，第一个为配置中的字段名，其余为别名
+var klineFields = [][]string{
+	{"open", "O", "OPEN"},
+	{"high", "H", "HIGH"},
+	{"low", "L", "LOW"},
+	{"close", "C", "CLOSE"},
+	{"vol", "V", "VOLUME"},
+}
+
+// LoadKline 把配置中的 K 线写入 executor：O、H、L、C、V（及 OPEN 等别名）和日期时间数组 dateTime
+// 成交量也可以写作 volume，日期也可以写作 dateTime、time
+func (s *ChartSetting) LoadKline(executor *api.MaiExecutor) {
+	if len(s.Kline) == 0 {
+		return
+	}
+	dates := make([]any, len(s.Kline))
+	for i, kl := range s.Kline {
+		for _, key := range []string{"date", "dateTime", "time"} {
+			if v, ok := kl.Store[key]; ok {
+				dates[i] = v
+				break
+			}
+		}
+	}
+	executor.SetVar("dateTime", dates)
+	executor.DateTimeKey = "dateTime"
+	for _, names := range klineFields {
+		values := make([]float64, len(s.Kline))
+		for i, kl := range s.Kline {
+			if _, ok := kl.Store[names[0]]; !ok && names[0] == "vol" {
+				values[i] = kl.GetFloat64("volume")
+				continue
+			}
+			values[i] = kl.GetFloat64(names[0])
+		}
+		for _, name := range names[1:] {
+			executor.SetVar(name, values)
+		}
+	}
+}
+
+// NewKlineChartFromSetting 按配置生成图表：K 线放在主图，MainIndicators 放在主图，SubCharts 依次放在副图，
+// 画图函数（DRAWTEXT 等）按修饰符放置。没有配置任何指标时与 SetDefaultKlineChart 加 AddFormulaOutputs 相同
+func NewKlineChartFromSetting(executor *api.MaiExecutor, setting *ChartSetting) (*KlineChart, error) {
+	setting.LoadKline(executor)
+	if setting.Formula != "" {
+		if err := executor.CompileCode(setting.Formula); err != nil {
+			return nil, err
+		}
+		if err := executeProgram(executor); err != nil {
+			return nil, err
+		}
+	}
+	r := NewKlineChart(executor, setting.Title)
+	r.Title = setting.Title
+	r.Period = setting.Period
+	r.KlineColorMode = setting.ColorMode
+	r.Height = setting.Height
+
+	if len(setting.MainIndicators) == 0 && len(setting.SubCharts) == 0 {
+		r.SetDefaultKlineChart()
+		r.AddFormulaOutputs(executor)
+		r.AddDrawings(executor)
+		return r, nil
+	}
+
+	r.AddCharts(0, r.klineTrace())
+	mainHeight := setting.MainHeight
+	if mainHeight <= 0 {
+		mainHeight = 3
+	}
+	r.PaneHeights = []float64{mainHeight}
+	if err := r.addIndicators(0, setting.MainIndicators); err != nil {
+		return nil, err
+	}
+	dates := executor.GetDateTimeArray()
+	for i, sub := range setting.SubCharts {
+		pane := i + 1
+		r.PaneHeights = append(r.PaneHeights, sub.Height)
+		if err := r.addIndicators(pane, sub.Indicators); err != nil {
+			return nil, err
+		}
+		for _, v := range sub.Lines {
+			r.AddCharts(pane, referenceLine(pane, v, dates))
+		}
+	}
+	r.AddDrawings(executor)
+	return r, nil
+}
+
+// executeProgram 执行预编译的公式，公式出错时 panic 转为 error
+func executeProgram(executor *api.MaiExecutor) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("执行公式失败: %v", r)
+		}
+	}()
+	return executor.ExecuteProgram()
+}
+
+func (r *KlineChart) addIndicators(pane int, list []Indicator) error {
+	dates := r.Executor.GetDateTimeArray()
+	for _, ind := range list {
+		kind := DrawKind(strings.ToUpper(ind.Type))
+		switch kind {
+		case "":
+			kind = DrawLine
+		case DrawLine, DrawStick, DrawColorStick, DrawVolStick, DrawPointDot, DrawCrossDot:
+		default:
+			return fmt.Errorf("指标 %s 的画法 %s 无效", ind.Name, ind.Type)
+		}
+		label := ind.Label
+		if label == "" {
+			label = groupNamePattern.ReplaceAllString(ind.Name, "")
+		}
+		series := ind.Values
+		if len(series) == 0 {
+			value, ok := r.Executor.GetVariable(ind.Name)
+			if !ok {
+				return fmt.Errorf("指标 %s 不存在", ind.Name)
+			}
+			n := len(dates)
+			if n == 0 {
+				n = outputLen(value)
+			}
+			series = [][]float64{outputValues(value, n)}
+		}
+		for i, y := range series {
+			style := DrawStyle{Name: label, Kind: kind, Color: ind.Color, LineWidth: ind.Width, Dot: ind.Dot, Chart: pane}
+			if i > 0 {
+				style.Name = fmt.Sprintf("%s%d", label, i+1)
+			}
+			r.AddCharts(pane, r.formulaTrace(r.Executor, style, Array(dates), y))
+		}
+	}
+	return nil
+}
+
+// referenceLine 副图中的水平参考线，从第一根 K 线画到最后一根
+func referenceLine(pane int, v float64, dates []any) *grob.Scatter {
+	x := []any{0, 1}
+	if len(dates) > 0 {
+		x = []any{dates[0], dates[len(dates)-1]}
+	}
+	return &grob.Scatter{
+		Name:       types.S(fmt.Sprint(v)),
+		X:          types.DataArray(x),
+		Y:          types.DataArray([]float64{v, v}),
+		Mode:       grob.ScatterModeLines,
+		Showlegend: types.False,
+		Hoverinfo:  types.ArrayOKValue(grob.ScatterHoverinfoSkip),
+		Line:       &grob.ScatterLine{Color: types.C("gray"), Width: types.N(1), Dash: types.S("dash")},
+		Xaxis:      types.S(Xaxis()),
+		Yaxis:      types.S(Yaxis(pane)),
+	}
+}
+
+// layoutYaxis 第 idx 个图（0 为主图）的 y 轴，create 为 true 时不存在则创建；只支持前 6 个
+func layoutYaxis(layout *grob.Layout, idx int, create bool) *grob.LayoutYaxis {
+	axes := []**grob.LayoutYaxis{&layout.Yaxis, &layout.YAxis2, &layout.YAxis3, &layout.YAxis4, &layout.YAxis5, &layout.YAxis6}
+	if idx < 0 || idx >= len(axes) {
+		return nil
+	}
+	if *axes[idx] == nil && create {
+		*axes[idx] = &grob.LayoutYaxis{}
+	}
+	return *axes[idx]
+}
+
+// applyPaneHeights 按 PaneHeights 从上到下设置各个图的 y 轴 domain，panes 为有数据的图
+func (r *KlineChart) applyPaneHeights(layout *grob.Layout, panes []int) {
+	if len(r.PaneHeights) == 0 || len(panes) == 0 || panes[len(panes)-1] >= 6 {
+		return
+	}
+	const gap = 0.03
+	heights := make([]float64, len(panes))
+	total := 0.0
+	for i, p := range panes {
+		heights[i] = 1
+		if p < len(r.PaneHeights) && r.PaneHeights[p] > 0 {
+			heights[i] = r.PaneHeights[p]
+		}
+		total += heights[i]
+	}
+	unit := (1 - gap*float64(len(panes)-1)) / total
+	top := 1.0
+	for i, p := range panes {
+		bottom := max(math.Round((top-heights[i]*unit)*1e4)/1e4, 0)
+		layoutYaxis(layout, p, true).Domain = []float64{bottom, top}
+		top = bottom - gap
+	}
+}
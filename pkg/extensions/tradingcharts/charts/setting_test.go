@@ -0,0 +1,133 @@
+package charts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+)
+
+const testChartSettingYAML = `
+title: 000001.SZ
+formula: |
+  MA2:MA(C,2);
+  RSI2:RSI(C,2),NODRAW;
+color_mode: green_down_and_red_up
+height: 600
+kline:
+  - {date: "2024-04-08", open: 10, high: 11, low: 9, close: 10, vol: 100}
+  - {date: "2024-04-09", open: 10, high: 12, low: 10, close: 11, vol: 200}
+  - {date: "2024-04-10", open: 11, high: 12, low: 10, close: 10.5, vol: 150}
+  - {date: "2024-04-11", open: 10.5, high: 13, low: 10, close: 12, volume: 300}
+main_indicators:
+  - {name: MA2, color: blue, width: 2}
+sub_charts:
+  - name: VOL
+    indicators:
+      - {name: V, label: 成交量, type: volstick}
+  - name: RSI
+    height: 2
+    indicators:
+      - {name: RSI2, dot: true}
+    lines: [30, 70]
+`
+
+func TestLoadChartSetting(t *testing.T) {
+	setting, err := LoadChartSetting([]byte(testChartSettingYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setting.Title != "000001.SZ" || setting.ColorMode != GreenDownAndRedUp || len(setting.Kline) != 4 ||
+		len(setting.SubCharts) != 2 || !reflect.DeepEqual(setting.SubCharts[1].Lines, []float64{30, 70}) {
+		t.Errorf("setting = %+v", setting)
+	}
+	json := `{"title":"000001.SZ","main_indicators":[{"name":"MA2","type":"line"}],"sub_charts":[{"name":"RSI","lines":[30,70]}]}`
+	setting, err = LoadChartSetting([]byte(json))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setting.MainIndicators[0].Type != "line" || setting.SubCharts[0].Lines[1] != 70 {
+		t.Errorf("setting = %+v", setting)
+	}
+	if _, err := LoadChartSetting([]byte("title: [")); err == nil {
+		t.Errorf("invalid yaml should fail")
+	}
+}
+
+func TestNewKlineChartFromSetting(t *testing.T) {
+	setting, err := LoadChartSetting([]byte(testChartSettingYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := api.NewMaiExecutor()
+	chart, err := NewKlineChartFromSetting(executor, setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := executor.GetFloat64Array("VOLUME"); !reflect.DeepEqual(got, []float64{100, 200, 150, 300}) {
+		t.Errorf("VOLUME = %v", got)
+	}
+
+	fig := chart.ObjInit()
+	if fig.Layout.Title.Text != "000001.SZ" || *fig.Layout.Height != 600 {
+		t.Errorf("layout = %+v", fig.Layout)
+	}
+	var names []string
+	for _, tr := range fig.Data {
+		switch tr := tr.(type) {
+		case *grob.Candlestick:
+			names = append(names, string(tr.Name)+"@"+string(tr.Yaxis))
+		case *grob.Bar:
+			names = append(names, string(tr.Name)+"@"+string(tr.Yaxis))
+		case *grob.Scatter:
+			names = append(names, string(tr.Name)+"@"+string(tr.Yaxis))
+		}
+	}
+	// NODRAW 只影响 AddFormulaOutputs，配置中列出的指标照常画出
+	want := []string{"Kline@y", "MA2@y", "成交量@y2", "RSI2@y3", "30@y3", "70@y3"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("traces = %v, want %v", names, want)
+	}
+	ma := fig.Data[1].(*grob.Scatter)
+	if ma.Line.Color != "blue" || *ma.Line.Width != 2 {
+		t.Errorf("MA2 line = %+v", ma.Line)
+	}
+	if rsi := fig.Data[3].(*grob.Scatter); rsi.Line.Dash != "dot" {
+		t.Errorf("RSI2 line = %+v", rsi.Line)
+	}
+	// 主图 3、成交量 1、RSI 2
+	main, vol, rsi := fig.Layout.Yaxis.Domain.([]float64), fig.Layout.YAxis2.Domain.([]float64), fig.Layout.YAxis3.Domain.([]float64)
+	if main[1] != 1 || rsi[0] != 0 || main[1]-main[0] <= 2.9*(vol[1]-vol[0]) || rsi[1]-rsi[0] <= 1.9*(vol[1]-vol[0]) {
+		t.Errorf("domains = %v %v %v", main, vol, rsi)
+	}
+	if svg := string(chart.AsSvgBytes()); !strings.Contains(svg, "成交量") {
+		t.Errorf("svg should contain the volume legend")
+	}
+}
+
+func TestNewKlineChartFromSettingErrors(t *testing.T) {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("C", []float64{1, 2, 3})
+	for _, setting := range []*ChartSetting{
+		{MainIndicators: []Indicator{{Name: "MA5"}}},
+		{MainIndicators: []Indicator{{Name: "C", Type: "area"}}},
+		{Formula: "MA5:MA(C,"},
+	} {
+		if _, err := NewKlineChartFromSetting(executor, setting); err == nil {
+			t.Errorf("setting %+v should fail", setting)
+		}
+	}
+
+	// 直接给出数据
+	chart, err := NewKlineChartFromSetting(executor, &ChartSetting{
+		SubCharts: []SubChart{{Indicators: []Indicator{{Name: "MACD", Type: "colorstick", Values: [][]float64{{1, -1, 2}, {0, 1, 1}}}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Tmp[1]) != 2 || chart.Tmp[1][1].(*grob.Bar).Name != "MACD2" {
+		t.Errorf("sub chart = %+v", chart.Tmp[1])
+	}
+}
@@ -0,0 +1,65 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgCanvas 把绘图命令写成 SVG 元素，文字由查看器的字体渲染，中文可以正常显示
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func newSvgCanvas(width, height float64) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="Arial, 'PingFang SC', 'Microsoft YaHei', sans-serif">`+"\n",
+		svgNum(width), svgNum(height), svgNum(width), svgNum(height))
+	return c
+}
+
+func (c *svgCanvas) polyline(pts []point, color string, width float64, dash bool) {
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round"`, svgPoints(pts), svgAttr(color), svgNum(width))
+	if dash {
+		fmt.Fprintf(&c.buf, ` stroke-dasharray="%s,%s"`, svgNum(width*2), svgNum(width*2))
+	}
+	c.buf.WriteString("/>\n")
+}
+
+func (c *svgCanvas) polygon(pts []point, fill string) {
+	fmt.Fprintf(&c.buf, `<polygon points="%s" fill="%s"/>`+"\n", svgPoints(pts), svgAttr(fill))
+}
+
+func (c *svgCanvas) text(p point, s string, size float64, color string, anchor string) {
+	fmt.Fprintf(&c.buf, `<text x="%s" y="%s" font-size="%s" fill="%s" text-anchor="%s" dominant-baseline="central">`,
+		svgNum(p.x), svgNum(p.y), svgNum(size), svgAttr(color), anchor)
+	xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) bytes() []byte {
+	c.buf.WriteString("</svg>\n")
+	return c.buf.Bytes()
+}
+
+func svgPoints(pts []point) string {
+	parts := make([]string, len(pts))
+	for i, p := range pts {
+		parts[i] = svgNum(p.x) + "," + svgNum(p.y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// svgNum 坐标保留两位小数
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func svgAttr(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
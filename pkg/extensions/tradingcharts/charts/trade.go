@@ -0,0 +1,277 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+	"github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/pkg/types"
+)
+
+// Trade 一笔交易，用于在 K 线图上画出开平仓位置和持仓区间
+// EntryTime、ExitTime 与日期时间数组的元素写法相同（日期字符串、unix 秒或 time.Time），
+// 画在不早于该时间的第一根 K 线上。ExitTime 为 nil 表示尚未平仓
+type Trade struct {
+	Short      bool // 做空
+	EntryTime  any
+	EntryPrice float64
+	ExitTime   any
+	ExitPrice  float64
+	Size       float64 // 数量，为 0 时按 1 计算
+	PnL        float64 // 盈亏，为 0 时按开平仓价格和数量计算
+}
+
+// Closed 是否已经平仓
+func (t Trade) Closed() bool {
+	return t.ExitTime != nil
+}
+
+// Profit 平仓的盈亏，未平仓时为 0
+func (t Trade) Profit() float64 {
+	if !t.Closed() {
+		return 0
+	}
+	if t.PnL != 0 {
+		return t.PnL
+	}
+	return t.priceProfit(t.ExitPrice)
+}
+
+// priceProfit 按价格 price 平仓的盈亏
+func (t Trade) priceProfit(price float64) float64 {
+	size := t.Size
+	if size == 0 {
+		size = 1
+	}
+	d := (price - t.EntryPrice) * size
+	if t.Short {
+		d = -d
+	}
+	return d
+}
+
+// tradeBars 把交易的时间对应到 K 线的位置
+type tradeBars struct {
+	loc   *time.Location
+	dates []any
+	times []time.Time // 能解析的 K 线时间，按时间排序
+	index []int       // times 对应的 K 线位置
+}
+
+func newTradeBars(executor *api.MaiExecutor) *tradeBars {
+	tb := &tradeBars{loc: executor.Location, dates: executor.GetDateTimeArray()}
+	if tb.loc == nil {
+		tb.loc = time.Local
+	}
+	for i, d := range tb.dates {
+		if t, ok := api.ParseDateTime(d, tb.loc); ok {
+			tb.times = append(tb.times, t)
+			tb.index = append(tb.index, i)
+		}
+	}
+	sort.Sort(tb)
+	return tb
+}
+
+func (tb *tradeBars) Len() int           { return len(tb.times) }
+func (tb *tradeBars) Less(i, j int) bool { return tb.times[i].Before(tb.times[j]) }
+func (tb *tradeBars) Swap(i, j int) {
+	tb.times[i], tb.times[j] = tb.times[j], tb.times[i]
+	tb.index[i], tb.index[j] = tb.index[j], tb.index[i]
+}
+
+// find 不早于 v 的第一根 K 线的位置，找不到时返回 false
+func (tb *tradeBars) find(v any) (int, bool) {
+	t, ok := api.ParseDateTime(v, tb.loc)
+	if !ok {
+		return 0, false
+	}
+	i := sort.Search(len(tb.times), func(i int) bool { return !tb.times[i].Before(t) })
+	if i == len(tb.times) {
+		return 0, false
+	}
+	return tb.index[i], true
+}
+
+// x 交易时间在图上的横坐标，能对应到 K 线时使用 K 线的日期时间
+func (tb *tradeBars) x(v any) any {
+	if i, ok := tb.find(v); ok {
+		return tb.dates[i]
+	}
+	return v
+}
+
+// AddTrades 在主图上画出交易：开仓、平仓标记（悬停显示价格、数量、盈亏），以及持仓区间的底色
+// 做多开仓为向上的箭头，做空开仓为向下的箭头；盈利的持仓区间为上涨颜色，亏损为下跌颜色，颜色跟随 KlineColorMode
+func (r *KlineChart) AddTrades(trades []Trade) {
+	if len(trades) == 0 {
+		return
+	}
+	tb := newTradeBars(r.Executor)
+	up, down := r.upDownColors()
+	closes := priceArray(r.Executor, "C", "CLOSE")
+	var (
+		entryX, entryY, exitX, exitY []any
+		entryText, exitText          []string
+		entrySymbols, exitSymbols    []grob.ScatterMarkerSymbol
+		entryColors, exitColors      []types.Color
+	)
+	// 买入为向上的红色箭头，卖出为向下的绿色箭头，同 DRAWICON 1、2
+	buy, sell := drawIcons[1], drawIcons[2]
+	for _, t := range trades {
+		open, close := "开多", "平多"
+		in, out := buy, sell
+		if t.Short {
+			open, close = "开空", "平空"
+			in, out = sell, buy
+		}
+		size := t.Size
+		if size == 0 {
+			size = 1
+		}
+		entryX, entryY = append(entryX, tb.x(t.EntryTime)), append(entryY, t.EntryPrice)
+		entryText = append(entryText, fmt.Sprintf("%s<br>价格: %.2f<br>数量: %g", open, t.EntryPrice, size))
+		entrySymbols, entryColors = append(entrySymbols, in.symbol), append(entryColors, types.C(in.color))
+
+		x1, profit := any(nil), 0.0
+		if t.Closed() {
+			x1, profit = tb.x(t.ExitTime), t.Profit()
+			exitX, exitY = append(exitX, x1), append(exitY, t.ExitPrice)
+			exitText = append(exitText, fmt.Sprintf("%s<br>价格: %.2f<br>数量: %g<br>盈亏: %.2f (%.2f%%)",
+				close, t.ExitPrice, size, profit, profit/(t.EntryPrice*size)*100))
+			exitSymbols, exitColors = append(exitSymbols, out.symbol), append(exitColors, types.C(out.color))
+		} else if len(tb.dates) > 0 {
+			// 未平仓的持仓区间画到最后一根 K 线，按最新收盘价计算浮动盈亏
+			x1 = tb.dates[len(tb.dates)-1]
+			if len(closes) > 0 {
+				profit = t.priceProfit(closes[len(closes)-1])
+			}
+		}
+		if x1 == nil {
+			continue
+		}
+		color := up
+		if profit < 0 {
+			color = down
+		}
+		r.Shapes = append(r.Shapes, grob.LayoutShape{
+			Type:      grob.LayoutShapeTypeRect,
+			X0:        tb.x(t.EntryTime),
+			X1:        x1,
+			Y0:        0,
+			Y1:        1,
+			Xref:      grob.LayoutShapeXref(Xaxis()),
+			Yref:      grob.LayoutShapeYref(Yaxis(0) + " domain"),
+			Fillcolor: types.C(fadeColor(color, 0.12)),
+			Line:      &grob.LayoutShapeLine{Width: types.N(0)},
+			Layer:     grob.LayoutShapeLayerBelow,
+		})
+	}
+	tradeMarkers := func(name string, x, y []any, text []string, symbols []grob.ScatterMarkerSymbol, colors []types.Color) *grob.Scatter {
+		return &grob.Scatter{
+			Name:      types.S(name),
+			X:         types.DataArray(x),
+			Y:         types.DataArray(y),
+			Mode:      grob.ScatterModeMarkers,
+			Xaxis:     types.S(Xaxis()),
+			Yaxis:     types.S(Yaxis(0)),
+			Hovertext: HoverTextArray(text...),
+			Hoverinfo: types.ArrayOKValue(grob.ScatterHoverinfoText),
+			Marker: &grob.ScatterMarker{
+				Symbol: types.ArrayOKArray(symbols...),
+				Color:  types.ArrayOKArray(types.UseColors(colors)...),
+				Size:   Size(12),
+			},
+		}
+	}
+	r.AddCharts(0, tradeMarkers("开仓", entryX, entryY, entryText, entrySymbols, entryColors))
+	if len(exitX) > 0 {
+		r.AddCharts(0, tradeMarkers("平仓", exitX, exitY, exitText, exitSymbols, exitColors))
+	}
+}
+
+// TradesEquity 按交易和每根 K 线的收盘价计算逐日权益，initial 为初始资金，持仓按收盘价计算浮动盈亏
+func (r *KlineChart) TradesEquity(trades []Trade, initial float64) []float64 {
+	closes := priceArray(r.Executor, "C", "CLOSE")
+	tb := newTradeBars(r.Executor)
+	equity := make([]float64, len(closes))
+	for i := range equity {
+		equity[i] = initial
+	}
+	for _, t := range trades {
+		entry, ok := tb.find(t.EntryTime)
+		if !ok {
+			continue
+		}
+		exit := len(closes)
+		if t.Closed() {
+			if i, ok := tb.find(t.ExitTime); ok {
+				exit = i
+			}
+		}
+		for i := entry; i < len(closes); i++ {
+			if i < exit {
+				equity[i] += t.priceProfit(closes[i])
+			} else {
+				equity[i] += t.Profit()
+			}
+		}
+	}
+	return equity
+}
+
+// AddEquity 画权益曲线和回撤：权益画在第 idx 个图，回撤（相对前高的百分比）画在第 idx+1 个图
+// equity 与 K 线一一对应，可以用 TradesEquity 计算，也可以直接使用回测的结果
+func (r *KlineChart) AddEquity(idx int, equity []float64) {
+	dates := Array(r.Executor.GetDateTimeArray())
+	r.AddCharts(idx, &grob.Scatter{
+		Name:  types.S("权益"),
+		X:     dates,
+		Y:     Array(equity),
+		Mode:  grob.ScatterModeLines,
+		Xaxis: types.S(Xaxis()),
+		Yaxis: types.S(Yaxis(idx)),
+		Line:  &grob.ScatterLine{Color: types.C("#1f77b4"), Width: types.N(1.5)},
+	})
+	_, down := r.upDownColors()
+	r.AddCharts(idx+1, &grob.Scatter{
+		Name:      types.S("回撤%"),
+		X:         dates,
+		Y:         Array(Drawdown(equity)),
+		Mode:      grob.ScatterModeLines,
+		Fill:      grob.ScatterFillTozeroy,
+		Fillcolor: types.C(fadeColor(down, 0.3)),
+		Xaxis:     types.S(Xaxis()),
+		Yaxis:     types.S(Yaxis(idx + 1)),
+		Line:      &grob.ScatterLine{Color: types.C(down), Width: types.N(1)},
+	})
+}
+
+// Drawdown 每个位置相对之前最高权益的回撤百分比，值小于等于 0，NaN 不参与计算
+func Drawdown(equity []float64) []float64 {
+	result := make([]float64, len(equity))
+	peak := math.NaN()
+	for i, v := range equity {
+		if math.IsNaN(v) {
+			result[i] = math.NaN()
+			continue
+		}
+		if math.IsNaN(peak) || v > peak {
+			peak = v
+		}
+		if peak == 0 {
+			continue
+		}
+		result[i] = (v - peak) / math.Abs(peak) * 100
+	}
+	return result
+}
+
+// fadeColor 把颜色转为带透明度的 rgba()
+func fadeColor(color string, alpha float64) string {
+	c := parseColor(color)
+	return fmt.Sprintf("rgba(%d,%d,%d,%g)", c.R, c.G, c.B, alpha)
+}
@@ -0,0 +1,95 @@
+package charts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lyr-2000/mylang/pkg/api"
+	grob "github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/go-plotly/generated/v2.34.0/graph_objects"
+)
+
+func tradeTestChart() *KlineChart {
+	executor := api.NewMaiExecutor()
+	executor.SetVar("dateTime", []any{"2024-04-08", "2024-04-09", "2024-04-10", "2024-04-11", "2024-04-12", "2024-04-15"})
+	executor.SetVarMap(map[string][]float64{
+		"O": {10, 10, 11, 12, 12, 11},
+		"H": {11, 11, 12, 13, 13, 12},
+		"L": {9, 9, 10, 11, 11, 10},
+		"C": {10, 11, 12, 12, 11, 10},
+		"V": {100, 100, 100, 100, 100, 100},
+	})
+	return NewKlineChart(executor, "test")
+}
+
+func TestAddTrades(t *testing.T) {
+	chart := tradeTestChart()
+	chart.SetDefaultKlineChart()
+	trades := []Trade{
+		// 周末的时间画在下一根 K 线上
+		{EntryTime: "2024-04-09", EntryPrice: 11, ExitTime: "2024-04-10 15:00", ExitPrice: 12, Size: 100},
+		{Short: true, EntryTime: "2024-04-13", EntryPrice: 11, Size: 100},
+	}
+	chart.AddTrades(trades)
+
+	if len(chart.Tmp[0]) != 3 {
+		t.Fatalf("main chart traces = %d", len(chart.Tmp[0]))
+	}
+	entry, exit := chart.Tmp[0][1].(*grob.Scatter), chart.Tmp[0][2].(*grob.Scatter)
+	if got := dataValues(entry.X); !reflect.DeepEqual(got, []any{"2024-04-09", "2024-04-15"}) {
+		t.Errorf("entry x = %v", got)
+	}
+	if got := dataValues(exit.X); !reflect.DeepEqual(got, []any{"2024-04-11"}) {
+		t.Errorf("exit x = %v", got)
+	}
+	if text := *exit.Hovertext.Array[0]; !strings.Contains(string(text), "盈亏: 100.00 (9.09%)") {
+		t.Errorf("exit hover text = %s", text)
+	}
+	if symbols := entry.Marker.Symbol.Array; *symbols[0] != grob.ScatterMarkerSymbolTriangleUp || *symbols[1] != grob.ScatterMarkerSymbolTriangleDown {
+		t.Errorf("entry symbols = %v %v", *symbols[0], *symbols[1])
+	}
+
+	if len(chart.Shapes) != 2 {
+		t.Fatalf("shapes = %d", len(chart.Shapes))
+	}
+	long, short := chart.Shapes[0], chart.Shapes[1]
+	if long.X0 != "2024-04-09" || long.X1 != "2024-04-11" || long.Yref != "y domain" || long.Layer != grob.LayoutShapeLayerBelow {
+		t.Errorf("long shape = %+v", long)
+	}
+	// 空单按最新收盘价 10 计算是盈利的，使用上涨颜色
+	if short.X1 != "2024-04-15" || long.Fillcolor != "rgba(0,128,0,0.12)" || short.Fillcolor != long.Fillcolor {
+		t.Errorf("short shape = %+v", short)
+	}
+
+	svg := string(chart.AsSvgBytes())
+	if !strings.Contains(svg, `fill="rgba(0,128,0,0.12)"`) {
+		t.Errorf("svg should contain the holding period")
+	}
+}
+
+func TestTradesEquity(t *testing.T) {
+	chart := tradeTestChart()
+	trades := []Trade{
+		{EntryTime: "2024-04-09", EntryPrice: 11, ExitTime: "2024-04-11", ExitPrice: 12, Size: 100},
+		{Short: true, EntryTime: "2024-04-12", EntryPrice: 11, Size: 100},
+	}
+	equity := chart.TradesEquity(trades, 1000)
+	want := []float64{1000, 1000, 1100, 1100, 1100, 1200}
+	if !reflect.DeepEqual(equity, want) {
+		t.Errorf("equity = %v, want %v", equity, want)
+	}
+	if got := Drawdown([]float64{100, 120, 90, 130, 117}); !reflect.DeepEqual(got, []float64{0, 0, -25, 0, -10}) {
+		t.Errorf("drawdown = %v", got)
+	}
+
+	chart.SetDefaultKlineChart()
+	chart.AddEquity(2, equity)
+	fig := chart.ObjInit()
+	if got := fig.Layout.Grid.Subplots; !reflect.DeepEqual(got, [][]string{{"xy"}, {"xy2"}, {"xy3"}, {"xy4"}}) {
+		t.Errorf("subplots = %v", got)
+	}
+	dd := chart.Tmp[3][0].(*grob.Scatter)
+	if dd.Yaxis != "y4" || dd.Fill != grob.ScatterFillTozeroy {
+		t.Errorf("drawdown trace = %+v", dd)
+	}
+}
@@ -18,6 +18,8 @@ type Options struct {
 }
 
 // ToHtml saves the figure as standalone HTML. It still requires internet to load plotly.js from CDN.
+// To embed plotly.js for offline viewing and get the errors back, use charts.WriteHtml
+// (github.com/lyr-2000/mylang/pkg/extensions/tradingcharts/charts) with charts.EmbedPlotlyJS or charts.PlotlyJSFile.
 func ToHtml(fig types.Fig, path string) {
 	buf := FigToBuffer(fig)
 	os.WriteFile(path, buf.Bytes(), os.ModePerm)
@@ -30,6 +32,8 @@ func Show(fig types.Fig) {
 	browser.OpenReader(buf)
 }
 
+// FigToBuffer renders the figure as standalone HTML that loads plotly.js from CDN.
+// See charts.FigToHtml for the offline options.
 func FigToBuffer(fig types.Fig) *bytes.Buffer {
 	figBytes, err := json.Marshal(fig)
 	if err != nil {
@@ -3,6 +3,7 @@ package mylang
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/lyr-2000/mylang/pkg/extensions/indicators"
@@ -10,9 +11,13 @@ import (
 
 // MylangInterpreter 代表麦语言解释器的接口
 type MylangInterpreter struct {
-	Interp *Interpreter
-	Env    *Environment
-	Err    error
+	Interp      *Interpreter
+	Env         *Environment
+	Err         error
+	Diagnostics []Diagnostic // 最近一次编译的警告，如未知的修饰符、全角符号
+
+	caseInsensitive bool
+	tolerance       Tolerance
 }
 
 func (mi *MylangInterpreter) DelVars() {
@@ -21,8 +26,11 @@ func (mi *MylangInterpreter) DelVars() {
 
 func (mi *MylangInterpreter) Reset() {
 	mi.Env = NewEnvironment()
+	mi.Env.SetCaseInsensitive(mi.caseInsensitive)
 	mi.Interp = NewInterpreter(mi.Env)
+	mi.Interp.Tolerance = mi.tolerance
 	mi.Err = nil
+	mi.Diagnostics = nil
 }
 
 // NewMylangInterpreter 创建一个新的麦语言解释器
@@ -32,6 +40,26 @@ func NewMylangInterpreter() *MylangInterpreter {
 	return &MylangInterpreter{Interp: interp, Env: env}
 }
 
+// SetCaseInsensitive 设置是否不区分大小写：开启后变量名、函数名和关键字（AND、OR、NOT）都不区分大小写，
+// 变量统一按大写存储，CustomVariableGetter 收到的也是大写名称。默认区分大小写，关键字只识别全大写和全小写。
+// 已有的变量名只有大小写不同时保留全大写的一个，并返回错误说明，见 Environment.SetCaseInsensitive
+func (mi *MylangInterpreter) SetCaseInsensitive(on bool) error {
+	mi.caseInsensitive = on
+	return mi.Env.SetCaseInsensitive(on)
+}
+
+// SetTolerance 设置比较运算的误差，Reset 后仍然有效。默认为 ToleranceLegacy：
+// 整数部分必须相等，小数部分允许 1% 的误差
+func (mi *MylangInterpreter) SetTolerance(t Tolerance) {
+	mi.tolerance = t
+	mi.Interp.Tolerance = t
+}
+
+// Tolerance 返回比较运算的误差设置
+func (mi *MylangInterpreter) Tolerance() Tolerance {
+	return mi.tolerance
+}
+
 // RegisterVariable 注册一个变量到解释器
 func (mi *MylangInterpreter) RegisterVariable(name string, value interface{}) {
 	mi.Env.SetVariable(name, value)
@@ -42,18 +70,14 @@ func (mi *MylangInterpreter) RegisterFunction(name string, fn func([]interface{}
 	mi.Env.SetFunction(name, fn)
 }
 
-func hasComment(code string) bool {
-	return strings.Contains(code, "{")
-}
-
 // CompileCode 预编译麦语言代码，返回语法树
 func (mi *MylangInterpreter) CompileCode(code string) *Program {
-	if hasComment(code) {
-		code = TrimComment(code)
-	}
 	lexer := NewLexer(code)
+	lexer.CaseInsensitive = mi.caseInsensitive
 	parser := NewParser(lexer)
-	return parser.ParseProgram()
+	program := parser.ParseProgram()
+	mi.Diagnostics = program.Diagnostics
+	return program
 }
 
 // ExecuteProgram 执行语法树
@@ -142,6 +166,17 @@ func (mi *MylangInterpreter) GetOutputVariableMap() map[string]int {
 	return mi.Interp.OutputVarMap
 }
 
+// GetOutputVariableNames 按输出顺序返回所有画图变量名
+func (mi *MylangInterpreter) GetOutputVariableNames() []string {
+	km := mi.GetOutputVariableMap()
+	names := make([]string, 0, len(km))
+	for name := range km {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool { return km[names[a]] < km[names[b]] })
+	return names
+}
+
 // GetOutputVariable 获取第 i 个输出值
 func (mi *MylangInterpreter) GetOutputVariable(i int) (any, bool) {
 	for name, id2 := range mi.GetOutputVariableMap() {
@@ -183,6 +218,11 @@ func (mi *MylangInterpreter) GetSuffixParams(name string) ([]string, bool) {
 	return params, exists
 }
 
+// GetSuffixModifiers 获取指定变量解析后的修饰符，无效的修饰符见 Diagnostics
+func (mi *MylangInterpreter) GetSuffixModifiers(name string) ([]Modifier, bool) {
+	return mi.Interp.GetSuffixModifiers(name)
+}
+
 // HasSyntaxErrors 检查是否有语法错误
 func (mi *MylangInterpreter) HasSyntaxErrors() bool {
 	return mi.Interp.Err != nil
@@ -196,7 +236,7 @@ func (mi *MylangInterpreter) GetSyntaxErrors() []string {
 	return []string{}
 }
 
-// GetAllVariables 获取所有变量
+// GetAllVariables 获取所有变量，不区分大小写时变量名为大写
 func (mi *MylangInterpreter) GetAllVariables() map[string]interface{} {
 	return mi.Env.variables
 }
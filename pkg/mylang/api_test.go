@@ -2,6 +2,7 @@ package mylang
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -398,3 +399,107 @@ func TestMylangInterpreterComplexExpressionsWithSuffixParams(t *testing.T) {
 		})
 	}
 }
+
+func TestMylangInterpreterFullWidthAndCaseInsensitive(t *testing.T) {
+	interp := NewMylangInterpreter()
+	interp.RegisterVariable("close", []float64{1, 2, 3})
+	interp.RegisterFunction("DOUBLE", func(args []interface{}) interface{} {
+		arr := args[0].([]float64)
+		out := make([]float64, len(arr))
+		for i, v := range arr {
+			out[i] = v * 2
+		}
+		return out
+	})
+
+	// 默认区分大小写，全角符号只产生警告
+	interp.Execute("A：＝DOUBLE（close）；")
+	if interp.Err != nil {
+		t.Fatalf("Execute() error = %v", interp.Err)
+	}
+	if a, _ := interp.GetVariable("A"); !reflect.DeepEqual(a, []float64{2, 4, 6}) {
+		t.Errorf("A = %v", a)
+	}
+	if len(interp.Diagnostics) != 5 {
+		t.Errorf("Diagnostics = %v", interp.Diagnostics)
+	}
+	if _, ok := interp.GetVariable("CLOSE"); ok {
+		t.Errorf("CLOSE should not exist when case sensitive")
+	}
+
+	if err := interp.SetCaseInsensitive(true); err != nil {
+		t.Fatalf("SetCaseInsensitive() error = %v", err)
+	}
+	interp.Execute("b:double(Close)>4 And CLOSE>1;")
+	if interp.Err != nil {
+		t.Fatalf("Execute() error = %v", interp.Err)
+	}
+	for _, name := range []string{"b", "B"} {
+		if b, _ := interp.GetVariable(name); !reflect.DeepEqual(b, []float64{0, 0, 1}) {
+			t.Errorf("%s = %v", name, b)
+		}
+	}
+	if !interp.IsOutputVariable("b") {
+		t.Errorf("output variable keeps the written name")
+	}
+
+	interp.Reset()
+	if !interp.Env.CaseInsensitive() {
+		t.Errorf("Reset should keep case insensitive mode")
+	}
+}
+
+func TestMylangInterpreterCaseCollision(t *testing.T) {
+	interp := NewMylangInterpreter()
+	interp.RegisterVariable("close", []float64{1})
+	interp.RegisterVariable("CLOSE", []float64{2})
+	interp.RegisterVariable("Close", []float64{3})
+	interp.RegisterVariable("open", []float64{4})
+	interp.RegisterVariable("Open", []float64{5})
+	interp.RegisterVariable("vol", []float64{6})
+
+	err := interp.SetCaseInsensitive(true)
+	if err == nil || !strings.Contains(err.Error(), "CLOSE、Close、close 保留 CLOSE") || !strings.Contains(err.Error(), "Open、open 保留 Open") {
+		t.Fatalf("SetCaseInsensitive() error = %v", err)
+	}
+	// 优先保留全大写的，没有时保留排序最前的，每次结果相同
+	for name, want := range map[string][]float64{"close": {2}, "open": {5}, "VOL": {6}} {
+		if got, _ := interp.GetVariable(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMylangInterpreterLexicalErrors(t *testing.T) {
+	interp := NewMylangInterpreter()
+	interp.RegisterVariable("C", []float64{1, 2})
+
+	interp.Execute("{ 注释 }\nA:C*1e2; // 注释\nB:'{x}';")
+	if interp.Err != nil {
+		t.Fatalf("Execute() error = %v", interp.Err)
+	}
+	if a, _ := interp.GetVariable("A"); !reflect.DeepEqual(a, []float64{100, 200}) {
+		t.Errorf("A = %v", a)
+	}
+	if b, _ := interp.GetVariable("B"); b != "{x}" {
+		t.Errorf("B = %v", b)
+	}
+
+	program := interp.CompileCode("A:C;\nB:'abc;")
+	if want := []string{"第2行第3列：字符串没有结束，缺少 '"}; !reflect.DeepEqual(program.Errors, want) {
+		t.Errorf("Errors = %v, want %v", program.Errors, want)
+	}
+	program = interp.CompileCode("A:C;\n  B:C+1.2.3;")
+	if want := []string{"第2行第7列：数字格式错误 1.2.3"}; !reflect.DeepEqual(program.Errors, want) {
+		t.Errorf("Errors = %v, want %v", program.Errors, want)
+	}
+
+	// 数字后面直接写 AND、OR 的旧写法仍然可以编译
+	interp.Execute("D:C>1AND C<10OR C=0;")
+	if interp.Err != nil {
+		t.Fatalf("Execute() error = %v", interp.Err)
+	}
+	if d, _ := interp.GetVariable("D"); !reflect.DeepEqual(d, []float64{0, 1}) {
+		t.Errorf("D = %v", d)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"math"
 	stdlog "log"
+	"sort"
+	"strings"
 
 	// "os"
 
@@ -21,6 +23,11 @@ func SetLogger(logger *stdlog.Logger) {
 	Logger = logger
 }
 
+// isTruthy 数值转布尔，非零且非 NaN 为 true
+func isTruthy(v float64) bool {
+	return v != 0 && !math.IsNaN(v)
+}
+
 // floatEqual 比较两个浮点数是否相等，允许 1% 的误差
 // 但整数部分必须完全相等
 func floatEqual(a, b float64) bool {
@@ -64,9 +71,10 @@ func floatEqual(a, b float64) bool {
 
 // Environment 代表执行环境
 type Environment struct {
-	variables map[string]interface{} // 存储变量
-	functions map[string]interface{} // 存储函数
-	outer     *Environment
+	variables       map[string]interface{} // 存储变量
+	functions       map[string]interface{} // 存储函数
+	outer           *Environment
+	caseInsensitive bool // 名称不区分大小写，统一按大写存储
 }
 
 func (e *Environment) DelAllVars() {
@@ -85,14 +93,83 @@ func NewEnvironment() *Environment {
 	}
 }
 
+// SetCaseInsensitive 设置变量名和函数名是否不区分大小写，开启后 close、Close、CLOSE 是同一个变量，
+// 已有的名称统一转为大写。只有大小写不同的名称保留全大写的一个，没有全大写时保留排序最前的一个；
+// 变量有这样的冲突时返回错误说明，函数（如内置的 LAST 和 Last）直接保留全大写的一个
+func (e *Environment) SetCaseInsensitive(on bool) error {
+	var collisions []string
+	for x := e; x != nil; x = x.outer {
+		x.caseInsensitive = on
+		if !on {
+			continue
+		}
+		collisions = append(collisions, foldNames(x.variables)...)
+		foldNames(x.functions)
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("变量名只有大小写不同：%s", strings.Join(collisions, "；"))
+	}
+	return nil
+}
+
+// foldNames 把 m 中的名称转为大写，返回只有大小写不同的名称，如 "close、CLOSE 保留 CLOSE"
+func foldNames(m map[string]interface{}) []string {
+	groups := map[string][]string{}
+	for name := range m {
+		upper := strings.ToUpper(name)
+		groups[upper] = append(groups[upper], name)
+	}
+	uppers := make([]string, 0, len(groups))
+	for upper := range groups {
+		uppers = append(uppers, upper)
+	}
+	sort.Strings(uppers)
+	var collisions []string
+	for _, upper := range uppers {
+		names := groups[upper]
+		if len(names) == 1 && names[0] == upper {
+			continue
+		}
+		sort.Strings(names)
+		keep := names[0]
+		for _, name := range names {
+			if name == upper {
+				keep = name
+			}
+		}
+		v := m[keep]
+		for _, name := range names {
+			delete(m, name)
+		}
+		m[upper] = v
+		if len(names) > 1 {
+			collisions = append(collisions, fmt.Sprintf("%s 保留 %s", strings.Join(names, "、"), keep))
+		}
+	}
+	return collisions
+}
+
+// CaseInsensitive 名称是否不区分大小写
+func (e *Environment) CaseInsensitive() bool {
+	return e.caseInsensitive
+}
+
+// key 名称在 map 中的键，不区分大小写时为大写
+func (e *Environment) key(name string) string {
+	if e.caseInsensitive {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
 // Get 从环境中获取一个值（先在变量中查找，再在函数中查找）
 func (e *Environment) Get(name string) (interface{}, bool) {
 	// 先在变量中查找
-	if val, ok := e.variables[name]; ok {
+	if val, ok := e.variables[e.key(name)]; ok {
 		return val, ok
 	}
 	// 再在函数中查找
-	if val, ok := e.functions[name]; ok {
+	if val, ok := e.functions[e.key(name)]; ok {
 		return val, ok
 	}
 	// 如果都没找到且存在外层环境，则在外层环境中查找
@@ -104,25 +181,25 @@ func (e *Environment) Get(name string) (interface{}, bool) {
 
 // Set 在环境中设置一个值（默认设置为变量）
 func (e *Environment) Set(name string, val interface{}) interface{} {
-	e.variables[name] = val
+	e.variables[e.key(name)] = val
 	return val
 }
 
 // SetVariable 在环境中设置一个变量
 func (e *Environment) SetVariable(name string, val interface{}) interface{} {
-	e.variables[name] = val
+	e.variables[e.key(name)] = val
 	return val
 }
 
 // SetFunction 在环境中设置一个函数
 func (e *Environment) SetFunction(name string, fn interface{}) interface{} {
-	e.functions[name] = fn
+	e.functions[e.key(name)] = fn
 	return fn
 }
 
 // GetVariable 从环境中获取一个变量
 func (e *Environment) GetVariable(name string) (interface{}, bool) {
-	if val, ok := e.variables[name]; ok {
+	if val, ok := e.variables[e.key(name)]; ok {
 		return val, ok
 	}
 	if e.outer != nil {
@@ -133,7 +210,7 @@ func (e *Environment) GetVariable(name string) (interface{}, bool) {
 
 // GetFunction 从环境中获取一个函数
 func (e *Environment) GetFunction(name string) (interface{}, bool) {
-	if fn, ok := e.functions[name]; ok {
+	if fn, ok := e.functions[e.key(name)]; ok {
 		return fn, ok
 	}
 	if e.outer != nil {
@@ -146,11 +223,15 @@ func (e *Environment) GetFunction(name string) (interface{}, bool) {
 type Interpreter struct {
 	env                  *Environment
 	CustomVariableGetter func(name string) any // if nil, use env.Get(name) to get the variable value
+	BuiltinVariableGetter func(name string) any // 环境中找不到变量时调用，提供 DATE、BARPOS 等内置变量
 	OutputVarMap         map[string]int   // 记录画图变量
 	_idx                 int                   //记录画图变量
 	suffixParams         map[string][]string   // 记录变量名到修饰符的映射
+	suffixModifiers      map[string][]Modifier // 记录变量名到解析后修饰符的映射
+	current              Statement             // 正在执行的语句
 	Err                  error
 	SkipNilPointerCheck bool //if false, will panic on variable is nil
+	Tolerance           Tolerance // 比较运算的误差设置，零值为兼容旧版的 1% 误差
 }
 
 func (r *Interpreter) GetSuffixParams(name string) ([]string, bool) {
@@ -158,6 +239,17 @@ func (r *Interpreter) GetSuffixParams(name string) ([]string, bool) {
 	return params, ok
 }
 
+// GetSuffixModifiers 获取变量解析后的修饰符
+func (r *Interpreter) GetSuffixModifiers(name string) ([]Modifier, bool) {
+	mods, ok := r.suffixModifiers[name]
+	return mods, ok
+}
+
+// CurrentStatement 返回正在执行的语句，画图函数用它获取变量名和修饰符
+func (r *Interpreter) CurrentStatement() Statement {
+	return r.current
+}
+
 func (r *Interpreter) getOutputVariableId() int {
 	r._idx++
 	return r._idx
@@ -166,9 +258,10 @@ func (r *Interpreter) getOutputVariableId() int {
 // NewInterpreter 创建一个新的解释器
 func NewInterpreter(env *Environment) *Interpreter {
 	return &Interpreter{
-		env:          env,
-		OutputVarMap: make(map[string]int),
-		suffixParams: make(map[string][]string),
+		env:             env,
+		OutputVarMap:    make(map[string]int),
+		suffixParams:    make(map[string][]string),
+		suffixModifiers: make(map[string][]Modifier),
 	}
 }
 
@@ -188,6 +281,12 @@ func (i *Interpreter) Eval(node Node) interface{} {
 	case *UnaryExpression:
 		Logger.Println("Evaluating unary expression")
 		return i.evalUnaryExpression(node)
+	case *ConditionalExpression:
+		Logger.Println("Evaluating conditional expression")
+		return i.evalConditionalExpression(node)
+	case *IndexExpression:
+		Logger.Println("Evaluating index expression")
+		return i.evalIndexExpression(node)
 	case *NumberLiteral:
 		Logger.Println("Evaluating number literal:", node.Value)
 		return node.Value
@@ -214,6 +313,7 @@ func (i *Interpreter) evalProgram(program *Program) interface{} {
 	Logger.Println("Evaluating program with", len(program.Statements), "statements")
 	for idx, statement := range program.Statements {
 		Logger.Println("Evaluating statement", idx)
+		i.current = statement
 		result = i.Eval(statement)
 		Logger.Println("Result of statement", idx, ":", result)
 	}
@@ -237,6 +337,7 @@ func (i *Interpreter) evalAssignmentStatement(stmt *AssignmentStatement) interfa
 	// 存储修饰符
 	if len(stmt.SuffixParams) > 0 {
 		i.suffixParams[stmt.Name.Value] = stmt.SuffixParams
+		i.suffixModifiers[stmt.Name.Value] = stmt.Modifiers
 		Logger.Println("Added suffix params for", stmt.Name.Value, ":", stmt.SuffixParams)
 	}
 
@@ -262,11 +363,146 @@ func (i *Interpreter) evalUnaryExpression(ue *UnaryExpression) interface{} {
 		}
 		// 其他类型不支持负号，返回 NaN 或错误
 		return math.NaN()
+	case "+": // 正号不改变数值
+		if f, ok := right.(float64); ok {
+			return f
+		} else if arr, ok := i.toFloat64Slice(right); ok {
+			return arr
+		}
+		return math.NaN()
 	}
 
 	return nil
 }
 
+// arithmetic 两个数的算术运算：+ - * /，% 和 MOD 取余，^ 和 ** 乘方；除数为 0 时为 NaN
+func arithmetic(operator string, a, b float64) float64 {
+	switch operator {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			return math.NaN()
+		}
+		return a / b
+	case "%", "MOD":
+		if b == 0 {
+			return math.NaN()
+		}
+		return math.Mod(a, b)
+	case "^", "**":
+		return math.Pow(a, b)
+	}
+	return math.NaN()
+}
+
+// evalConditionalExpression 条件表达式：条件为数值时只计算选中的分支，为数组时按元素选择，
+// 分支可以是数值或者数组
+func (i *Interpreter) evalConditionalExpression(ce *ConditionalExpression) interface{} {
+	cond := i.Eval(ce.Condition)
+	condArr, ok := i.toFloat64Slice(cond)
+	if _, isNum := cond.(float64); isNum || !ok {
+		if i.toBool(cond) {
+			return i.Eval(ce.Consequence)
+		}
+		return i.Eval(ce.Alternative)
+	}
+	a, b := i.Eval(ce.Consequence), i.Eval(ce.Alternative)
+	aArr, aIsArr := i.toFloat64Slice(a)
+	bArr, bIsArr := i.toFloat64Slice(b)
+	aVal, bVal := i.toFloat64(a), i.toFloat64(b)
+	result := make([]float64, len(condArr))
+	for idx, c := range condArr {
+		x, y := aVal, bVal
+		if aIsArr {
+			x = valueAt(aArr, idx)
+		}
+		if bIsArr {
+			y = valueAt(bArr, idx)
+		}
+		if isTruthy(c) {
+			result[idx] = x
+		} else {
+			result[idx] = y
+		}
+	}
+	return result
+}
+
+// evalIndexExpression 索引和切片，超出范围的位置为 NaN：
+//   - C[N]，N >= 0 为 N 个周期前的值，同 REF(C,N)；N 可以是数组，按元素计算
+//   - C[-N] 为倒数第 N 根 K 线的值（数值），C[-1] 为最后一根
+//   - C[M:N] 保留第 M 到 N-1 根 K 线（从 0 开始）的值，其余位置为 NaN，长度不变，与 K 线对齐；
+//     负数从末尾计算，省略时为开头或结尾
+//
+// 常数的索引和切片仍然是常数
+func (i *Interpreter) evalIndexExpression(ie *IndexExpression) interface{} {
+	left := i.Eval(ie.Left)
+	arr, ok := i.toFloat64Slice(left)
+	if _, isNum := left.(float64); isNum || !ok {
+		return left
+	}
+	if ie.IsSlice {
+		bound := func(e Expression, def int) int {
+			if e == nil {
+				return def
+			}
+			v := i.toFloat64(i.Eval(e))
+			if math.IsNaN(v) {
+				return def
+			}
+			n := int(v)
+			if n < 0 {
+				n += len(arr)
+			}
+			return n
+		}
+		start, end := bound(ie.Start, 0), bound(ie.End, len(arr))
+		result := make([]float64, len(arr))
+		for idx := range arr {
+			result[idx] = math.NaN()
+			if idx >= start && idx < end {
+				result[idx] = arr[idx]
+			}
+		}
+		return result
+	}
+
+	index := i.Eval(ie.Index)
+	offsets, isArr := i.toFloat64Slice(index)
+	n := i.toFloat64(index)
+	if _, isNum := index.(float64); isNum {
+		if n < 0 {
+			return indicators.Series(arr).At(len(arr) + int(n))
+		}
+		isArr = false
+	}
+	result := make([]float64, len(arr))
+	for idx := range arr {
+		if isArr {
+			n = valueAt(offsets, idx)
+		}
+		if math.IsNaN(n) || n < 0 {
+			result[idx] = math.NaN()
+			continue
+		}
+		result[idx] = indicators.Series(arr).At(idx - int(n))
+	}
+	return result
+}
+
+// valueAt 数组第 idx 个元素，超出长度时为 NaN
+func valueAt(arr []float64, idx int) float64 {
+	if idx < len(arr) {
+		return arr[idx]
+	}
+	return math.NaN()
+}
+
 func (i *Interpreter) evalBinaryExpression(be *BinaryExpression) interface{} {
 	left := i.Eval(be.Left)
 	right := i.Eval(be.Right)
@@ -301,19 +537,7 @@ func (i *Interpreter) evalBinaryExpression(be *BinaryExpression) interface{} {
 	rightVal, rok := right.(float64)
 
 	if lok && rok {
-		switch be.Operator {
-		case "+":
-			return leftVal + rightVal
-		case "-":
-			return leftVal - rightVal
-		case "*":
-			return leftVal * rightVal
-		case "/":
-			if rightVal == 0 {
-				return math.NaN()
-			}
-			return leftVal / rightVal
-		}
+		return arithmetic(be.Operator, leftVal, rightVal)
 	}
 
 	// 处理数组和浮点数的操作，兼容 Series 类型
@@ -323,36 +547,13 @@ func (i *Interpreter) evalBinaryExpression(be *BinaryExpression) interface{} {
 	if larrOk && rok { // 数组与浮点数操作
 		result := make([]float64, len(leftArr))
 		for i := 0; i < len(leftArr); i++ {
-			switch be.Operator {
-			case "+":
-				result[i] = leftArr[i] + rightVal
-			case "-":
-				result[i] = leftArr[i] - rightVal
-			case "*":
-				result[i] = leftArr[i] * rightVal
-			case "/":
-				if rightVal == 0 {
-					// return nil
-					result[i] = math.NaN()
-					continue
-				}
-				result[i] = leftArr[i] / rightVal
-			}
+			result[i] = arithmetic(be.Operator, leftArr[i], rightVal)
 		}
 		return result
 	} else if lok && rarrOk { // 浮点数与数组操作
 		result := make([]float64, len(rightArr))
 		for i := 0; i < len(rightArr); i++ {
-			switch be.Operator {
-			case "+":
-				result[i] = leftVal + rightArr[i]
-			case "-":
-				result[i] = leftVal - rightArr[i]
-			case "*":
-				result[i] = leftVal * rightArr[i]
-			case "/":
-				result[i] = leftVal / rightArr[i]
-			}
+			result[i] = arithmetic(be.Operator, leftVal, rightArr[i])
 		}
 		return result
 	} else if larrOk && rarrOk { // 数组与数组操作
@@ -362,21 +563,7 @@ func (i *Interpreter) evalBinaryExpression(be *BinaryExpression) interface{} {
 		}
 		result := make([]float64, len(leftArr))
 		for i := 0; i < len(leftArr); i++ {
-			switch be.Operator {
-			case "+":
-				result[i] = leftArr[i] + rightArr[i]
-			case "-":
-				result[i] = leftArr[i] - rightArr[i]
-			case "*":
-				result[i] = leftArr[i] * rightArr[i]
-			case "/":
-				if rightArr[i] == 0 {
-					// return nil
-					result[i] = math.NaN()
-					continue
-				}
-				result[i] = leftArr[i] / rightArr[i]
-			}
+			result[i] = arithmetic(be.Operator, leftArr[i], rightArr[i])
 		}
 		return result
 	}
@@ -384,8 +571,10 @@ func (i *Interpreter) evalBinaryExpression(be *BinaryExpression) interface{} {
 }
 
 func (i *Interpreter) evalIdentifier(symbol *Identifier) interface{} {
+	// 不区分大小写时，自定义变量和内置变量按大写名称查找
+	name := i.env.key(symbol.Value)
 	if i.CustomVariableGetter != nil {
-		x := i.CustomVariableGetter(symbol.Value)
+		x := i.CustomVariableGetter(name)
 		if x != nil {
 			return x
 		}
@@ -394,6 +583,11 @@ func (i *Interpreter) evalIdentifier(symbol *Identifier) interface{} {
 		Logger.Println("Found identifier", symbol.Value, "with value", val)
 		return val
 	}
+	if i.BuiltinVariableGetter != nil {
+		if x := i.BuiltinVariableGetter(name); x != nil {
+			return x
+		}
+	}
 	Logger.Println("Identifier", symbol.Value, "not found")
 	if !i.SkipNilPointerCheck {
 		log.Panicf("Variable Miss: %s",symbol.Value)
@@ -492,7 +686,7 @@ func (i *Interpreter) toBool(value interface{}) bool {
 	case bool:
 		return v
 	case float64:
-		return v != 0
+		return isTruthy(v)
 	case int:
 		return v != 0
 	case string:
@@ -500,7 +694,7 @@ func (i *Interpreter) toBool(value interface{}) bool {
 	case []float64:
 		// 对于数组，检查是否有任何非零元素
 		for _, val := range v {
-			if val != 0 {
+			if isTruthy(val) {
 				return true
 			}
 		}
@@ -508,7 +702,7 @@ func (i *Interpreter) toBool(value interface{}) bool {
 	case indicators.Series:
 		// 对于 Series，检查是否有任何非零元素
 		for _, val := range v {
-			if val != 0 {
+			if isTruthy(val) {
 				return true
 			}
 		}
@@ -532,30 +726,28 @@ func (i *Interpreter) evalComparison(left, right interface{}, operator string) i
 		rightVal := i.toFloat64(right)
 		return i.compareArrayWithScalar(leftArr, rightVal, operator)
 	} else if rightIsArr {
-		// 右边是数组，左边是标量
+		// 右边是数组，左边是标量，交换两边后运算符也要反过来
 		leftVal := i.toFloat64(left)
-		return i.compareArrayWithScalar(rightArr, leftVal, operator)
+		return i.compareArrayWithScalar(rightArr, leftVal, flipComparison(operator))
 	}
 
-	// 标量比较
-	leftVal := i.toFloat64(left)
-	rightVal := i.toFloat64(right)
+	// 标量比较，任一边为 NaN 时结果为 false
+	return i.Tolerance.Compare(i.toFloat64(left), i.toFloat64(right), operator)
+}
 
+// flipComparison 返回交换左右两边后等价的比较运算符
+func flipComparison(operator string) string {
 	switch operator {
 	case ">":
-		return leftVal > rightVal
+		return "<"
 	case "<":
-		return leftVal < rightVal
+		return ">"
 	case ">=":
-		return leftVal >= rightVal
+		return "<="
 	case "<=":
-		return leftVal <= rightVal
-	case "==", "=":
-		return floatEqual(leftVal, rightVal)
-	case "!=":
-		return !floatEqual(leftVal, rightVal)
+		return ">="
 	default:
-		return false
+		return operator
 	}
 }
 
@@ -566,47 +758,11 @@ func (i *Interpreter) compareArrays(leftArr, rightArr []float64, operator string
 		minLen = len(rightArr)
 	}
 
+	tol := i.Tolerance
 	result := make([]float64, minLen)
-	for i := 0; i < minLen; i++ {
-		switch operator {
-		case ">":
-			if leftArr[i] > rightArr[i] {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		case "<":
-			if leftArr[i] < rightArr[i] {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-	case ">=":
-		if leftArr[i] >= rightArr[i] {
-			result[i] = 1
-		} else {
-			result[i] = 0
-		}
-	case "<=":
-		if leftArr[i] <= rightArr[i] {
-			result[i] = 1
-		} else {
-			result[i] = 0
-		}
-	case "==", "=":
-		if floatEqual(leftArr[i], rightArr[i]) {
-			result[i] = 1
-		} else {
-			result[i] = 0
-		}
-	case "!=":
-		if !floatEqual(leftArr[i], rightArr[i]) {
-			result[i] = 1
-		} else {
-			result[i] = 0
-		}
-		default:
-			result[i] = 0
+	for idx := 0; idx < minLen; idx++ {
+		if tol.Compare(leftArr[idx], rightArr[idx], operator) {
+			result[idx] = 1
 		}
 	}
 	return result
@@ -614,47 +770,11 @@ func (i *Interpreter) compareArrays(leftArr, rightArr []float64, operator string
 
 // compareArrayWithScalar 比较数组和标量
 func (i *Interpreter) compareArrayWithScalar(arr []float64, scalar float64, operator string) []float64 {
+	tol := i.Tolerance
 	result := make([]float64, len(arr))
-	for i := 0; i < len(arr); i++ {
-		switch operator {
-		case ">":
-			if arr[i] > scalar {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		case "<":
-			if arr[i] < scalar {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		case ">=":
-			if arr[i] >= scalar {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		case "<=":
-			if arr[i] <= scalar {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		case "==", "=":
-			if floatEqual(arr[i], scalar) {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		case "!=":
-			if !floatEqual(arr[i], scalar) {
-				result[i] = 1
-			} else {
-				result[i] = 0
-			}
-		default:
-			result[i] = 0
+	for idx := 0; idx < len(arr); idx++ {
+		if tol.Compare(arr[idx], scalar, operator) {
+			result[idx] = 1
 		}
 	}
 	return result
@@ -723,8 +843,8 @@ func (i *Interpreter) logicalAndArrays(leftArr, rightArr []float64) []float64 {
 
 	result := make([]float64, minLen)
 	for idx := 0; idx < minLen; idx++ {
-		leftBool := leftArr[idx] != 0
-		rightBool := rightArr[idx] != 0
+		leftBool := isTruthy(leftArr[idx])
+		rightBool := isTruthy(rightArr[idx])
 		if leftBool && rightBool {
 			result[idx] = 1
 		} else {
@@ -738,7 +858,7 @@ func (i *Interpreter) logicalAndArrays(leftArr, rightArr []float64) []float64 {
 func (i *Interpreter) logicalAndArrayWithScalar(arr []float64, scalar bool) []float64 {
 	result := make([]float64, len(arr))
 	for idx := 0; idx < len(arr); idx++ {
-		arrBool := arr[idx] != 0
+		arrBool := isTruthy(arr[idx])
 		if arrBool && scalar {
 			result[idx] = 1
 		} else {
@@ -757,8 +877,8 @@ func (i *Interpreter) logicalOrArrays(leftArr, rightArr []float64) []float64 {
 
 	result := make([]float64, minLen)
 	for idx := 0; idx < minLen; idx++ {
-		leftBool := leftArr[idx] != 0
-		rightBool := rightArr[idx] != 0
+		leftBool := isTruthy(leftArr[idx])
+		rightBool := isTruthy(rightArr[idx])
 		if leftBool || rightBool {
 			result[idx] = 1
 		} else {
@@ -772,7 +892,7 @@ func (i *Interpreter) logicalOrArrays(leftArr, rightArr []float64) []float64 {
 func (i *Interpreter) logicalOrArrayWithScalar(arr []float64, scalar bool) []float64 {
 	result := make([]float64, len(arr))
 	for idx := 0; idx < len(arr); idx++ {
-		arrBool := arr[idx] != 0
+		arrBool := isTruthy(arr[idx])
 		if arrBool || scalar {
 			result[idx] = 1
 		} else {
@@ -804,7 +924,7 @@ func (i *Interpreter) evalLogicalNot(right interface{}) interface{} {
 func (i *Interpreter) logicalNotArray(arr []float64) []float64 {
 	result := make([]float64, len(arr))
 	for idx := 0; idx < len(arr); idx++ {
-		arrBool := arr[idx] != 0
+		arrBool := isTruthy(arr[idx])
 		if arrBool {
 			result[idx] = 0
 		} else {
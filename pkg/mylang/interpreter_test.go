@@ -1,6 +1,8 @@
 package mylang
 
 import (
+	"fmt"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -410,3 +412,186 @@ func TestFloatComparison(t *testing.T) {
 		})
 	}
 }
+
+// TestNaNComparison 任一边为 NaN 的比较结果为 false，NaN 在逻辑运算中视为 false
+func TestNaNComparison(t *testing.T) {
+	env := NewEnvironment()
+	interp := NewInterpreter(env)
+
+	nan := math.NaN()
+	env.Set("A", []float64{nan, 1, 2, nan})
+	env.Set("B", []float64{1, nan, 1, nan})
+	env.Set("X", nan)
+
+	tests := []struct {
+		name     string
+		code     string
+		expected interface{}
+	}{
+		{name: "数组大于", code: "result := A > B;", expected: []float64{0, 0, 1, 0}},
+		{name: "数组小于等于", code: "result := A <= B;", expected: []float64{0, 0, 0, 0}},
+		{name: "数组相等", code: "result := A = B;", expected: []float64{0, 0, 0, 0}},
+		{name: "数组不等", code: "result := A != B;", expected: []float64{0, 0, 1, 0}},
+		{name: "数组和标量", code: "result := A > 0;", expected: []float64{0, 1, 1, 0}},
+		{name: "数组和 NaN 标量", code: "result := B < X;", expected: []float64{0, 0, 0, 0}},
+		{name: "标量 NaN 不等", code: "result := X != 1;", expected: false},
+		{name: "标量 NaN 相等", code: "result := X = X;", expected: false},
+		{name: "NaN AND", code: "result := A AND 1;", expected: []float64{0, 1, 1, 0}},
+		{name: "NaN OR", code: "result := A OR B;", expected: []float64{1, 1, 1, 0}},
+		{name: "NOT NaN", code: "result := NOT A;", expected: []float64{1, 0, 0, 1}},
+		{name: "NOT NaN 标量", code: "result := NOT X;", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := NewLexer(tt.code)
+			parser := NewParser(lexer)
+			program := parser.ParseProgram()
+			result := interp.Eval(program)
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("result = %v (%T), want %v (%T)", result, result, tt.expected, tt.expected)
+			}
+		})
+	}
+}
+
+// TestScalarOnLeftComparison 标量在左边、数组在右边时按原来的方向比较，5 > C 不能算成 C > 5
+func TestScalarOnLeftComparison(t *testing.T) {
+	env := NewEnvironment()
+	interp := NewInterpreter(env)
+	env.Set("C", []float64{4, 5, 6})
+
+	tests := []struct {
+		name     string
+		code     string
+		expected interface{}
+	}{
+		{name: "大于", code: "result := 5 > C;", expected: []float64{1, 0, 0}},
+		{name: "小于", code: "result := 5 < C;", expected: []float64{0, 0, 1}},
+		{name: "大于等于", code: "result := 5 >= C;", expected: []float64{1, 1, 0}},
+		{name: "小于等于", code: "result := 5 <= C;", expected: []float64{0, 1, 1}},
+		{name: "相等", code: "result := 5 = C;", expected: []float64{0, 1, 0}},
+		{name: "不等", code: "result := 5 != C;", expected: []float64{1, 0, 1}},
+		{name: "与数组在左边一致", code: "result := (5 > C) = (C < 5);", expected: []float64{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := NewParser(NewLexer(tt.code)).ParseProgram()
+			result := interp.Eval(program)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("result = %v (%T), want %v (%T)", result, result, tt.expected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArithmeticOperators(t *testing.T) {
+	env := NewEnvironment()
+	interp := NewInterpreter(env)
+	env.Set("ARR", []float64{1, 2, 3})
+	env.Set("MOD", func(args []interface{}) interface{} { return 42.0 })
+
+	tests := []struct {
+		name     string
+		code     string
+		expected interface{}
+	}{
+		{"取余", "result := 7 % 3;", 1.0},
+		{"MOD 运算符", "result := ARR MOD 2;", []float64{1, 0, 1}},
+		{"MOD 函数", "result := MOD(7,3) + 1;", 43.0},
+		{"取余优先级", "result := 1 + 7 % 4 * 2;", 7.0},
+		{"乘方", "result := 2 ^ 10;", 1024.0},
+		{"乘方 **", "result := ARR ** 2;", []float64{1, 4, 9}},
+		{"乘方右结合", "result := 2 ^ 3 ^ 2;", 512.0},
+		{"乘方优先于负号", "result := -2 ^ 2;", -4.0},
+		{"负指数", "result := 2 ^ -1;", 0.5},
+		{"正号", "result := +ARR * +2;", []float64{2, 4, 6}},
+		{"&& 和 ||", "result := ARR > 1 && ARR < 3 || ARR = 1;", []float64{1, 1, 0}},
+		{"条件表达式", "result := 1 > 2 ? 10 : 20;", 20.0},
+		{"条件表达式数组", "result := ARR > 1 ? ARR * 10 : -1;", []float64{-1, 20, 30}},
+		{"条件表达式嵌套", "result := ARR = 1 ? 10 : ARR = 2 ? 20 : 30;", []float64{10, 20, 30}},
+		{"条件表达式优先级", "result := ARR > 2 || ARR < 2 ? 1 : 0;", []float64{1, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(NewLexer(tt.code))
+			program := parser.ParseProgram()
+			if len(program.Errors) > 0 {
+				t.Fatalf("Errors = %v", program.Errors)
+			}
+			if result := interp.Eval(program); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("result = %v, want %v (%s)", result, tt.expected, program.Statements[0])
+			}
+		})
+	}
+
+	if result := interp.Eval(NewParser(NewLexer("result := 2 % 0;")).ParseProgram()); !math.IsNaN(result.(float64)) {
+		t.Errorf("2 %% 0 = %v, want NaN", result)
+	}
+	program := NewParser(NewLexer("result := -A ^ 2 MOD 3 ? B : C;")).ParseProgram()
+	if got, want := program.Statements[0].(*AssignmentStatement).Value.String(), "(((- (A ^ 2)) MOD 3) ? B : C)"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	program = NewParser(NewLexer("result := ARR > 1 ? 1;")).ParseProgram()
+	if want := []string{"第1行第19列：语法错误，条件表达式缺少 :"}; !reflect.DeepEqual(program.Errors, want) {
+		t.Errorf("Errors = %v, want %v", program.Errors, want)
+	}
+}
+
+func TestIndexExpression(t *testing.T) {
+	env := NewEnvironment()
+	interp := NewInterpreter(env)
+	env.Set("C", []float64{1, 2, 3, 4, 5})
+	env.Set("N", []float64{0, 1, 1, 3, math.NaN()})
+	nan := math.NaN()
+
+	tests := []struct {
+		name     string
+		code     string
+		expected interface{}
+	}{
+		{"相对索引", "result := C[1];", []float64{nan, 1, 2, 3, 4}},
+		{"索引 0", "result := C[0];", []float64{1, 2, 3, 4, 5}},
+		{"超出范围", "result := C[10];", []float64{nan, nan, nan, nan, nan}},
+		{"数组索引", "result := C[N];", []float64{1, 1, 2, 1, nan}},
+		{"倒数", "result := C[-1];", 5.0},
+		{"倒数超出范围", "result := C[-6];", nan},
+		{"表达式索引", "result := (C*2)[2-1] + C[-2];", []float64{nan, 6, 8, 10, 12}},
+		{"切片", "result := C[1:3];", []float64{nan, 2, 3, nan, nan}},
+		{"切片负数", "result := C[-2:];", []float64{nan, nan, nan, 4, 5}},
+		{"切片省略开头", "result := C[:2];", []float64{1, 2, nan, nan, nan}},
+		{"切片超出范围", "result := C[3:10];", []float64{nan, nan, nan, 4, 5}},
+		{"空切片", "result := C[3:1];", []float64{nan, nan, nan, nan, nan}},
+		{"切片与 K 线对齐", "result := C[-2:] - C;", []float64{nan, nan, nan, 0, 0}},
+		{"常数", "result := 5[1];", 5.0},
+		{"索引优先于负号", "result := -C[-1];", -5.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := NewParser(NewLexer(tt.code)).ParseProgram()
+			if len(program.Errors) > 0 {
+				t.Fatalf("Errors = %v", program.Errors)
+			}
+			result := interp.Eval(program)
+			if fmt.Sprint(result) != fmt.Sprint(tt.expected) {
+				t.Errorf("result = %v, want %v (%s)", result, tt.expected, program.Statements[0])
+			}
+		})
+	}
+
+	for code, want := range map[string]string{
+		"result := C[1:2];":   "C[1:2]",
+		"result := C[:N[1]];": "C[:N[1]]",
+		"result := C[-1:];":   "C[(- 1):]",
+	} {
+		program := NewParser(NewLexer(code)).ParseProgram()
+		if got := program.Statements[0].(*AssignmentStatement).Value.String(); got != want {
+			t.Errorf("String() = %s, want %s", got, want)
+		}
+	}
+	program := NewParser(NewLexer("result := C[1;")).ParseProgram()
+	if want := []string{"第1行第12列：语法错误，索引缺少 ]"}; !reflect.DeepEqual(program.Errors, want) {
+		t.Errorf("Errors = %v, want %v", program.Errors, want)
+	}
+}
@@ -1,6 +1,7 @@
 package mylang
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -36,6 +37,11 @@ const (
 	TokenLessEqual
 	TokenNotEqual
 	TokenString
+	TokenMod      // % 取余，也可以写作 MOD（中缀时）
+	TokenPower    // ^ 或 ** 乘方
+	TokenQuestion // ? 条件表达式 cond ? a : b
+	TokenLBracket // [ 索引和切片 C[1]、C[10:20]
+	TokenRBracket // ]
 )
 
 // Token 代表一个令牌
@@ -48,17 +54,35 @@ type Token struct {
 
 // Lexer 代表词法分析器
 type Lexer struct {
-	input   string
-	pos     int
-	readPos int
-	ch      rune
-	line    int // 当前行号
-	column  int // 当前列号
+	input           string
+	pos             int
+	readPos         int
+	ch              rune
+	line            int          // 当前行号
+	column          int          // 当前列号
+	inString        bool         // 正在读取字符串或注释，不转换全角符号
+	CaseInsensitive bool         // 关键字不区分大小写，如 And、Or；默认只识别全大写和全小写
+	Diagnostics     []Diagnostic // 词法分析的警告，如全角符号
+	Errors          []Diagnostic // 词法错误，如字符串或注释没有结束，对应的令牌为 TokenError
+}
+
+// fullWidthPunct 中文输入法输入的全角符号，按对应的半角符号处理
+var fullWidthPunct = map[rune]rune{
+	'；': ';', '（': '(', '）': ')', '，': ',', '：': ':', '＝': '=',
+	'＞': '>', '＜': '<', '！': '!', '＋': '+', '－': '-', '＊': '*', '／': '/',
+	'？': '?', '％': '%', '［': '[', '］': ']', '【': '[', '】': ']', '‘': '\'', '’': '\'', '　': ' ',
+}
+
+// keywords 关键字，Literal 统一为大写
+var keywords = map[string]TokenType{
+	"AND": TokenAnd,
+	"OR":  TokenOr,
+	"NOT": TokenNot,
 }
 
 // NewLexer 创建一个新的词法分析器
 func NewLexer(input string) *Lexer {
-	l := &Lexer{input: input, line: 1, column: 1}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
@@ -72,13 +96,20 @@ func (l *Lexer) readChar() {
 		l.ch, size = utf8.DecodeRuneInString(l.input[l.readPos:])
 		l.readPos += size
 		
-		// 更新行号和列号
+		// 更新行号和列号，列号从 1 开始，换行符算作下一行的第 0 列
 		if l.ch == '\n' {
 			l.line++
-			l.column = 1
+			l.column = 0
 		} else {
 			l.column++
 		}
+		if half, ok := fullWidthPunct[l.ch]; ok && !l.inString {
+			l.Diagnostics = append(l.Diagnostics, Diagnostic{
+				Line: l.line, Column: l.column,
+				Message: fmt.Sprintf("全角符号 %c 按 %c 处理", l.ch, half),
+			})
+			l.ch = half
+		}
 	}
 }
 
@@ -87,17 +118,31 @@ func (l *Lexer) peekChar() rune {
 		return 0
 	}
 	ch, _ := utf8.DecodeRuneInString(l.input[l.readPos:])
+	if half, ok := fullWidthPunct[ch]; ok {
+		return half
+	}
 	return ch
 }
 
+// errorf 记录 line 行 column 列的词法错误，返回对应的 TokenError
+func (l *Lexer) errorf(line, column int, literal string, format string, args ...any) Token {
+	l.Errors = append(l.Errors, Diagnostic{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	return Token{Type: TokenError, Literal: literal}
+}
+
+// NextToken 返回下一个令牌，跳过空白和注释；Line、Column 为令牌第一个字符的位置
 func (l *Lexer) NextToken() Token {
-	var tok Token
+	if tok, ok := l.skipTrivia(); !ok {
+		return tok
+	}
+	line, column := l.line, l.column
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
 
-	l.skipWhitespace()
-	
-	// 设置当前token的行号和列号
-	tok.Line = l.line
-	tok.Column = l.column
+func (l *Lexer) readToken() Token {
+	var tok Token
 
 	switch l.ch {
 	case '+':
@@ -107,7 +152,34 @@ func (l *Lexer) NextToken() Token {
 		tok = Token{Type: TokenMinus, Literal: string(l.ch)}
 		l.readChar()
 	case '*':
-		tok = Token{Type: TokenMultiply, Literal: string(l.ch)}
+		if l.peekChar() == '*' {
+			l.readChar()
+			tok = Token{Type: TokenPower, Literal: "**"}
+		} else {
+			tok = Token{Type: TokenMultiply, Literal: string(l.ch)}
+		}
+		l.readChar()
+	case '%':
+		tok = Token{Type: TokenMod, Literal: string(l.ch)}
+		l.readChar()
+	case '^':
+		tok = Token{Type: TokenPower, Literal: string(l.ch)}
+		l.readChar()
+	case '?':
+		tok = Token{Type: TokenQuestion, Literal: string(l.ch)}
+		l.readChar()
+	case '&', '|':
+		// && 和 || 同 AND、OR
+		if l.peekChar() == l.ch {
+			if l.ch == '&' {
+				tok = Token{Type: TokenAnd, Literal: "AND"}
+			} else {
+				tok = Token{Type: TokenOr, Literal: "OR"}
+			}
+			l.readChar()
+		} else {
+			tok = l.errorf(l.line, l.column, string(l.ch), "无法识别的字符 %c", l.ch)
+		}
 		l.readChar()
 	case '/':
 		tok = Token{Type: TokenDivide, Literal: string(l.ch)}
@@ -118,6 +190,12 @@ func (l *Lexer) NextToken() Token {
 	case ')':
 		tok = Token{Type: TokenRParen, Literal: string(l.ch)}
 		l.readChar()
+	case '[':
+		tok = Token{Type: TokenLBracket, Literal: string(l.ch)}
+		l.readChar()
+	case ']':
+		tok = Token{Type: TokenRBracket, Literal: string(l.ch)}
+		l.readChar()
 	case ';':
 		tok = Token{Type: TokenSemicolon, Literal: string(l.ch)}
 		l.readChar()
@@ -171,13 +249,13 @@ func (l *Lexer) NextToken() Token {
 			l.readChar()
 			tok = Token{Type: TokenNotEqual, Literal: "!="}
 		} else {
-			tok = Token{Type: TokenError, Literal: string(l.ch)}
+			tok = l.errorf(l.line, l.column, string(l.ch), "无法识别的字符 %c", l.ch)
 		}
 		l.readChar()
 	case '\'':
-		tok.Type = TokenString
-		tok.Literal = l.readString()
-		return tok
+		return l.readString()
+	case '"':
+		return l.readQuotedString()
 	case 0:
 		tok = Token{Type: TokenEOF, Literal: ""}
 	default:
@@ -185,32 +263,78 @@ func (l *Lexer) NextToken() Token {
 			tok.Literal = l.readIdentifier()
 			tok.Type = TokenIdentifier
 			// 检查是否为关键字
-			if tok.Literal == "AND" {
-				tok.Type = TokenAnd
-			} else if tok.Literal == "OR" || tok.Literal == "or" {
-				tok.Type = TokenOr
-			} else if tok.Literal == "NOT" || tok.Literal == "not" {
-				tok.Type = TokenNot
+			upper := strings.ToUpper(tok.Literal)
+			if typ, ok := keywords[upper]; ok && l.isKeyword(tok.Literal, upper) {
+				tok.Type = typ
+				tok.Literal = upper
 			}
 			return tok
-		} else if isDigit(l.ch) {
-			// 检查是否可能是标识符的一部分（如果前面有字母或下划线）
-			// 这里需要更复杂的逻辑来处理 abcd_1 这种情况
-			tok.Type = TokenNumber
-			tok.Literal = l.readNumber()
-			return tok
+		} else if isDigit(l.ch) || (l.ch == '.' && isDigit(l.peekChar())) {
+			return l.readNumber()
 		} else {
-			tok = Token{Type: TokenError, Literal: string(l.ch)}
+			tok = l.errorf(l.line, l.column, string(l.ch), "无法识别的字符 %c", l.ch)
 		}
 		l.readChar()
 	}
 	return tok
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+// isKeyword literal 是否为关键字 keyword：全大写或全小写，CaseInsensitive 时不区分大小写
+func (l *Lexer) isKeyword(literal, keyword string) bool {
+	if l.CaseInsensitive {
+		return strings.EqualFold(literal, keyword)
+	}
+	return literal == keyword || literal == strings.ToLower(keyword)
+}
+
+// skipTrivia 跳过空白和注释：{...}、// 到行尾、(* ... *)，注释没有结束时返回 TokenError 和 false
+func (l *Lexer) skipTrivia() (Token, bool) {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.inString = true
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+			l.inString = false
+		case l.ch == '{':
+			if tok, ok := l.skipComment("{", "}"); !ok {
+				return tok, false
+			}
+		case l.ch == '(' && l.peekChar() == '*':
+			if tok, ok := l.skipComment("(*", "*)"); !ok {
+				return tok, false
+			}
+		default:
+			return Token{}, true
+		}
+	}
+}
+
+// skipComment 跳过 open 开始、close 结束的注释，当前字符为 open 的第一个字符，注释不能嵌套。
+// 注释中的全角符号不转换；没有结束时返回位于注释开始处的 TokenError
+func (l *Lexer) skipComment(open, close string) (Token, bool) {
+	line, column, pos := l.line, l.column, l.pos
+	l.inString = true
+	defer func() { l.inString = false }()
+	for range open {
+		l.readChar()
+	}
+	for l.ch != 0 {
+		if strings.HasPrefix(l.input[l.pos:], close) {
+			l.inString = false
+			for range close {
+				l.readChar()
+			}
+			return Token{}, true
+		}
 		l.readChar()
 	}
+	tok := l.errorf(line, column, l.input[pos:], "注释没有结束，缺少 %s", close)
+	tok.Line, tok.Column = line, column
+	return tok, false
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -221,27 +345,119 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[pos:l.pos]
 }
 
-func (l *Lexer) readNumber() string {
-	pos := l.pos
-	for isDigit(l.ch) || l.ch == '.' {
+// readNumber 读取数字：整数、小数（可以省略整数部分，如 .5）和指数（如 1e-3、2.5E+4）。
+// 数字在字母处结束，C>10AND O<5 中的 10 和 AND 是两个 token；e、E 后面是数字或者正负号加数字时才是指数，
+// 10EMA 中的 E 仍然是标识符的开头。重复的小数点、不完整的指数（如 1.2.3、1e、1e+）返回 TokenError
+func (l *Lexer) readNumber() Token {
+	line, column, pos := l.line, l.column, l.pos
+	digits := func() {
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+	digits()
+	if l.ch == '.' {
 		l.readChar()
+		digits()
 	}
-	return l.input[pos:l.pos]
+	if l.ch == 'e' || l.ch == 'E' {
+		rest := l.input[l.readPos:]
+		signed := len(rest) > 0 && (rest[0] == '+' || rest[0] == '-')
+		if signed {
+			rest = rest[1:]
+		}
+		next, _ := utf8.DecodeRuneInString(rest)
+		switch {
+		case isDigit(next):
+			l.readChar()
+			if signed {
+				l.readChar()
+			}
+			digits()
+		case !signed && isLetter(next):
+			// 字母开头的标识符，数字到这里结束
+			return Token{Type: TokenNumber, Literal: l.input[pos:l.pos]}
+		default:
+			l.readChar()
+			if signed {
+				l.readChar()
+			}
+			return l.errorf(line, column, l.input[pos:l.pos], "数字格式错误 %s", l.input[pos:l.pos])
+		}
+	}
+	if l.ch == '.' {
+		for l.ch == '.' || isDigit(l.ch) {
+			l.readChar()
+		}
+		return l.errorf(line, column, l.input[pos:l.pos], "数字格式错误 %s", l.input[pos:l.pos])
+	}
+	return Token{Type: TokenNumber, Literal: l.input[pos:l.pos]}
 }
 
-func (l *Lexer) readString() string {
-	pos := l.pos + 1 // 跳过开始的单引号
+// readString 读取单引号字符串，不支持转义；开始的引号是全角的 ‘ 时也可以用 ’ 结束；字符串中的全角符号保持不变
+func (l *Lexer) readString() Token {
+	line, column := l.line, l.column
+	fullWidth := l.input[l.pos] != '\''
+	pos := l.readPos // 跳过开始的单引号
+	l.inString = true
 	for {
 		l.readChar()
-		if l.ch == '\'' || l.ch == 0 {
+		if l.ch == '\'' || l.ch == 0 || l.ch == '\n' || (fullWidth && l.ch == '’') {
 			break
 		}
 	}
-	// 如果遇到结束的单引号，跳过它
-	if l.ch == '\'' {
+	l.inString = false
+	if l.ch != '\'' && l.ch != '’' {
+		return l.errorf(line, column, l.input[pos-1:l.pos], "字符串没有结束，缺少 '")
+	}
+	end := l.pos
+	l.readChar() // 跳过结束的单引号
+	return Token{Type: TokenString, Literal: l.input[pos:end]}
+}
+
+// stringEscapes 双引号字符串支持的转义字符
+var stringEscapes = map[rune]rune{
+	'"': '"', '\'': '\'', '\\': '\\', 'n': '\n', 't': '\t', 'r': '\r',
+}
+
+// readQuotedString 读取双引号字符串，支持 \"、\'、\\、\n、\t、\r 转义，不能跨行
+func (l *Lexer) readQuotedString() Token {
+	line, column, pos := l.line, l.column, l.pos
+	var out strings.Builder
+	l.inString = true
+	defer func() { l.inString = false }()
+	for {
 		l.readChar()
+		switch l.ch {
+		case '"':
+			l.inString = false
+			l.readChar()
+			return Token{Type: TokenString, Literal: out.String()}
+		case 0, '\n':
+			return l.errorf(line, column, l.input[pos:l.pos], "字符串没有结束，缺少 \"")
+		case '\\':
+			escLine, escColumn, escPos := l.line, l.column, l.pos
+			l.readChar()
+			esc := l.input[escPos:l.readPos]
+			c, ok := stringEscapes[l.ch]
+			if !ok {
+				if l.ch == 0 || l.ch == '\n' {
+					return l.errorf(line, column, l.input[pos:l.pos], "字符串没有结束，缺少 \"")
+				}
+				for l.ch != '"' && l.ch != 0 && l.ch != '\n' {
+					l.readChar()
+				}
+				if l.ch == '"' {
+					l.inString = false
+					l.readChar()
+				}
+				return l.errorf(escLine, escColumn, l.input[pos:l.pos], "无效的转义字符 %s", esc)
+			}
+			out.WriteRune(c)
+		default:
+			out.WriteRune(l.ch)
+		}
 	}
-	return l.input[pos : l.pos-1] // 去掉结束的单引号
 }
 
 func isLetter(ch rune) bool {
@@ -252,7 +468,9 @@ func isDigit(ch rune) bool {
 	return unicode.IsDigit(ch)
 }
 
-// TrimComment 去除代码中的注释
+// TrimComment 去除代码中的 {...} 注释
+//
+// Deprecated: 词法分析器会跳过注释并保留行号，字符串中的 { 也不会被当作注释，不需要预先去除
 func TrimComment(code string) string {
 	if !strings.Contains(code, "{") {
 		return code
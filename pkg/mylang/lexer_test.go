@@ -2,6 +2,7 @@ package mylang
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -217,4 +218,140 @@ func TestLexerComplexExpressions(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestLexerFullWidthPunctuation(t *testing.T) {
+	l := NewLexer("MA5：＝MA（C，5）；\nB：C＞MA5 AND ‘买，卖’；")
+	var got []Token
+	for {
+		tok := l.NextToken()
+		got = append(got, Token{Type: tok.Type, Literal: tok.Literal})
+		if tok.Type == TokenEOF || tok.Type == TokenError {
+			break
+		}
+	}
+	want := []Token{
+		{TokenIdentifier, "MA5", 0, 0}, {TokenColonEqual, ":=", 0, 0}, {TokenIdentifier, "MA", 0, 0},
+		{TokenLParen, "(", 0, 0}, {TokenIdentifier, "C", 0, 0}, {TokenComma, ",", 0, 0}, {TokenNumber, "5", 0, 0},
+		{TokenRParen, ")", 0, 0}, {TokenSemicolon, ";", 0, 0},
+		{TokenIdentifier, "B", 0, 0}, {TokenColon, ":", 0, 0}, {TokenIdentifier, "C", 0, 0},
+		{TokenGreaterThan, ">", 0, 0}, {TokenIdentifier, "MA5", 0, 0}, {TokenAnd, "AND", 0, 0},
+		{TokenString, "买，卖", 0, 0}, {TokenSemicolon, ";", 0, 0}, {TokenEOF, "", 0, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokens = %v, want %v", got, want)
+	}
+	// 字符串中的全角逗号保持不变，不产生警告
+	if len(l.Diagnostics) != 10 {
+		t.Errorf("diagnostics = %v", l.Diagnostics)
+	}
+	if d := l.Diagnostics[0]; d.Line != 1 || d.Message != "全角符号 ： 按 : 处理" {
+		t.Errorf("diagnostics[0] = %v", d)
+	}
+}
+
+func TestLexerKeywordCase(t *testing.T) {
+	tests := []struct {
+		input           string
+		caseInsensitive bool
+		want            TokenType
+	}{
+		{"AND", false, TokenAnd},
+		{"and", false, TokenAnd},
+		{"And", false, TokenIdentifier},
+		{"And", true, TokenAnd},
+		{"or", false, TokenOr},
+		{"Or", true, TokenOr},
+		{"nOT", true, TokenNot},
+		{"ANDX", true, TokenIdentifier},
+	}
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		l.CaseInsensitive = tt.caseInsensitive
+		tok := l.NextToken()
+		if tok.Type != tt.want {
+			t.Errorf("%s (caseInsensitive=%v) = %v, want %v", tt.input, tt.caseInsensitive, tok.Type, tt.want)
+		}
+		if tok.Type != TokenIdentifier && tok.Literal != strings.ToUpper(tt.input) {
+			t.Errorf("%s literal = %s", tt.input, tok.Literal)
+		}
+	}
+}
+
+func TestLexerNumbersCommentsStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token
+	}{
+		{
+			name:  "科学计数法",
+			input: "1e-3 2.5E+4 .5 10",
+			want:  []Token{{TokenNumber, "1e-3", 1, 1}, {TokenNumber, "2.5E+4", 1, 6}, {TokenNumber, ".5", 1, 13}, {TokenNumber, "10", 1, 16}},
+		},
+		{
+			name:  "数字在字母处结束",
+			input: "C>10AND O<5 10EMA 1e5OR",
+			want: []Token{
+				{TokenIdentifier, "C", 1, 1}, {TokenGreaterThan, ">", 1, 2}, {TokenNumber, "10", 1, 3}, {TokenAnd, "AND", 1, 5},
+				{TokenIdentifier, "O", 1, 9}, {TokenLessThan, "<", 1, 10}, {TokenNumber, "5", 1, 11},
+				{TokenNumber, "10", 1, 13}, {TokenIdentifier, "EMA", 1, 15}, {TokenNumber, "1e5", 1, 19}, {TokenOr, "OR", 1, 22},
+			},
+		},
+		{
+			name:  "注释",
+			input: "A:=1; // 行尾注释；\n{ 注释 } B(* 多行\n注释 *):2;",
+			want: []Token{
+				{TokenIdentifier, "A", 1, 1}, {TokenColonEqual, ":=", 1, 2}, {TokenNumber, "1", 1, 4}, {TokenSemicolon, ";", 1, 5},
+				{TokenIdentifier, "B", 2, 8}, {TokenColon, ":", 3, 6}, {TokenNumber, "2", 3, 7}, {TokenSemicolon, ";", 3, 8},
+			},
+		},
+		{
+			name:  "字符串",
+			input: `'{a}' "买\"入\n\\" 'x\y'`,
+			want:  []Token{{TokenString, "{a}", 1, 1}, {TokenString, "买\"入\n\\", 1, 7}, {TokenString, `x\y`, 1, 18}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLexer(tt.input)
+			var got []Token
+			for tok := l.NextToken(); tok.Type != TokenEOF; tok = l.NextToken() {
+				got = append(got, tok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokens = %v, want %v", got, tt.want)
+			}
+			if len(l.Errors) > 0 || len(l.Diagnostics) > 0 {
+				t.Errorf("errors = %v, diagnostics = %v", l.Errors, l.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"A:1.2.3;", "第1行第3列：数字格式错误 1.2.3"},
+		{"A:1e;", "第1行第3列：数字格式错误 1e"},
+		{"A:1E+;", "第1行第3列：数字格式错误 1E+"},
+		{"A:1..2;", "第1行第3列：数字格式错误 1..2"},
+		{"A:=1;\nB:'abc;", "第2行第3列：字符串没有结束，缺少 '"},
+		{`A:"abc;`, `第1行第3列：字符串没有结束，缺少 "`},
+		{`A:"a\qb";`, `第1行第5列：无效的转义字符 \q`},
+		{"A:1;\n  { abc", "第2行第3列：注释没有结束，缺少 }"},
+		{"A:1; (* abc *", "第1行第6列：注释没有结束，缺少 *)"},
+		{"A:1 @ 2;", "第1行第5列：无法识别的字符 @"},
+	}
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		hasError := false
+		for tok := l.NextToken(); tok.Type != TokenEOF; tok = l.NextToken() {
+			hasError = hasError || tok.Type == TokenError
+		}
+		if !hasError || len(l.Errors) != 1 || l.Errors[0].String() != tt.want {
+			t.Errorf("%q errors = %v, want %s", tt.input, l.Errors, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package mylang
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModifierKind 修饰符的类别
+type ModifierKind string
+
+const (
+	ModifierColor      ModifierKind = "COLOR"      // 颜色：COLORRED，或者通达信格式的 COLORBBGGRR
+	ModifierLineStyle  ModifierKind = "LINESTYLE"  // 画法：STICK、COLORSTICK、VOLSTICK、LINESTICK、POINTDOT、CROSSDOT、CIRCLEDOT、DOTLINE、DASHLINE
+	ModifierLineThick  ModifierKind = "LINETHICK"  // 线宽：LINETHICK0~LINETHICK9
+	ModifierVisibility ModifierKind = "VISIBILITY" // 显示：NODRAW、NOTEXT、NOTITLE
+	ModifierPane       ModifierKind = "PANE"       // 位置：MAIN 主图，SUB<n> 第 n 个副图
+	ModifierPrecision  ModifierKind = "PRECISION"  // 数值显示的小数位数：PRECIS0~PRECIS9
+	ModifierAlign      ModifierKind = "ALIGN"      // 文字对齐：ALIGN0~ALIGN2 水平，VALIGN0~VALIGN2 垂直
+)
+
+// Modifier 解析后的修饰符
+type Modifier struct {
+	Kind  ModifierKind
+	Raw   string // 源码中的写法
+	Name  string // 规范名称：颜色名（如 RED，十六进制颜色为空）、画法、显示方式、MAIN/SUB、ALIGN/VALIGN
+	Color string // 颜色，#RRGGBB
+	Value int    // 线宽、副图编号、小数位数、对齐方式
+}
+
+func (m Modifier) String() string {
+	return m.Raw
+}
+
+// modifierColors 通达信的颜色常量，值为 #RRGGBB
+var modifierColors = map[string]string{
+	"BLACK":     "#000000",
+	"BLUE":      "#0000FF",
+	"GREEN":     "#00FF00",
+	"CYAN":      "#00FFFF",
+	"RED":       "#FF0000",
+	"MAGENTA":   "#FF00FF",
+	"BROWN":     "#808000",
+	"LIGRAY":    "#C0C0C0",
+	"GRAY":      "#808080",
+	"LIBLUE":    "#8080FF",
+	"LIGREEN":   "#80FF80",
+	"LICYAN":    "#80FFFF",
+	"LIRED":     "#FF8080",
+	"LIMAGENTA": "#FF80FF",
+	"YELLOW":    "#FFFF00",
+	"WHITE":     "#FFFFFF",
+	"ORANGE":    "#FFA500",
+	// 兼容的写法
+	"LIGHTGRAY":  "#C0C0C0",
+	"LIGHTBLUE":  "#8080FF",
+	"LIGHTGREEN": "#80FF80",
+	"LIGHTRED":   "#FF8080",
+}
+
+var modifierNames = map[string]ModifierKind{
+	"STICK": ModifierLineStyle, "COLORSTICK": ModifierLineStyle, "VOLSTICK": ModifierLineStyle,
+	"LINESTICK": ModifierLineStyle, "POINTDOT": ModifierLineStyle, "CROSSDOT": ModifierLineStyle,
+	"CIRCLEDOT": ModifierLineStyle, "DOTLINE": ModifierLineStyle, "DASHLINE": ModifierLineStyle,
+	"NODRAW": ModifierVisibility, "NOTEXT": ModifierVisibility, "NOTITLE": ModifierVisibility,
+	"MAIN": ModifierPane,
+}
+
+var (
+	// COLORBBGGRR 的十六进制颜色，顺序为蓝绿红
+	modifierHexColorPattern = regexp.MustCompile(`^COLOR([0-9A-F]{6})$`)
+	// 字符串形式的颜色，如 '#FF0000'，顺序为红绿蓝
+	modifierRGBPattern     = regexp.MustCompile(`^#([0-9A-F]{6})$`)
+	modifierNumericPattern = regexp.MustCompile(`^(LINETHICK|SUB|PRECIS|ALIGN|VALIGN)([0-9]+)$`)
+)
+
+// ParseModifier 解析并校验一个修饰符，大小写不敏感
+func ParseModifier(raw string) (Modifier, error) {
+	m := Modifier{Raw: raw}
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	if unquoted, ok := strings.CutPrefix(s, "COLOR'"); ok {
+		s = "'" + unquoted
+	}
+	if strings.HasPrefix(s, "'") {
+		s = strings.Trim(s, "'")
+		if r := modifierRGBPattern.FindStringSubmatch(s); r != nil {
+			m.Kind, m.Color = ModifierColor, "#"+r[1]
+			return m, nil
+		}
+		if c, ok := modifierColors[s]; ok {
+			m.Kind, m.Name, m.Color = ModifierColor, s, c
+			return m, nil
+		}
+		return m, fmt.Errorf("无效的颜色 %s", raw)
+	}
+	if kind, ok := modifierNames[s]; ok {
+		m.Kind, m.Name = kind, s
+		return m, nil
+	}
+	if name, ok := strings.CutPrefix(s, "COLOR"); ok {
+		if c, ok := modifierColors[name]; ok {
+			m.Kind, m.Name, m.Color = ModifierColor, name, c
+			return m, nil
+		}
+		if h := modifierHexColorPattern.FindStringSubmatch(s); h != nil {
+			m.Kind, m.Color = ModifierColor, "#"+h[1][4:6]+h[1][2:4]+h[1][0:2]
+			return m, nil
+		}
+	}
+	if n := modifierNumericPattern.FindStringSubmatch(s); n != nil {
+		v, _ := strconv.Atoi(n[2])
+		m.Name, m.Value = n[1], v
+		switch n[1] {
+		case "LINETHICK":
+			m.Kind, m.Name = ModifierLineThick, ""
+			if v > 9 {
+				return m, fmt.Errorf("线宽 %s 超出范围 0~9", raw)
+			}
+		case "SUB":
+			m.Kind = ModifierPane
+			if v < 1 || v > 99 {
+				return m, fmt.Errorf("副图编号 %s 超出范围 1~99", raw)
+			}
+		case "PRECIS":
+			m.Kind, m.Name = ModifierPrecision, ""
+			if v > 9 {
+				return m, fmt.Errorf("小数位数 %s 超出范围 0~9", raw)
+			}
+		default:
+			m.Kind = ModifierAlign
+			if v > 2 {
+				return m, fmt.Errorf("对齐方式 %s 超出范围 0~2", raw)
+			}
+		}
+		return m, nil
+	}
+	return m, fmt.Errorf("未知的修饰符 %s", raw)
+}
+
+// ParseModifiers 解析一组修饰符，跳过无效的修饰符并返回它们的错误
+func ParseModifiers(params []string) ([]Modifier, []error) {
+	var mods []Modifier
+	var errs []error
+	for _, p := range params {
+		m, err := ParseModifier(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mods = append(mods, m)
+	}
+	return mods, errs
+}
+
+// Diagnostic 编译时的警告，不影响执行，如未知的修饰符
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("第%d行第%d列：%s", d.Line, d.Column, d.Message)
+}
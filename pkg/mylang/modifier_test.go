@@ -0,0 +1,86 @@
+package mylang
+
+import (
+	"io"
+	"log"
+	"reflect"
+	"testing"
+)
+
+func TestParseModifier(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Modifier
+	}{
+		{"COLORRED", Modifier{Kind: ModifierColor, Name: "RED", Color: "#FF0000"}},
+		{"colorliblue", Modifier{Kind: ModifierColor, Name: "LIBLUE", Color: "#8080FF"}},
+		{"COLOR00FF00", Modifier{Kind: ModifierColor, Color: "#00FF00"}},
+		{"COLOR0000FF", Modifier{Kind: ModifierColor, Color: "#FF0000"}},
+		{"'#1e90ff'", Modifier{Kind: ModifierColor, Color: "#1E90FF"}},
+		{"COLOR'yellow'", Modifier{Kind: ModifierColor, Name: "YELLOW", Color: "#FFFF00"}},
+		{"DOTLINE", Modifier{Kind: ModifierLineStyle, Name: "DOTLINE"}},
+		{"COLORSTICK", Modifier{Kind: ModifierLineStyle, Name: "COLORSTICK"}},
+		{"LINETHICK0", Modifier{Kind: ModifierLineThick, Value: 0}},
+		{"LINETHICK2", Modifier{Kind: ModifierLineThick, Value: 2}},
+		{"NODRAW", Modifier{Kind: ModifierVisibility, Name: "NODRAW"}},
+		{"MAIN", Modifier{Kind: ModifierPane, Name: "MAIN"}},
+		{"SUB3", Modifier{Kind: ModifierPane, Name: "SUB", Value: 3}},
+		{"PRECIS2", Modifier{Kind: ModifierPrecision, Value: 2}},
+		{"ALIGN0", Modifier{Kind: ModifierAlign, Name: "ALIGN", Value: 0}},
+		{"VALIGN2", Modifier{Kind: ModifierAlign, Name: "VALIGN", Value: 2}},
+	}
+	for _, tt := range tests {
+		tt.want.Raw = tt.raw
+		got, err := ParseModifier(tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseModifier(%s) = %+v, %v, want %+v", tt.raw, got, err, tt.want)
+		}
+	}
+
+	for _, raw := range []string{"COLORPINK", "COLOR12345", "LINETHICK10", "SUB0", "PRECIS12", "ALIGN3", "'#12'", "FOO", "2"} {
+		if m, err := ParseModifier(raw); err == nil {
+			t.Errorf("ParseModifier(%s) = %+v, want error", raw, m)
+		}
+	}
+}
+
+func TestCompileModifiers(t *testing.T) {
+	SetLogger(log.New(io.Discard, "", 0))
+	mi := NewMylangInterpreter()
+	mi.SetVar("C", []float64{1, 2, 3})
+	program := mi.CompileCode("A:C,COLOR00FF00,LINETHICK 2,PRECIS2;\nB:C*2,FOO,'#FF8000',SUB1;\nC>1,ALIGN5;")
+	if len(program.Errors) > 0 {
+		t.Fatalf("errors = %v", program.Errors)
+	}
+
+	want := []Diagnostic{
+		{Line: 2, Message: "未知的修饰符 FOO"},
+		{Line: 3, Message: "对齐方式 ALIGN5 超出范围 0~2"},
+	}
+	if len(program.Diagnostics) != len(want) || !reflect.DeepEqual(mi.Diagnostics, program.Diagnostics) {
+		t.Fatalf("diagnostics = %v, want %v", program.Diagnostics, want)
+	}
+	for i, d := range program.Diagnostics {
+		if d.Line != want[i].Line || d.Message != want[i].Message {
+			t.Errorf("diagnostic %d = %v, want %v", i, d, want[i])
+		}
+	}
+
+	mi.ExecuteProgram(program)
+	mods, ok := mi.GetSuffixModifiers("A")
+	wantA := []Modifier{
+		{Kind: ModifierColor, Raw: "COLOR00FF00", Color: "#00FF00"},
+		{Kind: ModifierLineThick, Raw: "LINETHICK2", Value: 2},
+		{Kind: ModifierPrecision, Raw: "PRECIS2", Value: 2},
+	}
+	if !ok || !reflect.DeepEqual(mods, wantA) {
+		t.Errorf("GetSuffixModifiers(A) = %+v, want %+v", mods, wantA)
+	}
+	// 原始写法仍然保留，无效的修饰符也在其中
+	if params, _ := mi.GetSuffixParams("B"); !reflect.DeepEqual(params, []string{"FOO", "'#FF8000'", "SUB1"}) {
+		t.Errorf("GetSuffixParams(B) = %v", params)
+	}
+	if mods, _ := mi.GetSuffixModifiers("B"); len(mods) != 2 || mods[0].Color != "#FF8000" || mods[1].Value != 1 {
+		t.Errorf("GetSuffixModifiers(B) = %+v", mods)
+	}
+}
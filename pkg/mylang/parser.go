@@ -2,6 +2,7 @@ package mylang
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -25,8 +26,9 @@ type Expression interface {
 
 // Program 代表整个程序
 type Program struct {
-	Statements []Statement
-	Errors     []string // 存储语法错误
+	Statements  []Statement
+	Errors      []string     // 存储语法错误
+	Diagnostics []Diagnostic // 不影响执行的警告，如未知的修饰符
 }
 
 func (p *Program) String() string {
@@ -101,6 +103,53 @@ func (ue *UnaryExpression) String() string {
 	return "(" + ue.Operator + " " + rightStr + ")"
 }
 
+// ConditionalExpression 代表条件表达式 Condition ? Consequence : Alternative，数组按元素选择
+type ConditionalExpression struct {
+	Token       Token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode() {}
+func (ce *ConditionalExpression) String() string {
+	parts := []string{"<nil>", "<nil>", "<nil>"}
+	for idx, e := range []Expression{ce.Condition, ce.Consequence, ce.Alternative} {
+		if e != nil {
+			parts[idx] = e.String()
+		}
+	}
+	return "(" + parts[0] + " ? " + parts[1] + " : " + parts[2] + ")"
+}
+
+// IndexExpression 代表索引 Left[Index] 或切片 Left[Start:End]，切片的 Start、End 可以省略
+type IndexExpression struct {
+	Token   Token
+	Left    Expression
+	Index   Expression
+	IsSlice bool
+	Start   Expression
+	End     Expression
+}
+
+func (ie *IndexExpression) expressionNode() {}
+func (ie *IndexExpression) String() string {
+	str := func(e Expression) string {
+		if e == nil {
+			return ""
+		}
+		return e.String()
+	}
+	left := "<nil>"
+	if ie.Left != nil {
+		left = ie.Left.String()
+	}
+	if ie.IsSlice {
+		return left + "[" + str(ie.Start) + ":" + str(ie.End) + "]"
+	}
+	return left + "[" + str(ie.Index) + "]"
+}
+
 // FunctionCall 代表一个函数调用
 type FunctionCall struct {
 	Token     Token
@@ -132,6 +181,7 @@ type AssignmentStatement struct {
 	Value        Expression
 	IsOutputVar bool // true表示是画图变量赋值(:), false表示普通赋值(:=)
 	SuffixParams []string // 存储修饰符，如 COLORRED, NODRAW
+	Modifiers    []Modifier // 解析后的修饰符，无效的修饰符不在其中
 }
 
 func (as *AssignmentStatement) statementNode() {}
@@ -154,23 +204,30 @@ func (as *AssignmentStatement) String() string {
 
 // ExpressionStatement 代表一个表达式语句
 type ExpressionStatement struct {
-	Token      Token
-	Expression Expression
+	Token        Token
+	Expression   Expression
+	SuffixParams []string // 画图函数的修饰符，如 DRAWTEXT(C>O,L,'买'),COLORRED
+	Modifiers    []Modifier // 解析后的修饰符
 }
 
 func (es *ExpressionStatement) statementNode() {}
 func (es *ExpressionStatement) String() string {
-	if es.Expression != nil {
-		return es.Expression.String()
+	if es.Expression == nil {
+		return ""
 	}
-	return ""
+	if len(es.SuffixParams) > 0 {
+		return es.Expression.String() + "," + strings.Join(es.SuffixParams, ",")
+	}
+	return es.Expression.String()
 }
 
 // Parser 代表语法分析器
 type Parser struct {
-	l       *Lexer
-	curTok  Token
-	peekTok Token
+	l           *Lexer
+	curTok      Token
+	peekTok     Token
+	diagnostics []Diagnostic
+	errors      []string // 解析表达式时的语法错误
 }
 
 // NewParser 创建一个新的语法分析器
@@ -201,13 +258,22 @@ func (p *Parser) ParseProgram() *Program {
 		if p.curTok.Type != TokenSemicolon && p.curTok.Type != TokenEOF {
 			errorMsg := fmt.Sprintf("第%d行第%d列：语法错误，语句必须以分号结尾，当前token: %s", p.curTok.Line, p.curTok.Column, p.curTok.Literal)
 			Logger.Printf("第%d行第%d列：语法错误，语句必须以分号结尾，当前token: %s", p.curTok.Line, p.curTok.Column, p.curTok.Literal)
-			program.Errors = append(program.Errors, errorMsg)
+			// 词法错误、表达式的语法错误引起的分号错误只报告前者
+			if len(p.errors) == 0 && (len(p.l.Errors) == 0 || positionAfter(p.l.Errors[0], p.curTok)) {
+				program.Errors = append(program.Errors, errorMsg)
+			}
+			program.Errors = append(program.Errors, p.errors...)
+			program.Errors = append(program.Errors, p.lexErrors()...)
+			program.Diagnostics = p.allDiagnostics()
 			// 返回错误程序，停止解析
 			return program
 		}
 		
 		p.nextToken()
 	}
+	program.Errors = append(program.Errors, p.errors...)
+	program.Errors = append(program.Errors, p.lexErrors()...)
+	program.Diagnostics = p.allDiagnostics()
 	Logger.Println("Parsed program with", len(program.Statements), "statements")
 	for i, stmt := range program.Statements {
 		if stmt != nil {
@@ -242,6 +308,32 @@ func (p *Parser) ParseProgram() *Program {
 	return program
 }
 
+// lexErrors 词法错误，格式与语法错误相同
+func (p *Parser) lexErrors() []string {
+	var errs []string
+	for _, e := range p.l.Errors {
+		errs = append(errs, e.String())
+	}
+	return errs
+}
+
+// positionAfter d 是否位于 tok 之后
+func positionAfter(d Diagnostic, tok Token) bool {
+	return d.Line > tok.Line || (d.Line == tok.Line && d.Column > tok.Column)
+}
+
+// allDiagnostics 词法和语法分析的警告，按位置排序
+func (p *Parser) allDiagnostics() []Diagnostic {
+	all := append(append([]Diagnostic(nil), p.l.Diagnostics...), p.diagnostics...)
+	sort.SliceStable(all, func(a, b int) bool {
+		if all[a].Line != all[b].Line {
+			return all[a].Line < all[b].Line
+		}
+		return all[a].Column < all[b].Column
+	})
+	return all
+}
+
 func (p *Parser) parseStatement() Statement {
 	Logger.Println("Parsing statement, current token:", p.curTok.Literal)
 	switch p.curTok.Type {
@@ -253,21 +345,17 @@ func (p *Parser) parseStatement() Statement {
 		// 如果不是赋值语句，尝试解析为表达式语句
 		expr := p.parseExpression(LOWEST)
 		if expr != nil {
-			// 消费分号（如果存在）
-			if p.peekTok.Type == TokenSemicolon {
-				p.nextToken()
-			}
-			return &ExpressionStatement{Expression: expr}
+			stmt := &ExpressionStatement{Expression: expr}
+			stmt.SuffixParams, stmt.Modifiers = p.parseSuffixParams()
+			return stmt
 		}
 	default:
 		// 尝试解析为表达式语句
 		expr := p.parseExpression(LOWEST)
 		if expr != nil {
-			// 消费分号（如果存在）
-			if p.peekTok.Type == TokenSemicolon {
-				p.nextToken()
-			}
-			return &ExpressionStatement{Expression: expr}
+			stmt := &ExpressionStatement{Expression: expr}
+			stmt.SuffixParams, stmt.Modifiers = p.parseSuffixParams()
+			return stmt
 		}
 	}
 	return nil
@@ -300,13 +388,36 @@ func (p *Parser) parseAssignmentStatement() *AssignmentStatement {
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
 
-	// 解析修饰符（逗号分隔的标识符）
+	params, modifiers := p.parseSuffixParams()
+	stmt.SuffixParams = append(stmt.SuffixParams, params...)
+	stmt.Modifiers = modifiers
+
+	return stmt
+}
+
+// parseSuffixParams 解析语句末尾逗号分隔的修饰符，并消费分号
+// 修饰符可以是标识符、字符串（如 '#FF0000'），或者标识符后紧跟数字、字符串（如 LINETHICK 2）
+// 无效的修饰符记录为 Diagnostic，原样保留在返回的字符串中
+func (p *Parser) parseSuffixParams() ([]string, []Modifier) {
+	var params []string
+	var modifiers []Modifier
 	if p.peekTok.Type == TokenComma {
 		p.nextToken() // 跳过第一个逗号
-		for p.peekTok.Type == TokenIdentifier {
+		for p.peekTok.Type == TokenIdentifier || p.peekTok.Type == TokenString || p.peekTok.Type == TokenNumber {
 			p.nextToken()
-			stmt.SuffixParams = append(stmt.SuffixParams, p.curTok.Literal)
-			Logger.Println("Added suffix param:", p.curTok.Literal)
+			tok := p.curTok
+			param := suffixParamLiteral(tok)
+			if tok.Type == TokenIdentifier && (p.peekTok.Type == TokenNumber || p.peekTok.Type == TokenString) {
+				p.nextToken()
+				param += suffixParamLiteral(p.curTok)
+			}
+			params = append(params, param)
+			Logger.Println("Added suffix param:", param)
+			if m, err := ParseModifier(param); err != nil {
+				p.diagnostics = append(p.diagnostics, Diagnostic{Line: tok.Line, Column: tok.Column, Message: err.Error()})
+			} else {
+				modifiers = append(modifiers, m)
+			}
 
 			// 如果下一个是逗号，继续解析
 			if p.peekTok.Type == TokenComma {
@@ -315,18 +426,19 @@ func (p *Parser) parseAssignmentStatement() *AssignmentStatement {
 				break
 			}
 		}
-		// 有修饰符时，消费分号
-		if p.peekTok.Type == TokenSemicolon {
-			p.nextToken()
-		}
-	} else {
-		// 没有修饰符时，也消费分号
-		if p.peekTok.Type == TokenSemicolon {
-			p.nextToken()
-		}
 	}
+	if p.peekTok.Type == TokenSemicolon {
+		p.nextToken()
+	}
+	return params, modifiers
+}
 
-	return stmt
+// suffixParamLiteral 修饰符在源码中的写法，字符串保留单引号
+func suffixParamLiteral(tok Token) string {
+	if tok.Type == TokenString {
+		return "'" + tok.Literal + "'"
+	}
+	return tok.Literal
 }
 
 func (p *Parser) parseExpression(precedence int) Expression {
@@ -373,7 +485,7 @@ func (p *Parser) parsePrefix(tokenType TokenType) func() Expression {
 		return p.parseStringLiteral
 	case TokenLParen:
 		return p.parseGroupedExpression
-	case TokenMinus, TokenNot: // 支持负号作为一元运算符
+	case TokenMinus, TokenPlus, TokenNot: // 支持正负号作为一元运算符
 		return p.parseUnaryExpression
 	}
 	return nil
@@ -381,26 +493,86 @@ func (p *Parser) parsePrefix(tokenType TokenType) func() Expression {
 
 func (p *Parser) parseInfix(left Expression, tokenType TokenType) func() Expression {
 	switch tokenType {
-	case TokenPlus, TokenMinus, TokenMultiply, TokenDivide, TokenAnd, TokenOr, TokenGreaterThan, TokenLessThan, TokenGreaterEqual, TokenLessEqual, TokenEqual, TokenNotEqual:
+	case TokenPlus, TokenMinus, TokenMultiply, TokenDivide, TokenMod, TokenPower, TokenAnd, TokenOr, TokenGreaterThan, TokenLessThan, TokenGreaterEqual, TokenLessEqual, TokenEqual, TokenNotEqual:
 		return func() Expression { return p.parseBinaryExpression(left) }
+	case TokenQuestion:
+		return func() Expression { return p.parseConditionalExpression(left) }
+	case TokenLBracket:
+		return func() Expression { return p.parseIndexExpression(left) }
+	case TokenIdentifier:
+		if p.isModOperator(p.peekTok) {
+			return func() Expression { return p.parseBinaryExpression(left) }
+		}
 	}
 	return nil
 }
 
+// isModOperator 中缀位置的 MOD 是取余运算符，A MOD B 同 A % B；MOD(A,B) 仍然是函数
+func (p *Parser) isModOperator(tok Token) bool {
+	return tok.Type == TokenIdentifier && p.l.isKeyword(tok.Literal, "MOD")
+}
+
 func (p *Parser) parseBinaryExpression(left Expression) Expression {
 	expression := &BinaryExpression{
 		Token:    p.curTok,
 		Operator: p.curTok.Literal,
 		Left:     left,
 	}
+	if p.isModOperator(p.curTok) {
+		expression.Operator = "MOD"
+	}
 
 	precedence := p.curPrecedence()
+	if p.curTok.Type == TokenPower {
+		precedence-- // 乘方是右结合的：2^3^2 = 2^(3^2)
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+// parseIndexExpression 解析 C[1]、C[-1]、C[10:20]、C[:5]、C[10:]
+func (p *Parser) parseIndexExpression(left Expression) Expression {
+	expression := &IndexExpression{Token: p.curTok, Left: left}
+	p.nextToken()
+	if p.curTok.Type != TokenColon {
+		expression.Index = p.parseExpression(LOWEST)
+		if p.peekTok.Type != TokenColon {
+			if !p.expectPeek(TokenRBracket) {
+				p.errors = append(p.errors, fmt.Sprintf("第%d行第%d列：语法错误，索引缺少 ]", expression.Token.Line, expression.Token.Column))
+			}
+			return expression
+		}
+		p.nextToken()
+	}
+	// 当前为 :
+	expression.IsSlice = true
+	expression.Start, expression.Index = expression.Index, nil
+	if p.peekTok.Type != TokenRBracket {
+		p.nextToken()
+		expression.End = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(TokenRBracket) {
+		p.errors = append(p.errors, fmt.Sprintf("第%d行第%d列：语法错误，切片缺少 ]", expression.Token.Line, expression.Token.Column))
+	}
+	return expression
+}
+
+// parseConditionalExpression 解析 cond ? a : b，右结合：a ? b : c ? d : e = a ? b : (c ? d : e)
+func (p *Parser) parseConditionalExpression(condition Expression) Expression {
+	expression := &ConditionalExpression{Token: p.curTok, Condition: condition}
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+	if !p.expectPeek(TokenColon) {
+		p.errors = append(p.errors, fmt.Sprintf("第%d行第%d列：语法错误，条件表达式缺少 :", expression.Token.Line, expression.Token.Column))
+		return expression
+	}
+	p.nextToken()
+	expression.Alternative = p.parseExpression(TERNARY - 1)
+	return expression
+}
+
 func (p *Parser) parseUnaryExpression() Expression {
 	expression := &UnaryExpression{
 		Token:    p.curTok,
@@ -507,35 +679,29 @@ func (p *Parser) expectPeek(t TokenType) bool {
 const (
 	_ int = iota
 	LOWEST
+	TERNARY // ?:
 	OR
 	AND
 	COMPARISON
 	SUM
-	PRODUCT
+	PRODUCT // * / % MOD
 	PREFIX
+	POWER // ^ **，比一元运算符优先：-2^2 = -(2^2)
 	CALL
 )
 
 func (p *Parser) peekPrecedence() int {
-	switch p.peekTok.Type {
-	case TokenOr:
-		return OR
-	case TokenAnd:
-		return AND
-	case TokenGreaterThan, TokenLessThan, TokenGreaterEqual, TokenLessEqual, TokenEqual, TokenNotEqual:
-		return COMPARISON
-	case TokenPlus, TokenMinus:
-		return SUM
-	case TokenMultiply, TokenDivide:
-		return PRODUCT
-	case TokenLParen:
-		return CALL
-	}
-	return LOWEST
+	return p.precedence(p.peekTok)
 }
 
 func (p *Parser) curPrecedence() int {
-	switch p.curTok.Type {
+	return p.precedence(p.curTok)
+}
+
+func (p *Parser) precedence(tok Token) int {
+	switch tok.Type {
+	case TokenQuestion:
+		return TERNARY
 	case TokenOr:
 		return OR
 	case TokenAnd:
@@ -544,8 +710,16 @@ func (p *Parser) curPrecedence() int {
 		return COMPARISON
 	case TokenPlus, TokenMinus:
 		return SUM
-	case TokenMultiply, TokenDivide:
+	case TokenMultiply, TokenDivide, TokenMod:
 		return PRODUCT
+	case TokenPower:
+		return POWER
+	case TokenIdentifier:
+		if p.isModOperator(tok) {
+			return PRODUCT
+		}
+	case TokenLParen, TokenLBracket:
+		return CALL
 	}
 	return LOWEST
 }
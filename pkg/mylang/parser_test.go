@@ -242,3 +242,33 @@ func TestAssignmentStatementString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseExpressionStatementWithSuffixParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		expected []string
+	}{
+		{"画图函数带修饰符", "DRAWTEXT(C>O,L,'买'),COLORRED;", []string{"COLORRED"}},
+		{"多个修饰符", "STICKLINE(C>O,O,C,2,0),COLORRED,SUB1;", []string{"COLORRED", "SUB1"}},
+		{"无修饰符", "DRAWICON(C>O,L,1);", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := NewParser(NewLexer(tt.code)).ParseProgram()
+			if len(program.Errors) > 0 {
+				t.Fatalf("errors: %v", program.Errors)
+			}
+			if len(program.Statements) != 1 {
+				t.Fatalf("statements = %d, want 1", len(program.Statements))
+			}
+			stmt, ok := program.Statements[0].(*ExpressionStatement)
+			if !ok {
+				t.Fatalf("statement is %T, want *ExpressionStatement", program.Statements[0])
+			}
+			if !reflect.DeepEqual(stmt.SuffixParams, tt.expected) {
+				t.Errorf("SuffixParams = %v, want %v", stmt.SuffixParams, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package mylang
+
+import (
+	"fmt"
+	"math"
+)
+
+// ToleranceMode 浮点数相等比较的误差模式
+type ToleranceMode int
+
+const (
+	// ToleranceLegacy 默认模式：整数部分必须相等，小数部分允许 1% 的相对误差，见 floatEqual
+	ToleranceLegacy ToleranceMode = iota
+	// ToleranceAbsolute |a-b| <= Value
+	ToleranceAbsolute
+	// ToleranceRelative |a-b| <= Value * max(|a|,|b|)
+	ToleranceRelative
+	// ToleranceULPs 两个数之间相隔的可表示浮点数个数 <= Value
+	ToleranceULPs
+)
+
+func (m ToleranceMode) String() string {
+	switch m {
+	case ToleranceLegacy:
+		return "legacy"
+	case ToleranceAbsolute:
+		return "absolute"
+	case ToleranceRelative:
+		return "relative"
+	case ToleranceULPs:
+		return "ulps"
+	default:
+		return fmt.Sprintf("ToleranceMode(%d)", int(m))
+	}
+}
+
+// Tolerance 比较运算使用的误差设置，零值为 ToleranceLegacy
+//
+// 非 Legacy 模式下 >、<、>=、<= 也会考虑误差：误差范围内的两个数视为相等，
+// 因此 a > b 要求 a 比 b 大出误差范围，a >= b 在误差范围内也成立。
+type Tolerance struct {
+	Mode  ToleranceMode
+	Value float64
+}
+
+// Equal 按误差设置判断 a、b 是否相等，两个 NaN 视为相等
+func (t Tolerance) Equal(a, b float64) bool {
+	if t.Mode == ToleranceLegacy {
+		return floatEqual(a, b)
+	}
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if a == b {
+		return true
+	}
+	switch t.Mode {
+	case ToleranceAbsolute:
+		return math.Abs(a-b) <= t.Value
+	case ToleranceRelative:
+		return math.Abs(a-b) <= t.Value*math.Max(math.Abs(a), math.Abs(b))
+	case ToleranceULPs:
+		return ulpDistance(a, b) <= uint64(t.Value)
+	default:
+		return false
+	}
+}
+
+// Compare 按误差设置比较 a、b，任一边为 NaN 时结果为 false
+func (t Tolerance) Compare(a, b float64, operator string) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return false
+	}
+	if t.Mode == ToleranceLegacy {
+		switch operator {
+		case ">":
+			return a > b
+		case "<":
+			return a < b
+		case ">=":
+			return a >= b
+		case "<=":
+			return a <= b
+		}
+	}
+	switch operator {
+	case ">":
+		return a > b && !t.Equal(a, b)
+	case "<":
+		return a < b && !t.Equal(a, b)
+	case ">=":
+		return a >= b || t.Equal(a, b)
+	case "<=":
+		return a <= b || t.Equal(a, b)
+	case "==", "=":
+		return t.Equal(a, b)
+	case "!=":
+		return !t.Equal(a, b)
+	default:
+		return false
+	}
+}
+
+// ulpDistance 返回两个有限浮点数之间相隔的可表示浮点数个数
+func ulpDistance(a, b float64) uint64 {
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return math.MaxUint64
+	}
+	x, y := orderedBits(a), orderedBits(b)
+	if x > y {
+		return uint64(x) - uint64(y)
+	}
+	return uint64(y) - uint64(x)
+}
+
+// orderedBits 把浮点数的位模式映射为有序整数，相邻的浮点数相差 1，+0 和 -0 相同
+func orderedBits(f float64) int64 {
+	bits := int64(math.Float64bits(f))
+	if bits < 0 {
+		return math.MinInt64 - bits
+	}
+	return bits
+}
@@ -0,0 +1,78 @@
+package mylang
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestToleranceEqual(t *testing.T) {
+	nan := math.NaN()
+	tenth := 0.1
+	sum := tenth + 0.2 // 0.30000000000000004
+	tests := []struct {
+		name string
+		tol  Tolerance
+		a, b float64
+		want bool
+	}{
+		{"legacy 1%", Tolerance{}, 1.0, 1.01, true},
+		{"legacy 整数部分", Tolerance{}, 1.0, 1.5, false},
+		{"absolute 相等", Tolerance{Mode: ToleranceAbsolute, Value: 1e-9}, sum, 0.3, true},
+		{"absolute 不等", Tolerance{Mode: ToleranceAbsolute, Value: 1e-9}, 10.05, 10.06, false},
+		{"absolute 零误差", Tolerance{Mode: ToleranceAbsolute}, sum, 0.3, false},
+		{"relative 相等", Tolerance{Mode: ToleranceRelative, Value: 1e-3}, 1000, 1000.5, true},
+		{"relative 不等", Tolerance{Mode: ToleranceRelative, Value: 1e-3}, 1, 1.01, false},
+		{"ulps 相邻", Tolerance{Mode: ToleranceULPs, Value: 1}, sum, 0.3, true},
+		{"ulps 正负零", Tolerance{Mode: ToleranceULPs}, 0, math.Copysign(0, -1), true},
+		{"ulps 跨零", Tolerance{Mode: ToleranceULPs, Value: 2}, math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64, true},
+		{"ulps 不等", Tolerance{Mode: ToleranceULPs, Value: 4}, 1, 1.0000001, false},
+		{"ulps 无穷", Tolerance{Mode: ToleranceULPs, Value: 4}, math.Inf(1), math.MaxFloat64, false},
+		{"NaN 相等", Tolerance{Mode: ToleranceAbsolute, Value: 1}, nan, nan, true},
+		{"NaN 不等", Tolerance{Mode: ToleranceAbsolute, Value: 1}, nan, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tol.Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("%v.Equal(%v, %v) = %v, want %v", tt.tol.Mode, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToleranceComparison(t *testing.T) {
+	tenth := 0.1
+	sum := tenth + 0.2
+	interp := NewMylangInterpreter()
+	interp.RegisterVariable("C", []float64{sum, 0.29, 0.31})
+	interp.RegisterVariable("X", sum)
+
+	// 默认兼容旧版：>=、<= 不考虑误差
+	interp.Execute("A:C>=0.3;B:X<=0.3;E:C=0.3;")
+	for name, want := range map[string]interface{}{"A": []float64{1, 0, 1}, "B": false, "E": []float64{1, 0, 0}} {
+		if got, _ := interp.GetVariable(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("legacy %s = %v, want %v", name, got, want)
+		}
+	}
+
+	interp.SetTolerance(Tolerance{Mode: ToleranceAbsolute, Value: 1e-9})
+	interp.Reset()
+	if interp.Tolerance().Mode != ToleranceAbsolute {
+		t.Errorf("Reset should keep the tolerance")
+	}
+	interp.RegisterVariable("C", []float64{sum, 0.29, 0.31})
+	interp.RegisterVariable("X", sum)
+	interp.Execute("A:C>=0.3;B:X<=0.3;D:X>0.3;E:C=0.3;F:0.3<C;G:0.3>=C;")
+	for name, want := range map[string]interface{}{
+		"A": []float64{1, 0, 1},
+		"B": true,
+		"D": false,
+		"E": []float64{1, 0, 0},
+		"F": []float64{0, 0, 1},
+		"G": []float64{1, 1, 0},
+	} {
+		if got, _ := interp.GetVariable(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("absolute %s = %v, want %v", name, got, want)
+		}
+	}
+}